@@ -0,0 +1,77 @@
+// cmd/server/app.go
+package main
+
+import (
+	"context"
+	"sync"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/router"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/cache"
+	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/mailer"
+	"gin-wire-demo/pkg/queue"
+	redisx "gin-wire-demo/pkg/redis"
+)
+
+// Worker 随服务启动的后台任务，Run 在 ctx 取消后返回
+type Worker interface {
+	Run(ctx context.Context)
+}
+
+// App HTTP 路由及后台任务
+type App struct {
+	*router.Router
+	Workers []Worker
+}
+
+func NewApp(
+	router *router.Router,
+	outboxRelay *service.OutboxRelay,
+	webhookDispatcher *service.WebhookDispatcher,
+	queueWorker *queue.Worker,
+	cronScheduler *service.CronScheduler,
+	userImports *service.UserImportServiceImpl,
+	cacheInvalidator *cache.Invalidator,
+	redisHealth *redisx.HealthWatcher,
+) *App {
+	return &App{
+		Router:  router,
+		Workers: []Worker{outboxRelay, webhookDispatcher, queueWorker, cronScheduler, userImports, cacheInvalidator, redisHealth},
+	}
+}
+
+// NewQueueWorker 创建任务队列的 worker 并注册各任务类型的处理函数
+func NewQueueWorker(client *queue.Client, cfg *config.Config, logger logger.Logger, sendMail *mailer.SendHandler) *queue.Worker {
+	worker := queue.NewWorker(client, cfg, logger)
+	worker.Handle(mailer.SendMailJob, sendMail)
+	return worker
+}
+
+// NewCronJobs 汇总全部定时任务，新增任务时在这里加入其 provider 的返回值
+func NewCronJobs(
+	purgeUsers *service.PurgeDeletedUsersJob,
+	expireInvitations *service.ExpireInvitationsJob,
+	reencryptPII *service.ReencryptPIIJob,
+	pruneRuns *service.PruneCronRunsJob,
+) []service.CronJob {
+	return []service.CronJob{purgeUsers, expireInvitations, reencryptPII, pruneRuns}
+}
+
+// StartWorkers 启动全部后台任务，返回的函数取消并等待它们退出
+func (a *App) StartWorkers(ctx context.Context) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, w := range a.Workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+			w.Run(ctx)
+		}(w)
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
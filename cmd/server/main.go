@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,8 +25,24 @@ func main() {
 	flag.StringVar(&configPath, "config", defaultConfigPath, "path to config file")
 	flag.Parse()
 
+	// 子命令：server migrate <up|down|status|create>，server pii reencrypt
+	switch flag.Arg(0) {
+	case "migrate":
+		if err := runMigrate(configPath, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "pii":
+		if err := runPII(configPath, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ PII command failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// 初始化应用
-	app, cleanup, err := InitializeApp("./configs/config.yaml")
+	app, cleanup, err := InitializeApp(configPath)
 	if err != nil {
 		// 使用标准日志，因为此时app.Logger可能未初始化
 		fmt.Fprintf(os.Stderr, "❌ Failed to initialize app: %v\n", err)
@@ -42,9 +59,19 @@ func main() {
 	}
 	addr := ":" + port
 
+	// 所有请求上下文的根，强制关闭时取消以中断进行中的数据库和 Redis 操作
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	// 后台任务（outbox 中继、webhook 投递、任务队列 worker、用户导入）
+	stopWorkers := app.StartWorkers(baseCtx)
+
 	server := &http.Server{
 		Addr:    addr,
 		Handler: app.Engine,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// 使用缓冲通道防止竞态
@@ -76,8 +103,10 @@ func main() {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
+		cancelBase()
 		app.Logger.Error(fmt.Sprintf("⚠️ Forced shutdown: %v (incomplete requests terminated)", err))
 	} else {
 		app.Logger.Info("✅ Server stopped gracefully")
 	}
+	stopWorkers()
 }
@@ -0,0 +1,148 @@
+// cmd/server/migrate.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/migrations"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/migrate"
+)
+
+const (
+	defaultMigrationsDir = "./migrations"
+	migrateUsage         = `usage: server [-config path] migrate <command>
+
+commands:
+  up [N]           apply all pending migrations, or the next N
+  down [N]         revert the last N applied migrations (default 1, "all" for every one)
+  status           list migrations and whether they are applied
+  create <name>    create empty up/down files for a new migration in every driver directory
+`
+)
+
+// runMigrate 处理 migrate 子命令
+func runMigrate(configPath string, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := fs.String("dir", defaultMigrationsDir, "migrations root directory used by create, one subdirectory per driver")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing migrate command")
+	}
+	command, rest := fs.Arg(0), fs.Args()[1:]
+
+	// create 只生成文件，不需要配置和数据库。各驱动的版本号需一致，因此每个驱动目录都生成
+	if command == "create" {
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: migrate create <name>")
+		}
+		dirs := make([]string, len(migrations.Drivers))
+		for i, driver := range migrations.Drivers {
+			dirs[i] = filepath.Join(*dir, driver)
+		}
+		paths, err := migrate.Create(dirs, rest[0])
+		for _, path := range paths {
+			fmt.Printf("created %s\n", path)
+		}
+		return err
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	driver := cfg.Database.Driver
+
+	source, err := migrations.For(driver)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.NewScriptDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migrate.New(sqlDB, driver, source)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		steps, err := parseSteps(rest, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx, steps)
+		for _, m := range applied {
+			fmt.Printf("applied  %06d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps, err := parseSteps(rest, 1)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Dirty {
+				state = "DIRTY"
+			}
+			fmt.Printf("%06d_%-40s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+// parseSteps 解析可选的步数参数，"all" 表示不限
+func parseSteps(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	if args[0] == "all" {
+		return 0, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("invalid step count %q", args[0])
+	}
+	return steps, nil
+}
@@ -0,0 +1,65 @@
+// cmd/server/pii.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/fieldcrypt"
+	"gin-wire-demo/pkg/logger"
+)
+
+const piiUsage = `usage: server [-config path] pii <command>
+
+commands:
+  reencrypt [-batch N]   encrypt legacy plaintext and rotate ciphertext to the active key version
+`
+
+// runPII 处理 pii 子命令
+func runPII(configPath string, args []string) error {
+	if len(args) == 0 || args[0] != "reencrypt" {
+		fmt.Fprint(os.Stderr, piiUsage)
+		return fmt.Errorf("unknown pii command")
+	}
+	fs := flag.NewFlagSet("pii reencrypt", flag.ContinueOnError)
+	batchSize := fs.Int("batch", 500, "rows per batch")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	zapLogger, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		return err
+	}
+	keyring, err := fieldcrypt.NewKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	gormDB, cleanup, err := db.NewDB(cfg, zapLogger, keyring)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 每行单独更新，中断后重新执行会跳过已完成的行
+	updated, err := repository.NewUserRepository(gormDB, keyring).ReencryptEmails(ctx, *batchSize)
+	fmt.Printf("re-encrypted %d user emails with key version %d\n", updated, keyring.ActiveVersion())
+	return err
+}
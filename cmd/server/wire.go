@@ -11,20 +11,41 @@ import (
 	"gin-wire-demo/internal/repository"
 	"gin-wire-demo/internal/router"
 	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/broker"
+	"gin-wire-demo/pkg/cache"
 	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/fieldcrypt"
 	"gin-wire-demo/pkg/jwtauth"
 	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/mailer"
+	"gin-wire-demo/pkg/queue"
 	"gin-wire-demo/pkg/redis"
 
 	"github.com/google/wire"
 )
 
 var dbSet = wire.NewSet(
-	db.NewMySQL,
+	db.NewDB,
+	fieldcrypt.NewKeyring,
 )
 
 var redisSet = wire.NewSet(
 	redis.NewRedisClient,
+	redis.NewLocker,
+	redis.NewHealthWatcher,
+)
+
+var cacheSet = wire.NewSet(
+	cache.NewStore,
+	cache.NewInvalidator,
+)
+
+var brokerSet = wire.NewSet(
+	broker.NewBroker,
+)
+
+var queueSet = wire.NewSet(
+	queue.NewClient,
 )
 
 var configSet = wire.NewSet(
@@ -34,33 +55,96 @@ var configSet = wire.NewSet(
 var repositorySet = wire.NewSet(
 	repository.NewUserRepository,
 	wire.Bind(new(repository.UserRepository), new(*repository.UserRepositoryImpl)),
+	repository.NewOrganizationRepository,
+	wire.Bind(new(repository.OrganizationRepository), new(*repository.OrganizationRepositoryImpl)),
+	repository.NewInvitationRepository,
+	wire.Bind(new(repository.InvitationRepository), new(*repository.InvitationRepositoryImpl)),
+	repository.NewAuditRepository,
+	wire.Bind(new(repository.AuditRepository), new(*repository.AuditRepositoryImpl)),
+	repository.NewImportJobRepository,
+	wire.Bind(new(repository.ImportJobRepository), new(*repository.ImportJobRepositoryImpl)),
+	repository.NewWebhookRepository,
+	wire.Bind(new(repository.WebhookRepository), new(*repository.WebhookRepositoryImpl)),
+	repository.NewOutboxRepository,
+	wire.Bind(new(repository.OutboxRepository), new(*repository.OutboxRepositoryImpl)),
+	repository.NewCronRepository,
+	wire.Bind(new(repository.CronRepository), new(*repository.CronRepositoryImpl)),
+	repository.NewTxManager,
+	wire.Bind(new(repository.TxManager), new(*repository.GormTxManager)),
 )
 
 var serviceSet = wire.NewSet(
 	service.NewUserService,
 	wire.Bind(new(service.UserService), new(*service.UserServiceImpl)),
+	service.NewOrganizationService,
+	wire.Bind(new(service.OrganizationService), new(*service.OrganizationServiceImpl)),
+	service.NewInvitationService,
+	wire.Bind(new(service.InvitationService), new(*service.InvitationServiceImpl)),
+	service.NewAuditService,
+	wire.Bind(new(service.AuditService), new(*service.AuditServiceImpl)),
+	service.NewUserImportService,
+	wire.Bind(new(service.UserImportService), new(*service.UserImportServiceImpl)),
+	service.NewWebhookService,
+	wire.Bind(new(service.WebhookService), new(*service.WebhookServiceImpl)),
+	service.NewOutboxRelay,
+	service.NewWebhookDispatcher,
+	service.NewCronScheduler,
+	wire.Bind(new(service.CronService), new(*service.CronScheduler)),
+)
+
+var cronJobSet = wire.NewSet(
+	service.NewPurgeDeletedUsersJob,
+	service.NewExpireInvitationsJob,
+	service.NewReencryptPIIJob,
+	service.NewPruneCronRunsJob,
+	NewCronJobs,
 )
 
 var controllerSet = wire.NewSet(
 	controller.NewUserController,
 	controller.NewAuthController, // 添加 AuthController
+	controller.NewProfileController,
+	controller.NewOrganizationController,
+	controller.NewInvitationController,
+	controller.NewUserImportController,
+	controller.NewWebhookController,
+	controller.NewQueueController,
+	controller.NewCronController,
+	controller.NewRedisController,
 
 )
 
 var middlewareSet = wire.NewSet(
 	middleware.NewAuthMiddleware,
 	middleware.NewRateLimiterMiddleware,
+	middleware.NewTenantMiddleware,
+	middleware.NewAdminMiddleware,
+	middleware.NewDeadlineMiddleware,
+	middleware.NewReadYourWritesMiddleware,
+	middleware.NewRequestIDMiddleware,
 )
 
 var routerSet = wire.NewSet(
 	router.NewRouter,
 )
 
+var appSet = wire.NewSet(
+	NewApp,
+	NewQueueWorker,
+)
+
 var loggerSet = wire.NewSet(
 	logger.NewZapLogger,
 	wire.Bind(new(logger.Logger), new(*logger.ZapLogger)),
 )
 
+var mailerSet = wire.NewSet(
+	mailer.NewLogMailer,
+	mailer.NewQueuedMailer,
+	mailer.NewSendHandler,
+	wire.Bind(new(mailer.Mailer), new(*mailer.QueuedMailer)),
+)
+
 var jwtSet = wire.NewSet(
 	middleware.NewJWT,
 	jwtauth.NewJwtBlacklist,
@@ -69,18 +153,24 @@ var jwtSet = wire.NewSet(
 )
 
 // InitializeApp 初始化应用
-func InitializeApp(configPath string) (*router.Router, func(), error) {
+func InitializeApp(configPath string) (*App, func(), error) {
 	wire.Build(
 		configSet,
 		dbSet,
 		redisSet,
+		cacheSet,
+		brokerSet,
+		queueSet,
 		loggerSet, // 添加日志 Set
+		mailerSet,
 		repositorySet,
 		serviceSet,
+		cronJobSet,
 		controllerSet,
 		jwtSet, // 添加 JWT Set
 		middlewareSet,
 		routerSet,
+		appSet,
 	)
 	return nil, nil, nil
 }
@@ -13,9 +13,14 @@ import (
 	"gin-wire-demo/internal/repository"
 	"gin-wire-demo/internal/router"
 	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/broker"
+	"gin-wire-demo/pkg/cache"
 	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/fieldcrypt"
 	"gin-wire-demo/pkg/jwtauth"
 	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/mailer"
+	"gin-wire-demo/pkg/queue"
 	"gin-wire-demo/pkg/redis"
 	"github.com/google/wire"
 )
@@ -23,42 +28,110 @@ import (
 // Injectors from wire.go:
 
 // InitializeApp 初始化应用
-func InitializeApp(configPath string) (*router.Router, func(), error) {
+func InitializeApp(configPath string) (*App, func(), error) {
 	configConfig, err := config.LoadConfig(configPath)
 	if err != nil {
 		return nil, nil, err
 	}
-	gormDB, cleanup, err := db.NewMySQL(configConfig)
+	zapLogger, err := logger.NewZapLogger(configConfig)
 	if err != nil {
 		return nil, nil, err
 	}
-	userRepositoryImpl := repository.NewUserRepository(gormDB)
-	userServiceImpl := service.NewUserService(userRepositoryImpl)
-	zapLogger, err := logger.NewZapLogger(configConfig)
+	keyring, err := fieldcrypt.NewKeyring(configConfig)
 	if err != nil {
-		cleanup()
 		return nil, nil, err
 	}
-	userController := controller.NewUserController(userServiceImpl, zapLogger)
+	gormDB, cleanup, err := db.NewDB(configConfig, zapLogger, keyring)
+	if err != nil {
+		return nil, nil, err
+	}
+	userRepositoryImpl := repository.NewUserRepository(gormDB, keyring)
+	outboxRepositoryImpl := repository.NewOutboxRepository(gormDB)
+	gormTxManager := repository.NewTxManager(gormDB, configConfig, zapLogger)
 	client, cleanup2, err := redis.NewRedisClient(configConfig)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
+	queueClient := queue.NewClient(client, configConfig)
+	queuedMailer := mailer.NewQueuedMailer(queueClient)
+	userServiceImpl := service.NewUserService(userRepositoryImpl, outboxRepositoryImpl, gormTxManager, configConfig, queuedMailer)
+	userController := controller.NewUserController(userServiceImpl, zapLogger)
+	organizationRepositoryImpl := repository.NewOrganizationRepository(gormDB)
+	organizationServiceImpl := service.NewOrganizationService(organizationRepositoryImpl, userRepositoryImpl)
 	authMiddleware := middleware.NewAuthMiddleware(client)
-	jwtBlacklist := jwtauth.NewJwtBlacklist(client, configConfig, zapLogger)
-	loginLocked := jwtauth.NewLoginLocked(client, configConfig)
-	jwtCacheUserinfo := jwtauth.NewJwtCacheUserinfo(client, configConfig, userServiceImpl)
-	jwt, err := middleware.NewJWT(userServiceImpl, zapLogger, configConfig, client, jwtBlacklist, loginLocked, jwtCacheUserinfo)
+	healthWatcher := redis.NewHealthWatcher(client, configConfig, zapLogger)
+	jwtBlacklist := jwtauth.NewJwtBlacklist(client, healthWatcher, configConfig, zapLogger)
+	locker := redis.NewLocker(client, configConfig)
+	loginLocked := jwtauth.NewLoginLocked(client, healthWatcher, configConfig, userServiceImpl)
+	invalidator := cache.NewInvalidator(client, configConfig, zapLogger)
+	store, err := cache.NewStore(configConfig, client, invalidator, healthWatcher)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	jwtCacheUserinfo := jwtauth.NewJwtCacheUserinfo(store, configConfig, userServiceImpl)
+	jwt, err := middleware.NewJWT(userServiceImpl, organizationServiceImpl, zapLogger, configConfig, client, jwtBlacklist, loginLocked, jwtCacheUserinfo)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	profileController := controller.NewProfileController(userServiceImpl, jwt, zapLogger)
+	organizationController := controller.NewOrganizationController(organizationServiceImpl, jwt, zapLogger)
+	invitationRepositoryImpl := repository.NewInvitationRepository(gormDB)
+	auditRepositoryImpl := repository.NewAuditRepository(gormDB)
+	auditServiceImpl := service.NewAuditService(auditRepositoryImpl, zapLogger)
+	invitationServiceImpl := service.NewInvitationService(invitationRepositoryImpl, organizationRepositoryImpl, userRepositoryImpl, userServiceImpl, auditServiceImpl, gormTxManager, queuedMailer, configConfig)
+	invitationController := controller.NewInvitationController(invitationServiceImpl, zapLogger)
+	importJobRepositoryImpl := repository.NewImportJobRepository(gormDB)
+	userImportServiceImpl := service.NewUserImportService(userRepositoryImpl, importJobRepositoryImpl, outboxRepositoryImpl, gormTxManager, zapLogger)
+	userImportController := controller.NewUserImportController(userImportServiceImpl, zapLogger)
+	webhookRepositoryImpl := repository.NewWebhookRepository(gormDB)
+	webhookServiceImpl := service.NewWebhookService(webhookRepositoryImpl, organizationRepositoryImpl, configConfig)
+	webhookController := controller.NewWebhookController(webhookServiceImpl, zapLogger)
+	queueController := controller.NewQueueController(queueClient, zapLogger)
+	purgeDeletedUsersJob := service.NewPurgeDeletedUsersJob(userRepositoryImpl, configConfig, zapLogger)
+	expireInvitationsJob := service.NewExpireInvitationsJob(invitationRepositoryImpl, zapLogger)
+	reencryptPIIJob := service.NewReencryptPIIJob(userRepositoryImpl, zapLogger)
+	cronRepositoryImpl := repository.NewCronRepository(gormDB)
+	pruneCronRunsJob := service.NewPruneCronRunsJob(cronRepositoryImpl, configConfig)
+	v := NewCronJobs(purgeDeletedUsersJob, expireInvitationsJob, reencryptPIIJob, pruneCronRunsJob)
+	cronScheduler, err := service.NewCronScheduler(v, cronRepositoryImpl, client, locker, configConfig, zapLogger)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
+	cronController := controller.NewCronController(cronScheduler, zapLogger)
+	redisController := controller.NewRedisController(healthWatcher)
 	authController := controller.NewAuthController(jwt, zapLogger)
-	rateLimiterMiddleware := middleware.NewRateLimiterMiddleware(client, configConfig, zapLogger)
-	routerRouter := router.NewRouter(userController, authMiddleware, authController, jwt, rateLimiterMiddleware, configConfig, zapLogger)
-	return routerRouter, func() {
+	rateLimiterMiddleware, err := middleware.NewRateLimiterMiddleware(client, healthWatcher, configConfig, zapLogger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	tenantMiddleware := middleware.NewTenantMiddleware(organizationServiceImpl, zapLogger)
+	adminMiddleware := middleware.NewAdminMiddleware()
+	deadlineMiddleware := middleware.NewDeadlineMiddleware(configConfig)
+	readYourWritesMiddleware := middleware.NewReadYourWritesMiddleware(configConfig, client, zapLogger)
+	requestIDMiddleware := middleware.NewRequestIDMiddleware()
+	routerRouter := router.NewRouter(userController, profileController, organizationController, invitationController, userImportController, webhookController, queueController, cronController, redisController, authMiddleware, authController, jwt, rateLimiterMiddleware, tenantMiddleware, adminMiddleware, deadlineMiddleware, readYourWritesMiddleware, requestIDMiddleware, configConfig, zapLogger)
+	brokerBroker, err := broker.NewBroker(configConfig, client)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	outboxRelay := service.NewOutboxRelay(outboxRepositoryImpl, brokerBroker, webhookServiceImpl, configConfig, zapLogger)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepositoryImpl, gormTxManager, configConfig, zapLogger)
+	logMailer := mailer.NewLogMailer(zapLogger)
+	sendHandler := mailer.NewSendHandler(logMailer)
+	worker := NewQueueWorker(queueClient, configConfig, zapLogger, sendHandler)
+	app := NewApp(routerRouter, outboxRelay, webhookDispatcher, worker, cronScheduler, userImportServiceImpl, invalidator, healthWatcher)
+	return app, func() {
 		cleanup2()
 		cleanup()
 	}, nil
@@ -66,22 +139,34 @@ func InitializeApp(configPath string) (*router.Router, func(), error) {
 
 // wire.go:
 
-var dbSet = wire.NewSet(db.NewMySQL)
+var dbSet = wire.NewSet(db.NewDB, fieldcrypt.NewKeyring)
+
+var redisSet = wire.NewSet(redis.NewRedisClient, redis.NewLocker, redis.NewHealthWatcher)
+
+var cacheSet = wire.NewSet(cache.NewStore, cache.NewInvalidator)
 
-var redisSet = wire.NewSet(redis.NewRedisClient)
+var brokerSet = wire.NewSet(broker.NewBroker)
+
+var queueSet = wire.NewSet(queue.NewClient)
 
 var configSet = wire.NewSet(config.LoadConfig)
 
-var repositorySet = wire.NewSet(repository.NewUserRepository, wire.Bind(new(repository.UserRepository), new(*repository.UserRepositoryImpl)))
+var repositorySet = wire.NewSet(repository.NewUserRepository, wire.Bind(new(repository.UserRepository), new(*repository.UserRepositoryImpl)), repository.NewOrganizationRepository, wire.Bind(new(repository.OrganizationRepository), new(*repository.OrganizationRepositoryImpl)), repository.NewInvitationRepository, wire.Bind(new(repository.InvitationRepository), new(*repository.InvitationRepositoryImpl)), repository.NewAuditRepository, wire.Bind(new(repository.AuditRepository), new(*repository.AuditRepositoryImpl)), repository.NewImportJobRepository, wire.Bind(new(repository.ImportJobRepository), new(*repository.ImportJobRepositoryImpl)), repository.NewWebhookRepository, wire.Bind(new(repository.WebhookRepository), new(*repository.WebhookRepositoryImpl)), repository.NewOutboxRepository, wire.Bind(new(repository.OutboxRepository), new(*repository.OutboxRepositoryImpl)), repository.NewCronRepository, wire.Bind(new(repository.CronRepository), new(*repository.CronRepositoryImpl)), repository.NewTxManager, wire.Bind(new(repository.TxManager), new(*repository.GormTxManager)))
+
+var serviceSet = wire.NewSet(service.NewUserService, wire.Bind(new(service.UserService), new(*service.UserServiceImpl)), service.NewOrganizationService, wire.Bind(new(service.OrganizationService), new(*service.OrganizationServiceImpl)), service.NewInvitationService, wire.Bind(new(service.InvitationService), new(*service.InvitationServiceImpl)), service.NewAuditService, wire.Bind(new(service.AuditService), new(*service.AuditServiceImpl)), service.NewUserImportService, wire.Bind(new(service.UserImportService), new(*service.UserImportServiceImpl)), service.NewWebhookService, wire.Bind(new(service.WebhookService), new(*service.WebhookServiceImpl)), service.NewOutboxRelay, service.NewWebhookDispatcher, service.NewCronScheduler, wire.Bind(new(service.CronService), new(*service.CronScheduler)))
 
-var serviceSet = wire.NewSet(service.NewUserService, wire.Bind(new(service.UserService), new(*service.UserServiceImpl)))
+var cronJobSet = wire.NewSet(service.NewPurgeDeletedUsersJob, service.NewExpireInvitationsJob, service.NewReencryptPIIJob, service.NewPruneCronRunsJob, NewCronJobs)
 
-var controllerSet = wire.NewSet(controller.NewUserController, controller.NewAuthController)
+var controllerSet = wire.NewSet(controller.NewUserController, controller.NewAuthController, controller.NewProfileController, controller.NewOrganizationController, controller.NewInvitationController, controller.NewUserImportController, controller.NewWebhookController, controller.NewQueueController, controller.NewCronController, controller.NewRedisController)
 
-var middlewareSet = wire.NewSet(middleware.NewAuthMiddleware, middleware.NewRateLimiterMiddleware)
+var middlewareSet = wire.NewSet(middleware.NewAuthMiddleware, middleware.NewRateLimiterMiddleware, middleware.NewTenantMiddleware, middleware.NewAdminMiddleware, middleware.NewDeadlineMiddleware, middleware.NewReadYourWritesMiddleware, middleware.NewRequestIDMiddleware)
 
 var routerSet = wire.NewSet(router.NewRouter)
 
+var appSet = wire.NewSet(NewApp, NewQueueWorker)
+
 var loggerSet = wire.NewSet(logger.NewZapLogger, wire.Bind(new(logger.Logger), new(*logger.ZapLogger)))
 
+var mailerSet = wire.NewSet(mailer.NewLogMailer, mailer.NewQueuedMailer, mailer.NewSendHandler, wire.Bind(new(mailer.Mailer), new(*mailer.QueuedMailer)))
+
 var jwtSet = wire.NewSet(middleware.NewJWT, jwtauth.NewJwtBlacklist, jwtauth.NewLoginLocked, jwtauth.NewJwtCacheUserinfo)
@@ -4,8 +4,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
@@ -15,47 +17,268 @@ type Config struct {
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Log      LogConfig      `mapstructure:"log"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	Invite   InviteConfig   `mapstructure:"invite"`
+	PII      PIIConfig      `mapstructure:"pii"`
+	Events   EventsConfig   `mapstructure:"events"`
+	Webhook  WebhookConfig  `mapstructure:"webhook"`
+	Queue    QueueConfig    `mapstructure:"queue"`
+	Cron     CronConfig     `mapstructure:"cron"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+	// RateLimits 限流规则，修改配置文件后无需重启即生效
+	RateLimits RateLimitConfig `mapstructure:"rate_limits"`
 }
 
 type AppConfig struct {
-	Name string `mapstructure:"name"`
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Name           string        `mapstructure:"name"`
+	Port           string        `mapstructure:"port"`
+	Mode           string        `mapstructure:"mode"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"` // 单个请求的处理时限
 }
 
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type DatabaseConfig struct {
+	Driver          string `mapstructure:"driver"` // mysql、postgres 或 sqlite
 	Username        string `mapstructure:"username"`
 	Password        string `mapstructure:"password"`
 	Host            string `mapstructure:"host"`
 	Port            int    `mapstructure:"port"`
-	DBname          string `mapstructure:"dbname"`
+	DBname          string `mapstructure:"dbname"`   // sqlite 时为数据库文件路径，:memory: 表示内存库
+	SSLMode         string `mapstructure:"ssl_mode"` // 仅 postgres 使用
 	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	TxMaxRetries    int    `mapstructure:"tx_max_retries"` // 死锁或序列化冲突时事务的最大重试次数
+
+	// 只读从库，未配置时所有请求走主库
+	Replicas             []ReplicaConfig `mapstructure:"replicas"`
+	ReplicaMaxLag        time.Duration   `mapstructure:"replica_max_lag"`        // 复制延迟超过该值的从库移出轮询
+	ReplicaCheckInterval time.Duration   `mapstructure:"replica_check_interval"` // 从库健康检查间隔
+	ReadYourWrites       bool            `mapstructure:"read_your_writes"`       // 发生写操作后，同一请求及同一客户端、用户随后的请求读主库
+}
+
+// ReplicaConfig 从库连接信息，未填写的账号密码沿用主库配置
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// ForReplica 返回连接指定从库所用的配置
+func (c DatabaseConfig) ForReplica(r ReplicaConfig) DatabaseConfig {
+	c.Host = r.Host
+	c.Port = r.Port
+	if r.Username != "" {
+		c.Username = r.Username
+	}
+	if r.Password != "" {
+		c.Password = r.Password
+	}
+	c.Replicas = nil
+	return c
 }
 
+// Redis 部署模式
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
 type RedisConfig struct {
-	Addr         string `mapstructure:"addr"`
-	Password     string `mapstructure:"password"`
-	DB           int    `mapstructure:"db"`
-	PoolSize     int    `mapstructure:"pool_size"`
-	DialTimeout  int    `mapstructure:"dial_timeout"`
-	ReadTimeout  int    `mapstructure:"read_timeout"`
-	WriteTimeout int    `mapstructure:"write_timeout"`
-	PoolTimeout  int    `mapstructure:"pool_timeout"`
+	Mode             string         `mapstructure:"mode"`              // single、sentinel 或 cluster
+	Addr             string         `mapstructure:"addr"`              // single 模式的地址
+	Addrs            []string       `mapstructure:"addrs"`             // sentinel 模式为哨兵地址，cluster 模式为集群节点地址
+	MasterName       string         `mapstructure:"master_name"`       // sentinel 模式的主节点名
+	SentinelPassword string         `mapstructure:"sentinel_password"` // 哨兵自身的密码，可为空
+	Username         string         `mapstructure:"username"`          // ACL 用户名，可为空
+	Password         string         `mapstructure:"password"`
+	DB               int            `mapstructure:"db"` // cluster 模式只能为 0
+	PoolSize         int            `mapstructure:"pool_size"`
+	DialTimeout      int            `mapstructure:"dial_timeout"`
+	ReadTimeout      int            `mapstructure:"read_timeout"`
+	WriteTimeout     int            `mapstructure:"write_timeout"`
+	PoolTimeout      int            `mapstructure:"pool_timeout"`
+	TLS              RedisTLSConfig `mapstructure:"tls"`
+	// RequireOnStartup 启动时 Redis 不可用是否退出。关闭后服务照常启动，依赖 Redis 的功能按 degrade 降级
+	RequireOnStartup    bool               `mapstructure:"require_on_startup"`
+	HealthCheckInterval time.Duration      `mapstructure:"health_check_interval"`
+	Degrade             RedisDegradeConfig `mapstructure:"degrade"`
+}
+
+// Redis 不可用时的降级策略
+const (
+	DegradeFailOpen   = "fail_open"   // 跳过该功能，放行请求
+	DegradeFailClosed = "fail_closed" // 拒绝请求
+	DegradeLocal      = "local"       // 改用进程内实现，各实例互不共享
+)
+
+// RedisDegradeConfig 各功能在 Redis 不可用时的降级策略
+type RedisDegradeConfig struct {
+	Blacklist string `mapstructure:"blacklist"`  // 令牌黑名单
+	LoginLock string `mapstructure:"login_lock"` // 登录失败计数和账户锁定
+	Cache     string `mapstructure:"cache"`      // redis 缓存后端
+	RateLimit string `mapstructure:"rate_limit"` // 限流
+}
+
+// RedisTLSConfig 连接 Redis 的 TLS 设置。cert_file 和 key_file 同时配置时启用客户端证书认证
+type RedisTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"` // 为空时使用系统根证书
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	ServerName         string `mapstructure:"server_name"` // 为空时使用连接地址中的主机名
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
 }
 
 type LogConfig struct {
-	Level string `mapstructure:"level"`
+	Level              string        `mapstructure:"level"`
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold"` // 超过该耗时的 SQL 以 warn 级别记录
+	RedactSQLParams    bool          `mapstructure:"redact_sql_params"`    // 日志中的 SQL 不带参数值，避免记录密码哈希、邮箱等数据
 }
 
 type JWTConfig struct {
-	SigningKey       string        `mapstructure:"signing_key"`        // JWT 签名密钥
-	Timeout          time.Duration `mapstructure:"timeout"`            // Token 过期时间
-	MaxRefresh       time.Duration `mapstructure:"max_refresh"`        // 最大刷新时间
-	CacheDuration    time.Duration `mapstructure:"cache_duration"`     // 用户信息缓存时间
-	MaxLoginAttempts int           `mapstructure:"max_login_attempts"` // 新增
-	LockDuration     time.Duration `mapstructure:"lock_duration"`      // 新增
+	SigningKey            string        `mapstructure:"signing_key"`             // JWT 签名密钥
+	Timeout               time.Duration `mapstructure:"timeout"`                 // Token 过期时间
+	MaxRefresh            time.Duration `mapstructure:"max_refresh"`             // 最大刷新时间
+	CacheDuration         time.Duration `mapstructure:"cache_duration"`          // 用户信息缓存时间
+	NegativeCacheDuration time.Duration `mapstructure:"negative_cache_duration"` // 用户不存在的结果缓存时间
+	MaxLoginAttempts      int           `mapstructure:"max_login_attempts"`      // 新增
+	LockDuration          time.Duration `mapstructure:"lock_duration"`           // 新增
+}
+
+type InviteConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // 邀请链接有效期
+}
+
+// PIIConfig 个人敏感字段加密。密钥为 base64 编码的 32 字节随机数，
+// 轮换时新增版本并修改 active_key，再执行 pii reencrypt；旧版本在重新加密完成前不能删除
+type PIIConfig struct {
+	ActiveKey int      `mapstructure:"active_key"` // 新数据使用的密钥版本
+	Keys      []PIIKey `mapstructure:"keys"`
+	IndexKey  string   `mapstructure:"index_key"` // 盲索引 HMAC 密钥，更换后需重建全部索引
+}
+
+type PIIKey struct {
+	Version int    `mapstructure:"version"`
+	Key     string `mapstructure:"key"`
+}
+
+// 支持的事件代理
+const (
+	BrokerRedis  = "redis"
+	BrokerMemory = "memory"
+)
+
+// EventsConfig 领域事件的 outbox 中继
+type EventsConfig struct {
+	Broker       string        `mapstructure:"broker"`         // redis 或 memory（仅用于测试，事件不出进程）
+	Stream       string        `mapstructure:"stream"`         // Redis Stream 名称
+	StreamMaxLen int64         `mapstructure:"stream_max_len"` // Stream 近似保留的最大条数
+	PollInterval time.Duration `mapstructure:"poll_interval"`  // outbox 轮询间隔
+	BatchSize    int           `mapstructure:"batch_size"`     // 每次轮询发布的最大事件数
+	MaxAttempts  int           `mapstructure:"max_attempts"`   // 超过后移入死信表
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`  // 首次重试的等待时间，之后按指数增长
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`    // 重试等待时间上限
+	PublishLease time.Duration `mapstructure:"publish_lease"`  // 领取事件后的占用时长，避免多实例重复发布
+}
+
+// WebhookConfig 对外 webhook 投递
+type WebhookConfig struct {
+	PollInterval      time.Duration `mapstructure:"poll_interval"`       // 待投递记录的轮询间隔
+	BatchSize         int           `mapstructure:"batch_size"`          // 每次轮询领取的最大投递数
+	Concurrency       int           `mapstructure:"concurrency"`         // 并发请求数
+	Timeout           time.Duration `mapstructure:"timeout"`             // 单次请求超时
+	MaxAttempts       int           `mapstructure:"max_attempts"`        // 单个投递的最大尝试次数
+	RetryBackoff      time.Duration `mapstructure:"retry_backoff"`       // 首次重试的等待时间，之后按指数增长
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`         // 重试等待时间上限
+	DeliveryLease     time.Duration `mapstructure:"delivery_lease"`      // 领取投递后的占用时长，应大于 timeout
+	DisableAfter      int           `mapstructure:"disable_after"`       // 端点连续失败次数达到该值后自动停用
+	AllowInsecureURLs bool          `mapstructure:"allow_insecure_urls"` // 允许 http 和内网地址，仅用于开发环境
+}
+
+// QueueConfig 基于 Redis 的后台任务队列
+type QueueConfig struct {
+	Queues          map[string]int `mapstructure:"queues"`           // 队列名及权重，权重越高被取到的概率越大
+	Concurrency     int            `mapstructure:"concurrency"`      // 每个实例同时执行的任务数
+	PollInterval    time.Duration  `mapstructure:"poll_interval"`    // 队列为空时的轮询间隔
+	Timeout         time.Duration  `mapstructure:"timeout"`          // 默认的任务执行时限，再过 30s 未确认的任务会被重新投递
+	MaxAttempts     int            `mapstructure:"max_attempts"`     // 默认的最大执行次数
+	RetryBackoff    time.Duration  `mapstructure:"retry_backoff"`    // 首次重试的等待时间，之后按指数增长
+	MaxBackoff      time.Duration  `mapstructure:"max_backoff"`      // 重试等待时间上限
+	ShutdownTimeout time.Duration  `mapstructure:"shutdown_timeout"` // 停机时等待执行中任务完成的时间，超时后放回队列
+	DeadMaxSize     int64          `mapstructure:"dead_max_size"`    // 每个队列保留的死信数量
+}
+
+// 定时任务的重叠策略：上一次运行尚未结束时新的触发如何处理
+const (
+	CronOverlapSkip  = "skip"  // 跳过本次触发，记录为 skipped
+	CronOverlapAllow = "allow" // 允许并发运行
+)
+
+// CronConfig 定时任务。每次触发只有一个实例执行，由 Redis 租约保证
+type CronConfig struct {
+	Timezone               string                   `mapstructure:"timezone"`                  // 计算触发时间的时区，默认为本地时区
+	Timeout                time.Duration            `mapstructure:"timeout"`                   // 默认的单次运行时限
+	HistoryRetention       time.Duration            `mapstructure:"history_retention"`         // 运行记录保留时长
+	PurgeDeletedUsersAfter time.Duration            `mapstructure:"purge_deleted_users_after"` // 注销用户软删除后保留多久再彻底删除
+	Jobs                   map[string]CronJobConfig `mapstructure:"jobs"`                      // 按任务名覆盖默认设置
+}
+
+// CronJobConfig 单个定时任务的设置，留空的字段使用任务自身的默认值
+type CronJobConfig struct {
+	Schedule string        `mapstructure:"schedule"` // cron 表达式
+	Overlap  string        `mapstructure:"overlap"`  // skip 或 allow
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// 支持的缓存后端
+const (
+	CacheRedis  = "redis"
+	CacheMemory = "memory"
+	CacheNone   = "none"
+)
+
+// CacheConfig 缓存后端。memory 为进程内缓存，多实例部署时各实例互不共享
+type CacheConfig struct {
+	Driver     string        `mapstructure:"driver"`      // redis、memory 或 none
+	MaxEntries int           `mapstructure:"max_entries"` // 进程内缓存的最大条目数
+	LocalTTL   time.Duration `mapstructure:"local_ttl"`   // redis 后端前的进程内缓存有效期，0 表示不启用
+	// EarlyRefreshBeta 临近过期时随机提前刷新的力度（XFetch），1 为推荐值，0 表示不提前
+	EarlyRefreshBeta float64 `mapstructure:"early_refresh_beta"`
+}
+
+// RateLimitConfig 命名的限流策略及其绑定的路由
+type RateLimitConfig struct {
+	Policies map[string]RateLimitPolicy `mapstructure:"policies"`
+	Routes   []RateLimitRoute           `mapstructure:"routes"`
+	// TrustedProxies 可信代理的地址或网段，只采信它们添加的 X-Forwarded-For
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// APIKeys api_key 计数方式认可的 key，ID 到 key 的 SHA-256 摘要（十六进制），不保存明文
+	APIKeys map[string]string `mapstructure:"api_keys"`
+}
+
+// RateLimitPolicy 每个 key 在 window 内最多 limit 次请求
+type RateLimitPolicy struct {
+	Limit     int64         `mapstructure:"limit"`
+	Window    time.Duration `mapstructure:"window"`
+	Algorithm string        `mapstructure:"algorithm"` // sliding_window（默认）、token_bucket 或 gcra
+	Burst     int64         `mapstructure:"burst"`     // token_bucket 和 gcra 允许连续突发的请求数，默认等于 limit
+	Key       string        `mapstructure:"key"`       // ip（默认）、user、api_key、header:<名称>，可用 + 组合，缺失的部分按 ip
+}
+
+// RateLimitRoute 将策略绑定到路由。route 为 gin 的路由模板，可带请求方法，
+// 以 /* 结尾时匹配该前缀下的全部路由，例如 "POST /api/login"、"/api/users/:username"、"/api/admin/*"。
+// 一个路由匹配多条绑定时，各策略都要满足；按配置顺序逐条计入用量，被后面的策略拒绝的请求也计入前面的策略
+type RateLimitRoute struct {
+	Route  string `mapstructure:"route"`
+	Policy string `mapstructure:"policy"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -76,6 +299,16 @@ func LoadConfig(path string) (*Config, error) {
 		}
 	}
 
+	cfg, err := decode()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("config loaded successfully")
+	return cfg, nil
+}
+
+func decode() (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -85,24 +318,67 @@ func LoadConfig(path string) (*Config, error) {
 	if err := validateConfig(&cfg); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
-
-	log.Println("config loaded successfully")
 	return &cfg, nil
 }
 
+var (
+	watchOnce sync.Once
+	watchMu   sync.Mutex
+	watchers  []func(*Config)
+)
+
+// OnChange 配置文件修改后重新加载，校验通过时调用 fn，未通过时保留旧配置。
+// 只有读取新配置的组件会生效，目前为限流规则，其余配置仍需重启
+func OnChange(fn func(*Config)) {
+	watchMu.Lock()
+	watchers = append(watchers, fn)
+	watchMu.Unlock()
+
+	watchOnce.Do(func() {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			cfg, err := decode()
+			if err != nil {
+				log.Printf("config reload failed, keeping previous config: %v", err)
+				return
+			}
+			log.Printf("config reloaded: %s", e.Name)
+			watchMu.Lock()
+			fns := append([]func(*Config){}, watchers...)
+			watchMu.Unlock()
+			for _, fn := range fns {
+				fn(cfg)
+			}
+		})
+		viper.WatchConfig()
+	})
+}
+
 func setDefaults() {
 	// App defaults
 	viper.SetDefault("app.port", "8080")
 	viper.SetDefault("app.mode", "debug")
+	viper.SetDefault("app.request_timeout", time.Second*30)
 
 	// Database defaults
+	// 端口不设默认值，未配置时按驱动使用标准端口
+	viper.SetDefault("database.driver", DriverMySQL)
 	viper.SetDefault("database.host", "127.0.0.1")
-	viper.SetDefault("database.port", 3306)
+	viper.SetDefault("database.ssl_mode", "disable")
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.max_open_conns", 100)
 	viper.SetDefault("database.conn_max_lifetime", 60)
+	viper.SetDefault("database.tx_max_retries", 3)
+	viper.SetDefault("database.replica_max_lag", time.Second*5)
+	viper.SetDefault("database.replica_check_interval", time.Second*5)
+	viper.SetDefault("database.read_your_writes", true)
+
+	// Log defaults
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.slow_query_threshold", time.Millisecond*200)
+	viper.SetDefault("log.redact_sql_params", true)
 
 	// Redis defaults
+	viper.SetDefault("redis.mode", RedisModeSingle)
 	viper.SetDefault("redis.addr", "localhost:6379")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.pool_size", 10)
@@ -110,6 +386,12 @@ func setDefaults() {
 	viper.SetDefault("redis.read_timeout", 30)
 	viper.SetDefault("redis.write_timeout", 30)
 	viper.SetDefault("redis.pool_timeout", 30)
+	viper.SetDefault("redis.require_on_startup", true)
+	viper.SetDefault("redis.health_check_interval", time.Second)
+	viper.SetDefault("redis.degrade.blacklist", DegradeLocal)
+	viper.SetDefault("redis.degrade.login_lock", DegradeLocal)
+	viper.SetDefault("redis.degrade.cache", DegradeFailOpen)
+	viper.SetDefault("redis.degrade.rate_limit", DegradeLocal)
 
 	//jwt defaults
 	viper.SetDefault("jwt.timeout", time.Hour*8)           // 默认24小时
@@ -117,7 +399,70 @@ func setDefaults() {
 	viper.SetDefault("jwt.cache_duration", time.Second*60) //
 	viper.SetDefault("jwt.max_login_attempts", 3)          //
 	viper.SetDefault("jwt.lock_duration", time.Minute*5)   //
+	viper.SetDefault("jwt.negative_cache_duration", time.Second*10)
 
+	// invite defaults
+	viper.SetDefault("invite.ttl", time.Hour*72)
+
+	// events defaults
+	viper.SetDefault("events.broker", BrokerRedis)
+	viper.SetDefault("events.stream", "events")
+	viper.SetDefault("events.stream_max_len", 100000)
+	viper.SetDefault("events.poll_interval", time.Second)
+	viper.SetDefault("events.batch_size", 100)
+	viper.SetDefault("events.max_attempts", 10)
+	viper.SetDefault("events.retry_backoff", time.Second)
+	viper.SetDefault("events.max_backoff", time.Minute*10)
+	viper.SetDefault("events.publish_lease", time.Second*30)
+
+	// webhook defaults
+	viper.SetDefault("webhook.poll_interval", time.Second)
+	viper.SetDefault("webhook.batch_size", 50)
+	viper.SetDefault("webhook.concurrency", 8)
+	viper.SetDefault("webhook.timeout", time.Second*10)
+	viper.SetDefault("webhook.max_attempts", 8)
+	viper.SetDefault("webhook.retry_backoff", time.Second*10)
+	viper.SetDefault("webhook.max_backoff", time.Hour)
+	viper.SetDefault("webhook.delivery_lease", time.Minute)
+	viper.SetDefault("webhook.disable_after", 50)
+
+	// queue defaults
+	viper.SetDefault("queue.queues", map[string]int{"critical": 6, "default": 3, "low": 1})
+	viper.SetDefault("queue.concurrency", 10)
+	viper.SetDefault("queue.poll_interval", time.Second)
+	viper.SetDefault("queue.timeout", time.Minute*5)
+	viper.SetDefault("queue.max_attempts", 5)
+	viper.SetDefault("queue.retry_backoff", time.Second*10)
+	viper.SetDefault("queue.max_backoff", time.Hour)
+	viper.SetDefault("queue.shutdown_timeout", time.Second*10)
+	viper.SetDefault("queue.dead_max_size", 10000)
+
+	// cron defaults
+	viper.SetDefault("cron.timeout", time.Hour)
+	viper.SetDefault("cron.history_retention", time.Hour*24*30)
+	viper.SetDefault("cron.purge_deleted_users_after", time.Hour*24*30)
+
+	// rate limit defaults，未配置时对登录、注册等公开接口按 IP 限流，登录后的接口按用户限流
+	viper.SetDefault("rate_limits.policies", map[string]interface{}{
+		"public": map[string]interface{}{"limit": 2, "window": "5s"},
+		"user":   map[string]interface{}{"limit": 120, "window": "1m", "algorithm": "token_bucket", "burst": 20, "key": "user"},
+	})
+	viper.SetDefault("rate_limits.routes", []map[string]interface{}{
+		{"route": "POST /api/register", "policy": "public"},
+		{"route": "POST /api/login", "policy": "public"},
+		{"route": "POST /api/email/verify", "policy": "public"},
+		{"route": "POST /api/invitations/accept", "policy": "public"},
+		{"route": "/api/*", "policy": "user"},
+	})
+	viper.SetDefault("rate_limits.trusted_proxies", []string{
+		"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1", "fc00::/7",
+	})
+
+	// cache defaults
+	viper.SetDefault("cache.driver", CacheRedis)
+	viper.SetDefault("cache.max_entries", 10000)
+	viper.SetDefault("cache.local_ttl", time.Second*5)
+	viper.SetDefault("cache.early_refresh_beta", 1.0)
 }
 
 func validateConfig(cfg *Config) error {
@@ -128,22 +473,88 @@ func validateConfig(cfg *Config) error {
 	if cfg.App.Port == "" {
 		return fmt.Errorf("app port cannot be empty")
 	}
-
-	if cfg.Database.Host == "" {
-		return fmt.Errorf("database host cannot be empty")
-	}
-	if cfg.Database.Username == "" {
-		return fmt.Errorf("database username cannot be empty")
+	if cfg.App.RequestTimeout <= 0 {
+		return fmt.Errorf("app request timeout must be positive")
 	}
-	if cfg.Database.Password == "" {
-		return fmt.Errorf("database password cannot be empty")
+
+	switch cfg.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		if cfg.Database.Host == "" {
+			return fmt.Errorf("database host cannot be empty")
+		}
+		if cfg.Database.Username == "" {
+			return fmt.Errorf("database username cannot be empty")
+		}
+		if cfg.Database.Password == "" {
+			return fmt.Errorf("database password cannot be empty")
+		}
+	case DriverSQLite:
+	default:
+		return fmt.Errorf("unsupported database driver %q", cfg.Database.Driver)
 	}
 	if cfg.Database.DBname == "" {
 		return fmt.Errorf("database name cannot be empty")
 	}
+	if len(cfg.Database.Replicas) > 0 {
+		if cfg.Database.Driver == DriverSQLite {
+			return fmt.Errorf("database replicas are not supported for sqlite")
+		}
+		for _, r := range cfg.Database.Replicas {
+			if r.Host == "" {
+				return fmt.Errorf("database replica host cannot be empty")
+			}
+		}
+		if cfg.Database.ReplicaMaxLag <= 0 {
+			return fmt.Errorf("database replica max lag must be positive")
+		}
+		if cfg.Database.ReplicaCheckInterval <= 0 {
+			return fmt.Errorf("database replica check interval must be positive")
+		}
+	}
 
-	if cfg.Redis.Addr == "" {
-		return fmt.Errorf("redis address cannot be empty")
+	if cfg.Log.SlowQueryThreshold <= 0 {
+		return fmt.Errorf("log slow query threshold must be positive")
+	}
+
+	switch cfg.Redis.Mode {
+	case RedisModeSingle:
+		if cfg.Redis.Addr == "" {
+			return fmt.Errorf("redis address cannot be empty")
+		}
+	case RedisModeSentinel:
+		if cfg.Redis.MasterName == "" {
+			return fmt.Errorf("redis master name cannot be empty in sentinel mode")
+		}
+		if len(cfg.Redis.Addrs) == 0 {
+			return fmt.Errorf("redis sentinel addresses cannot be empty")
+		}
+	case RedisModeCluster:
+		if len(cfg.Redis.Addrs) == 0 {
+			return fmt.Errorf("redis cluster addresses cannot be empty")
+		}
+		if cfg.Redis.DB != 0 {
+			return fmt.Errorf("redis db must be 0 in cluster mode")
+		}
+	default:
+		return fmt.Errorf("unsupported redis mode %q", cfg.Redis.Mode)
+	}
+	if (cfg.Redis.TLS.CertFile == "") != (cfg.Redis.TLS.KeyFile == "") {
+		return fmt.Errorf("redis tls cert file and key file must be set together")
+	}
+	if cfg.Redis.HealthCheckInterval <= 0 {
+		return fmt.Errorf("redis health check interval must be positive")
+	}
+	for feature, policy := range map[string]string{
+		"blacklist":  cfg.Redis.Degrade.Blacklist,
+		"login_lock": cfg.Redis.Degrade.LoginLock,
+		"cache":      cfg.Redis.Degrade.Cache,
+		"rate_limit": cfg.Redis.Degrade.RateLimit,
+	} {
+		switch policy {
+		case DegradeFailOpen, DegradeFailClosed, DegradeLocal:
+		default:
+			return fmt.Errorf("unsupported redis degrade policy %q for %s", policy, feature)
+		}
 	}
 
 	// 验证 JWT 配置
@@ -162,8 +573,145 @@ func validateConfig(cfg *Config) error {
 	if cfg.JWT.MaxLoginAttempts <= 0 {
 		return fmt.Errorf("jwt max login attempts must be positive")
 	}
+	if cfg.JWT.NegativeCacheDuration < 0 {
+		return fmt.Errorf("jwt negative cache duration must not be negative")
+	}
 	if cfg.JWT.LockDuration <= 0 {
 		return fmt.Errorf("jwt lock duration must be positive")
 	}
+	if cfg.Invite.TTL <= 0 {
+		return fmt.Errorf("invite ttl must be positive")
+	}
+	if len(cfg.PII.Keys) == 0 {
+		return fmt.Errorf("pii keys cannot be empty")
+	}
+	if cfg.PII.IndexKey == "" {
+		return fmt.Errorf("pii index key cannot be empty")
+	}
+
+	// 验证事件配置
+	switch cfg.Events.Broker {
+	case BrokerRedis:
+		if cfg.Events.Stream == "" {
+			return fmt.Errorf("events stream cannot be empty")
+		}
+	case BrokerMemory:
+	default:
+		return fmt.Errorf("unsupported events broker %q", cfg.Events.Broker)
+	}
+	if cfg.Events.PollInterval <= 0 {
+		return fmt.Errorf("events poll interval must be positive")
+	}
+	if cfg.Events.BatchSize <= 0 {
+		return fmt.Errorf("events batch size must be positive")
+	}
+	if cfg.Events.MaxAttempts <= 0 {
+		return fmt.Errorf("events max attempts must be positive")
+	}
+	if cfg.Events.RetryBackoff <= 0 || cfg.Events.MaxBackoff < cfg.Events.RetryBackoff {
+		return fmt.Errorf("events retry backoff must be positive and not exceed max backoff")
+	}
+	if cfg.Events.PublishLease <= 0 {
+		return fmt.Errorf("events publish lease must be positive")
+	}
+
+	// 验证 webhook 配置
+	if cfg.Webhook.PollInterval <= 0 {
+		return fmt.Errorf("webhook poll interval must be positive")
+	}
+	if cfg.Webhook.BatchSize <= 0 || cfg.Webhook.Concurrency <= 0 {
+		return fmt.Errorf("webhook batch size and concurrency must be positive")
+	}
+	if cfg.Webhook.Timeout <= 0 || cfg.Webhook.DeliveryLease <= cfg.Webhook.Timeout {
+		return fmt.Errorf("webhook timeout must be positive and shorter than delivery lease")
+	}
+	if cfg.Webhook.MaxAttempts <= 0 {
+		return fmt.Errorf("webhook max attempts must be positive")
+	}
+	if cfg.Webhook.RetryBackoff <= 0 || cfg.Webhook.MaxBackoff < cfg.Webhook.RetryBackoff {
+		return fmt.Errorf("webhook retry backoff must be positive and not exceed max backoff")
+	}
+	if cfg.Webhook.DisableAfter <= 0 {
+		return fmt.Errorf("webhook disable after must be positive")
+	}
+
+	// 验证队列配置
+	if _, ok := cfg.Queue.Queues["default"]; !ok {
+		return fmt.Errorf("queue queues must include \"default\"")
+	}
+	for name, weight := range cfg.Queue.Queues {
+		if weight <= 0 {
+			return fmt.Errorf("queue %q weight must be positive", name)
+		}
+	}
+	if cfg.Queue.Concurrency <= 0 || cfg.Queue.PollInterval <= 0 {
+		return fmt.Errorf("queue concurrency and poll interval must be positive")
+	}
+	if cfg.Queue.Timeout <= 0 || cfg.Queue.MaxAttempts <= 0 {
+		return fmt.Errorf("queue timeout and max attempts must be positive")
+	}
+	if cfg.Queue.RetryBackoff <= 0 || cfg.Queue.MaxBackoff < cfg.Queue.RetryBackoff {
+		return fmt.Errorf("queue retry backoff must be positive and not exceed max backoff")
+	}
+	if cfg.Queue.ShutdownTimeout <= 0 || cfg.Queue.DeadMaxSize <= 0 {
+		return fmt.Errorf("queue shutdown timeout and dead max size must be positive")
+	}
+
+	// 验证定时任务配置
+	if cfg.Cron.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Cron.Timezone); err != nil {
+			return fmt.Errorf("invalid cron timezone: %w", err)
+		}
+	}
+	if cfg.Cron.Timeout <= 0 || cfg.Cron.HistoryRetention <= 0 || cfg.Cron.PurgeDeletedUsersAfter <= 0 {
+		return fmt.Errorf("cron timeout, history retention and purge deleted users after must be positive")
+	}
+	for name, job := range cfg.Cron.Jobs {
+		if job.Overlap != "" && job.Overlap != CronOverlapSkip && job.Overlap != CronOverlapAllow {
+			return fmt.Errorf("cron job %q overlap must be %q or %q", name, CronOverlapSkip, CronOverlapAllow)
+		}
+		if job.Timeout < 0 {
+			return fmt.Errorf("cron job %q timeout must not be negative", name)
+		}
+	}
+
+	// 验证限流配置，算法、计数方式、可信代理和 API key 由限流中间件校验
+	for name, p := range cfg.RateLimits.Policies {
+		if p.Limit <= 0 || p.Window <= 0 {
+			return fmt.Errorf("rate limit policy %q limit and window must be positive", name)
+		}
+		if p.Burst < 0 {
+			return fmt.Errorf("rate limit policy %q burst must not be negative", name)
+		}
+	}
+	for _, r := range cfg.RateLimits.Routes {
+		if r.Route == "" {
+			return fmt.Errorf("rate limit route cannot be empty")
+		}
+		if _, ok := cfg.RateLimits.Policies[r.Policy]; !ok {
+			return fmt.Errorf("rate limit route %q uses unknown policy %q", r.Route, r.Policy)
+		}
+	}
+
+	// 验证缓存配置
+	if cfg.Cache.EarlyRefreshBeta < 0 {
+		return fmt.Errorf("cache early refresh beta must not be negative")
+	}
+	switch cfg.Cache.Driver {
+	case CacheNone:
+	case CacheRedis:
+		if cfg.Cache.LocalTTL < 0 {
+			return fmt.Errorf("cache local ttl must not be negative")
+		}
+		if (cfg.Cache.LocalTTL > 0 || cfg.Redis.Degrade.Cache == DegradeLocal) && cfg.Cache.MaxEntries <= 0 {
+			return fmt.Errorf("cache max entries must be positive")
+		}
+	case CacheMemory:
+		if cfg.Cache.MaxEntries <= 0 {
+			return fmt.Errorf("cache max entries must be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported cache driver %q", cfg.Cache.Driver)
+	}
 	return nil
 }
@@ -0,0 +1,122 @@
+// internal/controller/cron_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CronController 定时任务的查看、手动触发和暂停，仅系统管理员可用
+type CronController struct {
+	cronService service.CronService
+	logger      logger.Logger
+}
+
+func NewCronController(cronService service.CronService, logger logger.Logger) *CronController {
+	return &CronController{
+		cronService: cronService,
+		logger:      logger.With(zap.String("module", "cron_controller")),
+	}
+}
+
+// List 全部任务及状态
+func (c *CronController) List(ctx *gin.Context) {
+	infos, err := c.cronService.ListJobs(ctx.Request.Context())
+	if err != nil {
+		c.handleServiceError(ctx, "list cron jobs failed", err)
+		return
+	}
+	resp := make([]*dto.CronJobResponse, 0, len(infos))
+	for _, info := range infos {
+		resp = append(resp, &dto.CronJobResponse{
+			Name:      info.Name,
+			Schedule:  info.Schedule,
+			Overlap:   info.Overlap,
+			Timeout:   info.Timeout.String(),
+			Paused:    info.Paused,
+			NextRunAt: info.NextRunAt,
+			LastRun:   info.LastRun,
+		})
+	}
+	utils.Success(ctx, resp)
+}
+
+// ListRuns 运行记录，按开始时间倒序，limit 默认 50
+func (c *CronController) ListRuns(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	runs, err := c.cronService.ListRuns(ctx.Request.Context(), ctx.Param("name"), limit)
+	if err != nil {
+		c.handleServiceError(ctx, "list cron runs failed", err)
+		return
+	}
+	utils.Success(ctx, runs)
+}
+
+// Trigger 立即运行一次，返回运行记录，结果通过 ListRuns 查看
+func (c *CronController) Trigger(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	run, err := c.cronService.Trigger(ctx.Request.Context(), ctx.Param("name"), user.ID)
+	if err != nil {
+		c.handleServiceError(ctx, "trigger cron job failed", err)
+		return
+	}
+	utils.Success(ctx, run)
+}
+
+// Pause 暂停计划触发
+func (c *CronController) Pause(ctx *gin.Context) {
+	c.setPaused(ctx, true)
+}
+
+// Resume 恢复计划触发
+func (c *CronController) Resume(ctx *gin.Context) {
+	c.setPaused(ctx, false)
+}
+
+func (c *CronController) setPaused(ctx *gin.Context, paused bool) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	if err := c.cronService.SetPaused(ctx.Request.Context(), ctx.Param("name"), paused, user.ID); err != nil {
+		c.handleServiceError(ctx, "update cron job failed", err)
+		return
+	}
+	if paused {
+		utils.Success(ctx, "cron job paused")
+	} else {
+		utils.Success(ctx, "cron job resumed")
+	}
+}
+
+func (c *CronController) handleServiceError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrCronJobNotFound):
+		utils.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCronJobRunning):
+		utils.Error(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrSchedulerNotActive):
+		utils.Error(ctx, http.StatusServiceUnavailable, err.Error())
+	default:
+		c.logger.Error(msg, zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
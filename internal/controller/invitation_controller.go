@@ -0,0 +1,120 @@
+// internal/controller/invitation_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type InvitationController struct {
+	inviteService service.InvitationService
+	logger        logger.Logger
+}
+
+func NewInvitationController(
+	inviteService service.InvitationService,
+	logger logger.Logger,
+) *InvitationController {
+	return &InvitationController{
+		inviteService: inviteService,
+		logger:        logger.With(zap.String("module", "invitation_controller")),
+	}
+}
+
+// Create 邀请成员加入当前组织
+func (c *InvitationController) Create(ctx *gin.Context) {
+	var req dto.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	invitation, err := c.inviteService.CreateInvitation(ctx.Request.Context(), ctx.GetUint("userID"), req.Email, req.Role)
+	if err != nil {
+		c.handleServiceError(ctx, "create invitation failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewInvitationResponse(invitation))
+}
+
+// List 列出待处理的邀请
+func (c *InvitationController) List(ctx *gin.Context) {
+	invitations, err := c.inviteService.ListPending(ctx.Request.Context())
+	if err != nil {
+		c.handleServiceError(ctx, "list invitations failed", err)
+		return
+	}
+	resp := make([]*dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		resp = append(resp, dto.NewInvitationResponse(&invitations[i]))
+	}
+	utils.Success(ctx, resp)
+}
+
+// Resend 重新发送邀请
+func (c *InvitationController) Resend(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	invitation, err := c.inviteService.ResendInvitation(ctx.Request.Context(), ctx.GetUint("userID"), uint(id))
+	if err != nil {
+		c.handleServiceError(ctx, "resend invitation failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewInvitationResponse(invitation))
+}
+
+// Revoke 撤销邀请
+func (c *InvitationController) Revoke(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if err := c.inviteService.RevokeInvitation(ctx.Request.Context(), ctx.GetUint("userID"), uint(id)); err != nil {
+		c.handleServiceError(ctx, "revoke invitation failed", err)
+		return
+	}
+	utils.Success(ctx, "invitation revoked")
+}
+
+// Accept 接受邀请（公共接口）
+func (c *InvitationController) Accept(ctx *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	membership, err := c.inviteService.AcceptInvitation(ctx.Request.Context(), req.Token, req.Username, req.Password)
+	if err != nil {
+		c.handleServiceError(ctx, "accept invitation failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewMemberResponse(membership))
+}
+
+func (c *InvitationController) handleServiceError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInvitation),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrUsernameRequired),
+		errors.Is(err, service.ErrWeakPassword):
+		utils.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvitationPending),
+		errors.Is(err, service.ErrAlreadyMember):
+		utils.Error(ctx, http.StatusConflict, err.Error())
+	default:
+		c.logger.Error(msg, zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
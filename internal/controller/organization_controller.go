@@ -0,0 +1,186 @@
+// internal/controller/organization_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/middleware"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OrganizationController struct {
+	orgService    service.OrganizationService
+	jwtMiddleware *middleware.JWT
+	logger        logger.Logger
+}
+
+func NewOrganizationController(
+	orgService service.OrganizationService,
+	jwtMiddleware *middleware.JWT,
+	logger logger.Logger,
+) *OrganizationController {
+	return &OrganizationController{
+		orgService:    orgService,
+		jwtMiddleware: jwtMiddleware,
+		logger:        logger.With(zap.String("module", "organization_controller")),
+	}
+}
+
+// Create 创建组织，创建者成为 owner
+func (c *OrganizationController) Create(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	var req dto.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	org, err := c.orgService.CreateOrganization(ctx.Request.Context(), user.ID, req.Name, req.Slug)
+	if err != nil {
+		c.handleServiceError(ctx, "create organization failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewOrganizationResponse(org, model.RoleOwner))
+}
+
+// List 列出当前用户加入的组织
+func (c *OrganizationController) List(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	memberships, err := c.orgService.ListUserOrganizations(ctx.Request.Context(), user.ID)
+	if err != nil {
+		c.handleServiceError(ctx, "list organizations failed", err)
+		return
+	}
+	resp := make([]*dto.OrganizationResponse, 0, len(memberships))
+	for i := range memberships {
+		if memberships[i].Organization == nil {
+			continue
+		}
+		resp = append(resp, dto.NewOrganizationResponse(memberships[i].Organization, memberships[i].Role))
+	}
+	utils.Success(ctx, resp)
+}
+
+// Switch 切换当前组织：校验成员关系后签发新令牌，旧令牌加入黑名单
+func (c *OrganizationController) Switch(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	orgID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if _, err := c.orgService.GetMembership(ctx.Request.Context(), uint(orgID), user.ID); err != nil {
+		c.handleServiceError(ctx, "switch organization failed", err)
+		return
+	}
+	token, expire, err := c.jwtMiddleware.GenerateToken(user, uint(orgID))
+	if err != nil {
+		c.logger.Error("token generate failed", zap.Uint("user_id", user.ID), zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+		return
+	}
+	if err := c.jwtMiddleware.JwtBlacklist.AddTokenBlacklist(ctx); err != nil {
+		c.logger.Warn("blacklist token after switching organization failed", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	utils.Success(ctx, dto.TokenResponse{Token: token, Expire: expire})
+}
+
+// ListMembers 列出当前组织成员
+func (c *OrganizationController) ListMembers(ctx *gin.Context) {
+	memberships, err := c.orgService.ListMembers(ctx.Request.Context())
+	if err != nil {
+		c.handleServiceError(ctx, "list members failed", err)
+		return
+	}
+	resp := make([]*dto.MemberResponse, 0, len(memberships))
+	for i := range memberships {
+		resp = append(resp, dto.NewMemberResponse(&memberships[i]))
+	}
+	utils.Success(ctx, resp)
+}
+
+// AddMember 将已注册用户加入当前组织
+func (c *OrganizationController) AddMember(ctx *gin.Context) {
+	var req dto.AddMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	membership, err := c.orgService.AddMember(ctx.Request.Context(), req.Username, req.Role)
+	if err != nil {
+		c.handleServiceError(ctx, "add member failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewMemberResponse(membership))
+}
+
+// UpdateMemberRole 修改成员角色
+func (c *OrganizationController) UpdateMemberRole(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	var req dto.UpdateMemberRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if err := c.orgService.UpdateMemberRole(ctx.Request.Context(), uint(userID), req.Role); err != nil {
+		c.handleServiceError(ctx, "update member role failed", err)
+		return
+	}
+	utils.Success(ctx, "member role updated")
+}
+
+// RemoveMember 移除成员
+func (c *OrganizationController) RemoveMember(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if err := c.orgService.RemoveMember(ctx.Request.Context(), uint(userID)); err != nil {
+		c.handleServiceError(ctx, "remove member failed", err)
+		return
+	}
+	utils.Success(ctx, "member removed")
+}
+
+func (c *OrganizationController) handleServiceError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotMember):
+		utils.Error(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrAlreadyMember),
+		errors.Is(err, service.ErrSlugTaken):
+		utils.Error(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrOwnerImmutable):
+		utils.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.Error(ctx, http.StatusNotFound, "user not found")
+	default:
+		c.logger.Error(msg, zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
@@ -0,0 +1,188 @@
+// internal/controller/profile_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/middleware"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnauthenticated = errors.New("未登录")
+	ErrInternal        = errors.New("服务器内部错误")
+)
+
+type ProfileController struct {
+	userService   service.UserService
+	jwtMiddleware *middleware.JWT
+	logger        logger.Logger
+}
+
+func NewProfileController(
+	userService service.UserService,
+	jwtMiddleware *middleware.JWT,
+	logger logger.Logger,
+) *ProfileController {
+	return &ProfileController{
+		userService:   userService,
+		jwtMiddleware: jwtMiddleware,
+		logger:        logger.With(zap.String("module", "profile_controller")),
+	}
+}
+
+// GetMe 获取当前用户资料
+func (c *ProfileController) GetMe(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	// 上下文中的用户来自鉴权缓存，不含资料字段
+	profile, err := c.userService.GetUserByID(ctx.Request.Context(), user.ID)
+	if err != nil {
+		c.handleServiceError(ctx, "get profile failed", user.ID, err)
+		return
+	}
+	utils.Success(ctx, dto.NewProfileResponse(profile))
+}
+
+// UpdateMe 修改当前用户资料
+func (c *ProfileController) UpdateMe(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	var req dto.UpdateProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	updated, err := c.userService.UpdateProfile(ctx.Request.Context(), user.ID, req.Fields())
+	if err != nil {
+		c.logger.Error("update profile failed", zap.Uint("user_id", user.ID), zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+		return
+	}
+	c.jwtMiddleware.ClearUserCache(ctx.Request.Context(), user.ID)
+	utils.Success(ctx, dto.NewProfileResponse(updated))
+}
+
+// ChangePassword 修改密码，其他会话全部失效，并为当前客户端签发新令牌
+func (c *ProfileController) ChangePassword(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	updated, err := c.userService.ChangePassword(ctx.Request.Context(), user.ID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		c.handleServiceError(ctx, "change password failed", user.ID, err)
+		return
+	}
+	c.jwtMiddleware.ClearUserCache(ctx.Request.Context(), user.ID)
+
+	token, expire, err := c.jwtMiddleware.GenerateToken(updated, middleware.ClaimTenantID(ctx))
+	if err != nil {
+		c.logger.Error("token generate failed", zap.Uint("user_id", user.ID), zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+		return
+	}
+	utils.Success(ctx, dto.TokenResponse{Token: token, Expire: expire})
+}
+
+// ChangeEmail 申请修改邮箱，向新邮箱发送验证令牌
+func (c *ProfileController) ChangeEmail(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	var req dto.ChangeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if err := c.userService.RequestEmailChange(ctx.Request.Context(), user.ID, req.Password, req.NewEmail); err != nil {
+		c.handleServiceError(ctx, "request email change failed", user.ID, err)
+		return
+	}
+	utils.Custom(ctx, http.StatusAccepted, "verification email sent", nil)
+}
+
+// VerifyEmail 确认邮箱变更
+func (c *ProfileController) VerifyEmail(ctx *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	user, err := c.userService.ConfirmEmailChange(ctx.Request.Context(), req.Token)
+	if err != nil {
+		c.handleServiceError(ctx, "confirm email change failed", 0, err)
+		return
+	}
+	c.jwtMiddleware.ClearUserCache(ctx.Request.Context(), user.ID)
+	utils.Success(ctx, dto.NewProfileResponse(user))
+}
+
+// CloseAccount 注销当前账户
+func (c *ProfileController) CloseAccount(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		utils.Error(ctx, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		return
+	}
+	var req dto.CloseAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	if err := c.userService.CloseAccount(ctx.Request.Context(), user.ID, req.Password); err != nil {
+		c.handleServiceError(ctx, "close account failed", user.ID, err)
+		return
+	}
+	c.jwtMiddleware.ClearUserCache(ctx.Request.Context(), user.ID)
+	if err := c.jwtMiddleware.JwtBlacklist.AddTokenBlacklist(ctx); err != nil {
+		c.logger.Warn("blacklist token after account closure failed", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	utils.Success(ctx, "account closed")
+}
+
+func (c *ProfileController) handleServiceError(ctx *gin.Context, msg string, userID uint, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPassword):
+		utils.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEmailTaken):
+		utils.Error(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidEmailToken):
+		utils.Error(ctx, http.StatusBadRequest, err.Error())
+	default:
+		c.logger.Error(msg, zap.Uint("user_id", userID), zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
+
+// currentUser 读取 JWT Authorizator 写入上下文的当前用户，只有 ID、状态、角色和令牌版本
+func currentUser(ctx *gin.Context) (*model.User, bool) {
+	value, exists := ctx.Get("currentUser")
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*model.User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,97 @@
+// internal/controller/queue_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/queue"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeadJobLimit = 50
+	maxDeadJobLimit     = 500
+)
+
+// QueueController 后台任务队列的状态查看和死信处理，仅系统管理员可用
+type QueueController struct {
+	client *queue.Client
+	logger logger.Logger
+}
+
+func NewQueueController(client *queue.Client, logger logger.Logger) *QueueController {
+	return &QueueController{
+		client: client,
+		logger: logger.With(zap.String("module", "queue_controller")),
+	}
+}
+
+// Stats 各队列的任务数
+func (c *QueueController) Stats(ctx *gin.Context) {
+	stats, err := c.client.Stats(ctx.Request.Context())
+	if err != nil {
+		c.handleQueueError(ctx, "get queue stats failed", err)
+		return
+	}
+	utils.Success(ctx, stats)
+}
+
+// ListDead 死信任务，按失败时间倒序，支持 offset/limit 分页
+func (c *QueueController) ListDead(ctx *gin.Context) {
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultDeadJobLimit)))
+	if err != nil || limit <= 0 || limit > maxDeadJobLimit {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	jobs, err := c.client.DeadJobs(ctx.Request.Context(), ctx.Param("queue"), offset, limit)
+	if err != nil {
+		c.handleQueueError(ctx, "list dead jobs failed", err)
+		return
+	}
+	resp := make([]*dto.DeadJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, dto.NewDeadJobResponse(job))
+	}
+	utils.Success(ctx, resp)
+}
+
+// RetryDead 重新执行死信任务，尝试次数清零
+func (c *QueueController) RetryDead(ctx *gin.Context) {
+	if err := c.client.RetryDead(ctx.Request.Context(), ctx.Param("queue"), ctx.Param("id")); err != nil {
+		c.handleQueueError(ctx, "retry dead job failed", err)
+		return
+	}
+	utils.Success(ctx, "job requeued")
+}
+
+// DeleteDead 丢弃死信任务
+func (c *QueueController) DeleteDead(ctx *gin.Context) {
+	if err := c.client.DeleteDead(ctx.Request.Context(), ctx.Param("queue"), ctx.Param("id")); err != nil {
+		c.handleQueueError(ctx, "delete dead job failed", err)
+		return
+	}
+	utils.Success(ctx, "job deleted")
+}
+
+func (c *QueueController) handleQueueError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, queue.ErrUnknownQueue),
+		errors.Is(err, queue.ErrJobNotFound):
+		utils.Error(ctx, http.StatusNotFound, err.Error())
+	default:
+		c.logger.Error(msg, zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
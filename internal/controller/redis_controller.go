@@ -0,0 +1,27 @@
+// internal/controller/redis_controller.go
+package controller
+
+import (
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/utils"
+	redisx "gin-wire-demo/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedisController Redis 可用状态及各功能的降级统计，仅系统管理员可用
+type RedisController struct {
+	health *redisx.HealthWatcher
+}
+
+func NewRedisController(health *redisx.HealthWatcher) *RedisController {
+	return &RedisController{health: health}
+}
+
+// Health 当前是否可用，以及各功能处于降级状态的累计时间和次数
+func (c *RedisController) Health(ctx *gin.Context) {
+	utils.Success(ctx, &dto.RedisHealthResponse{
+		Healthy:  c.health.Healthy(),
+		Features: c.health.Stats(),
+	})
+}
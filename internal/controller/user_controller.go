@@ -5,7 +5,7 @@ import (
 	"errors"
 	"net/http"
 
-	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/dto"
 	"gin-wire-demo/internal/service"
 	"gin-wire-demo/internal/utils"
 	"gin-wire-demo/pkg/logger"
@@ -35,27 +35,28 @@ func NewUserController(
 }
 
 func (c *UserController) Register(ctx *gin.Context) {
-	var user model.User
-	if err := ctx.ShouldBindJSON(&user); err != nil {
+	var req dto.RegisterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
 		return
 	}
-	if err := c.userService.CreateUser(&user); err != nil {
+	user := req.ToModel()
+	if err := c.userService.CreateUser(ctx.Request.Context(), user); err != nil {
+		c.logger.Error("register failed", zap.String("username", req.Username), zap.Error(err))
 		utils.Error(ctx, http.StatusInternalServerError, ErrRegisterFail.Error())
 		return
 	}
 
-	utils.Success(ctx, "user created successfully")
+	utils.Success(ctx, dto.NewProfileResponse(user))
 }
 
 func (c *UserController) GetUser(ctx *gin.Context) {
 	username := ctx.Param("username")
 
-	user, err := c.userService.GetUserByUsername(username)
+	user, err := c.userService.GetUserByUsername(ctx.Request.Context(), username)
 	if err != nil {
 		utils.Error(ctx, http.StatusNotFound, "user not found")
 		return
 	}
-	user.Password = ""
-	utils.Success(ctx, user)
+	utils.Success(ctx, dto.NewUserResponse(user))
 }
@@ -0,0 +1,117 @@
+// internal/controller/user_import_controller.go
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserImportController struct {
+	importService service.UserImportService
+	logger        logger.Logger
+}
+
+func NewUserImportController(
+	importService service.UserImportService,
+	logger logger.Logger,
+) *UserImportController {
+	return &UserImportController{
+		importService: importService,
+		logger:        logger.With(zap.String("module", "user_import_controller")),
+	}
+}
+
+// Import 上传 CSV 或 NDJSON 文件（表单字段 file），返回后台任务
+func (c *UserImportController) Import(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	format := ctx.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	defer file.Close()
+
+	job, err := c.importService.StartImport(ctx.Request.Context(), ctx.GetUint("userID"), format, file)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedFormat) {
+			utils.Error(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrImportStopped) {
+			utils.Error(ctx, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		c.logger.Error("start import failed", zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+		return
+	}
+	utils.Custom(ctx, http.StatusAccepted, "import started", dto.NewImportJobResponse(job))
+}
+
+// GetJob 查询导入任务进度和错误报告
+func (c *UserImportController) GetJob(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	job, err := c.importService.GetJob(ctx.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(ctx, http.StatusNotFound, "import job not found")
+			return
+		}
+		c.logger.Error("get import job failed", zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+		return
+	}
+	utils.Success(ctx, dto.NewImportJobResponse(job))
+}
+
+// Export 流式导出用户，format 为 csv（默认）或 ndjson
+func (c *UserImportController) Export(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", service.FormatCSV)
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case service.FormatCSV:
+	case service.FormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		utils.Error(ctx, http.StatusBadRequest, service.ErrUnsupportedFormat.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102150405"), format)
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Status(http.StatusOK)
+
+	// 响应头已发出，出错时只能中断输出并记录日志
+	if err := c.importService.Export(ctx.Request.Context(), format, ctx.Writer); err != nil {
+		c.logger.Error("export users failed", zap.Error(err))
+		ctx.Abort()
+	}
+}
@@ -0,0 +1,205 @@
+// internal/controller/webhook_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/tenant"
+	"gin-wire-demo/internal/utils"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WebhookController 同一组接口同时挂在组织管理路由（管理本组织端点）
+// 和系统管理员路由（管理应用级端点）下，按请求上下文中的租户区分
+type WebhookController struct {
+	webhookService service.WebhookService
+	logger         logger.Logger
+}
+
+func NewWebhookController(
+	webhookService service.WebhookService,
+	logger logger.Logger,
+) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+		logger:         logger.With(zap.String("module", "webhook_controller")),
+	}
+}
+
+// Create 注册 webhook 端点，响应中包含签名密钥，之后不再返回
+func (c *WebhookController) Create(ctx *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	endpoint, err := c.webhookService.CreateEndpoint(ctx.Request.Context(), webhookTenant(ctx), req.URL, req.EventTypes, req.Description)
+	if err != nil {
+		c.handleServiceError(ctx, "create webhook failed", err)
+		return
+	}
+	utils.Success(ctx, &dto.WebhookCreatedResponse{
+		WebhookResponse: dto.NewWebhookResponse(endpoint),
+		Secret:          endpoint.Secret,
+	})
+}
+
+// List 列出端点
+func (c *WebhookController) List(ctx *gin.Context) {
+	endpoints, err := c.webhookService.ListEndpoints(ctx.Request.Context(), webhookTenant(ctx))
+	if err != nil {
+		c.handleServiceError(ctx, "list webhooks failed", err)
+		return
+	}
+	resp := make([]*dto.WebhookResponse, 0, len(endpoints))
+	for i := range endpoints {
+		resp = append(resp, dto.NewWebhookResponse(&endpoints[i]))
+	}
+	utils.Success(ctx, resp)
+}
+
+// Get 查看端点
+func (c *WebhookController) Get(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	endpoint, err := c.webhookService.GetEndpoint(ctx.Request.Context(), webhookTenant(ctx), id)
+	if err != nil {
+		c.handleServiceError(ctx, "get webhook failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewWebhookResponse(endpoint))
+}
+
+// Update 修改端点或重新启用
+func (c *WebhookController) Update(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	endpoint, err := c.webhookService.UpdateEndpoint(ctx.Request.Context(), webhookTenant(ctx), id, service.WebhookUpdate{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		c.handleServiceError(ctx, "update webhook failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewWebhookResponse(endpoint))
+}
+
+// Delete 删除端点，未完成的投递不再发送
+func (c *WebhookController) Delete(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.webhookService.DeleteEndpoint(ctx.Request.Context(), webhookTenant(ctx), id); err != nil {
+		c.handleServiceError(ctx, "delete webhook failed", err)
+		return
+	}
+	utils.Success(ctx, "webhook deleted")
+}
+
+// ListDeliveries 投递记录，按时间倒序，limit 默认 50
+func (c *WebhookController) ListDeliveries(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return
+	}
+	deliveries, err := c.webhookService.ListDeliveries(ctx.Request.Context(), webhookTenant(ctx), id, limit)
+	if err != nil {
+		c.handleServiceError(ctx, "list webhook deliveries failed", err)
+		return
+	}
+	resp := make([]*dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		resp = append(resp, dto.NewWebhookDeliveryResponse(&deliveries[i]))
+	}
+	utils.Success(ctx, resp)
+}
+
+// GetDelivery 投递详情及请求日志
+func (c *WebhookController) GetDelivery(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(ctx, "delivery_id")
+	if !ok {
+		return
+	}
+	delivery, attempts, err := c.webhookService.GetDelivery(ctx.Request.Context(), webhookTenant(ctx), id, deliveryID)
+	if err != nil {
+		c.handleServiceError(ctx, "get webhook delivery failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewWebhookDeliveryDetailResponse(delivery, attempts))
+}
+
+// Replay 重新投递
+func (c *WebhookController) Replay(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(ctx, "delivery_id")
+	if !ok {
+		return
+	}
+	delivery, err := c.webhookService.ReplayDelivery(ctx.Request.Context(), webhookTenant(ctx), id, deliveryID)
+	if err != nil {
+		c.handleServiceError(ctx, "replay webhook delivery failed", err)
+		return
+	}
+	utils.Success(ctx, dto.NewWebhookDeliveryResponse(delivery))
+}
+
+// webhookTenant 组织路由上为当前组织，系统管理员路由上没有租户，返回 0 即应用级
+func webhookTenant(ctx *gin.Context) uint {
+	tenantID, _ := tenant.FromContext(ctx.Request.Context())
+	return tenantID
+}
+
+func parseIDParam(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, ErrValidationfail.Error())
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (c *WebhookController) handleServiceError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookURL),
+		errors.Is(err, service.ErrUnknownEventType):
+		utils.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrDeliveryNotFound):
+		utils.Error(ctx, http.StatusNotFound, err.Error())
+	default:
+		c.logger.Error(msg, zap.Error(err))
+		utils.Error(ctx, http.StatusInternalServerError, ErrInternal.Error())
+	}
+}
@@ -0,0 +1,10 @@
+// internal/dto/auth.go
+package dto
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	// 登录时选择的组织，不传且只加入了一个组织时自动选择该组织
+	OrganizationID uint `json:"organization_id"`
+}
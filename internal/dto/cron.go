@@ -0,0 +1,18 @@
+// internal/dto/cron.go
+package dto
+
+import (
+	"time"
+
+	"gin-wire-demo/internal/model"
+)
+
+type CronJobResponse struct {
+	Name      string         `json:"name"`
+	Schedule  string         `json:"schedule"`
+	Overlap   string         `json:"overlap"`
+	Timeout   string         `json:"timeout"`
+	Paused    bool           `json:"paused"`
+	NextRunAt *time.Time     `json:"next_run_at,omitempty"`
+	LastRun   *model.CronRun `json:"last_run,omitempty"`
+}
@@ -0,0 +1,93 @@
+// internal/dto/organization.go
+package dto
+
+import (
+	"time"
+
+	"gin-wire-demo/internal/model"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Slug string `json:"slug" binding:"required,min=2,max=64,slug"`
+}
+
+type AddMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=admin member"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// OrganizationResponse 当前用户加入的组织及其角色
+type OrganizationResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Role string `json:"role"`
+}
+
+func NewOrganizationResponse(org *model.Organization, role string) *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:   org.ID,
+		Name: org.Name,
+		Slug: org.Slug,
+		Role: role,
+	}
+}
+
+// MemberResponse 组织成员
+type MemberResponse struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+func NewMemberResponse(m *model.Membership) *MemberResponse {
+	resp := &MemberResponse{
+		UserID:   m.UserID,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+	if m.User != nil {
+		resp.Username = m.User.Username
+		resp.DisplayName = m.User.DisplayName
+	}
+	return resp
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Role  string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// AcceptInvitationRequest 接受邀请；邮箱未注册时 username 必填并以 password 创建账户
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"omitempty,min=3,max=32,alphanum"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+type InvitationResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy uint      `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewInvitationResponse(i *model.Invitation) *InvitationResponse {
+	return &InvitationResponse{
+		ID:        i.ID,
+		Email:     i.Email,
+		Role:      i.Role,
+		InvitedBy: i.InvitedBy,
+		ExpiresAt: i.ExpiresAt,
+		CreatedAt: i.CreatedAt,
+	}
+}
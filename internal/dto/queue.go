@@ -0,0 +1,35 @@
+// internal/dto/queue.go
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin-wire-demo/pkg/queue"
+)
+
+type DeadJobResponse struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Queue       string          `json:"queue"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`
+}
+
+func NewDeadJobResponse(j *queue.Job) *DeadJobResponse {
+	return &DeadJobResponse{
+		ID:          j.ID,
+		Type:        j.Type,
+		Queue:       j.Queue,
+		Payload:     json.RawMessage(j.Payload),
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		LastError:   j.LastError,
+		CreatedAt:   j.CreatedAt,
+		FailedAt:    j.FailedAt,
+	}
+}
@@ -0,0 +1,9 @@
+// internal/dto/redis.go
+package dto
+
+import redisx "gin-wire-demo/pkg/redis"
+
+type RedisHealthResponse struct {
+	Healthy  bool                  `json:"healthy"`
+	Features []redisx.DegradeStats `json:"features"`
+}
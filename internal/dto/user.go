@@ -0,0 +1,122 @@
+// internal/dto/user.go
+package dto
+
+import (
+	"time"
+
+	"gin-wire-demo/internal/model"
+)
+
+// RegisterRequest 注册请求，只接收客户端允许设置的字段
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32,alphanum"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+}
+
+// ToModel 转换为用户模型，状态等服务端字段由 service 设置
+func (r *RegisterRequest) ToModel() *model.User {
+	return &model.User{
+		Username: r.Username,
+		Password: r.Password,
+		Email:    r.Email,
+	}
+}
+
+// UserResponse 对其他用户可见的公开资料
+type UserResponse struct {
+	ID          uint      `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewUserResponse(u *model.User) *UserResponse {
+	return &UserResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+// ProfileResponse 当前登录用户资料
+type ProfileResponse struct {
+	ID          uint      `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Status      string    `json:"status"`
+	DisplayName string    `json:"display_name"`
+	Locale      string    `json:"locale"`
+	Timezone    string    `json:"timezone"`
+	AvatarURL   string    `json:"avatar_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewProfileResponse(u *model.User) *ProfileResponse {
+	return &ProfileResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Status:      u.Status,
+		DisplayName: u.DisplayName,
+		Locale:      u.Locale,
+		Timezone:    u.Timezone,
+		AvatarURL:   u.AvatarURL,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+// UpdateProfileRequest 修改资料，未传的字段保持不变
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=64"`
+	Locale      *string `json:"locale" binding:"omitempty,bcp47_language_tag"`
+	Timezone    *string `json:"timezone" binding:"omitempty,timezone"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,url,max=1024"`
+}
+
+// Fields 转换为待更新的列
+func (r *UpdateProfileRequest) Fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if r.DisplayName != nil {
+		fields["display_name"] = *r.DisplayName
+	}
+	if r.Locale != nil {
+		fields["locale"] = *r.Locale
+	}
+	if r.Timezone != nil {
+		fields["timezone"] = *r.Timezone
+	}
+	if r.AvatarURL != nil {
+		fields["avatar_url"] = *r.AvatarURL
+	}
+	return fields
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=72,nefield=OldPassword"`
+}
+
+type ChangeEmailRequest struct {
+	Password string `json:"password" binding:"required"`
+	NewEmail string `json:"new_email" binding:"required,email,max=255"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type CloseAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// TokenResponse 重新签发的令牌
+type TokenResponse struct {
+	Token  string    `json:"token"`
+	Expire time.Time `json:"expire"`
+}
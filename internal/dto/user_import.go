@@ -0,0 +1,87 @@
+// internal/dto/user_import.go
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin-wire-demo/internal/model"
+)
+
+// ImportUserRow 导入文件中的一行，校验规则与注册一致
+type ImportUserRow struct {
+	RegisterRequest
+	DisplayName string `json:"display_name" binding:"omitempty,max=64"`
+	Locale      string `json:"locale" binding:"omitempty,bcp47_language_tag"`
+	Timezone    string `json:"timezone" binding:"omitempty,timezone"`
+}
+
+// ToModel 转换为用户模型，密码仍为明文，由 service 负责哈希
+func (r *ImportUserRow) ToModel() *model.User {
+	user := r.RegisterRequest.ToModel()
+	user.DisplayName = r.DisplayName
+	user.Locale = r.Locale
+	user.Timezone = r.Timezone
+	return user
+}
+
+// ExportUserRow 导出的用户字段，不含任何凭据
+type ExportUserRow struct {
+	ID          uint      `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Status      string    `json:"status"`
+	DisplayName string    `json:"display_name"`
+	Locale      string    `json:"locale"`
+	Timezone    string    `json:"timezone"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ExportUserColumns CSV 导出的列顺序
+var ExportUserColumns = []string{"id", "username", "email", "status", "display_name", "locale", "timezone", "created_at"}
+
+func NewExportUserRow(u *model.User) *ExportUserRow {
+	return &ExportUserRow{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Status:      u.Status,
+		DisplayName: u.DisplayName,
+		Locale:      u.Locale,
+		Timezone:    u.Timezone,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+// ImportJobResponse 导入任务进度与逐行错误报告
+type ImportJobResponse struct {
+	ID            uint                   `json:"id"`
+	Format        string                 `json:"format"`
+	Status        string                 `json:"status"`
+	TotalRows     int                    `json:"total_rows"`
+	SucceededRows int                    `json:"succeeded_rows"`
+	FailedRows    int                    `json:"failed_rows"`
+	Message       string                 `json:"message,omitempty"`
+	Errors        []model.ImportRowError `json:"errors"`
+	CreatedAt     time.Time              `json:"created_at"`
+	FinishedAt    *time.Time             `json:"finished_at"`
+}
+
+func NewImportJobResponse(job *model.ImportJob) *ImportJobResponse {
+	resp := &ImportJobResponse{
+		ID:            job.ID,
+		Format:        job.Format,
+		Status:        job.Status,
+		TotalRows:     job.TotalRows,
+		SucceededRows: job.SucceededRows,
+		FailedRows:    job.FailedRows,
+		Message:       job.Message,
+		Errors:        []model.ImportRowError{},
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+	if job.Errors != "" {
+		_ = json.Unmarshal([]byte(job.Errors), &resp.Errors)
+	}
+	return resp
+}
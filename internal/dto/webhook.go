@@ -0,0 +1,121 @@
+// internal/dto/webhook.go
+package dto
+
+import (
+	"time"
+
+	"gin-wire-demo/internal/model"
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,required,max=128"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest 修改端点，未传的字段保持不变；enabled 设为 true 可重新启用被自动停用的端点
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,min=1,dive,required,max=128"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+type WebhookResponse struct {
+	ID                  uint       `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Description         string     `json:"description"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func NewWebhookResponse(e *model.WebhookEndpoint) *WebhookResponse {
+	return &WebhookResponse{
+		ID:                  e.ID,
+		URL:                 e.URL,
+		EventTypes:          e.EventTypeList(),
+		Description:         e.Description,
+		Enabled:             e.Enabled,
+		ConsecutiveFailures: e.ConsecutiveFailures,
+		DisabledAt:          e.DisabledAt,
+		DisabledReason:      e.DisabledReason,
+		CreatedAt:           e.CreatedAt,
+		UpdatedAt:           e.UpdatedAt,
+	}
+}
+
+// WebhookCreatedResponse 创建结果，签名密钥只在此时返回
+type WebhookCreatedResponse struct {
+	*WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewWebhookDeliveryResponse(d *model.WebhookDelivery) *WebhookDeliveryResponse {
+	resp := &WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+type WebhookAttemptResponse struct {
+	StatusCode   int       `json:"status_code"`
+	Error        string    `json:"error,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	DurationMS   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryDetailResponse 投递详情，包含请求体和每次请求的日志
+type WebhookDeliveryDetailResponse struct {
+	*WebhookDeliveryResponse
+	Payload    string                    `json:"payload"`
+	AttemptLog []*WebhookAttemptResponse `json:"attempt_log"`
+}
+
+func NewWebhookDeliveryDetailResponse(d *model.WebhookDelivery, attempts []model.WebhookAttempt) *WebhookDeliveryDetailResponse {
+	resp := &WebhookDeliveryDetailResponse{
+		WebhookDeliveryResponse: NewWebhookDeliveryResponse(d),
+		Payload:                 d.Payload,
+		AttemptLog:              make([]*WebhookAttemptResponse, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		resp.AttemptLog = append(resp.AttemptLog, &WebhookAttemptResponse{
+			StatusCode:   a.StatusCode,
+			Error:        a.Error,
+			ResponseBody: a.ResponseBody,
+			DurationMS:   a.DurationMS,
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+	return resp
+}
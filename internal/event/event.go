@@ -0,0 +1,115 @@
+// internal/event/event.go
+package event
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gin-wire-demo/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// 领域事件类型
+const (
+	UserRegistered = "user.registered"
+	UserUpdated    = "user.updated"
+	UserLocked     = "user.locked"
+)
+
+// AggregateUser 用户事件的聚合类型，聚合 ID 为用户 ID
+const AggregateUser = "user"
+
+// UserEvents 对外开放订阅的用户生命周期事件
+var UserEvents = []string{UserRegistered, UserUpdated, UserLocked}
+
+// Event 服务层产生的领域事件
+type Event struct {
+	Type          string
+	AggregateType string
+	AggregateID   string
+	Payload       interface{}
+}
+
+// UserRegisteredPayload 用户注册事件。不含邮箱等敏感字段，
+// 事件会以明文写入 outbox 和消息代理，消费方需要时按 ID 查询
+type UserRegisteredPayload struct {
+	UserID       uint      `json:"user_id"`
+	Username     string    `json:"username"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// NewUserRegistered 构造用户注册事件
+func NewUserRegistered(user *model.User) Event {
+	return Event{
+		Type:          UserRegistered,
+		AggregateType: AggregateUser,
+		AggregateID:   strconv.FormatUint(uint64(user.ID), 10),
+		Payload: UserRegisteredPayload{
+			UserID:       user.ID,
+			Username:     user.Username,
+			RegisteredAt: user.CreatedAt,
+		},
+	}
+}
+
+// UserUpdatedPayload 用户资料变更事件，只列出变更的字段名，不含字段值
+type UserUpdatedPayload struct {
+	UserID        uint      `json:"user_id"`
+	Username      string    `json:"username"`
+	ChangedFields []string  `json:"changed_fields"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewUserUpdated 构造用户资料变更事件
+func NewUserUpdated(user *model.User, changedFields []string) Event {
+	return Event{
+		Type:          UserUpdated,
+		AggregateType: AggregateUser,
+		AggregateID:   strconv.FormatUint(uint64(user.ID), 10),
+		Payload: UserUpdatedPayload{
+			UserID:        user.ID,
+			Username:      user.Username,
+			ChangedFields: changedFields,
+			UpdatedAt:     user.UpdatedAt,
+		},
+	}
+}
+
+// UserLockedPayload 连续登录失败导致账户被临时锁定
+type UserLockedPayload struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// NewUserLocked 构造账户锁定事件
+func NewUserLocked(user *model.User, lockedUntil time.Time) Event {
+	return Event{
+		Type:          UserLocked,
+		AggregateType: AggregateUser,
+		AggregateID:   strconv.FormatUint(uint64(user.ID), 10),
+		Payload: UserLockedPayload{
+			UserID:      user.ID,
+			Username:    user.Username,
+			LockedUntil: lockedUntil,
+		},
+	}
+}
+
+// ToOutbox 编码为 outbox 记录，分配全局唯一的事件 ID
+func (e Event) ToOutbox(now time.Time) (*model.OutboxEvent, error) {
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OutboxEvent{
+		EventID:       uuid.NewString(),
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		Payload:       string(payload),
+		NextAttemptAt: now,
+	}, nil
+}
@@ -0,0 +1,29 @@
+// internal/middleware/admin.go
+package middleware
+
+import (
+	"net/http"
+
+	"gin-wire-demo/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminMiddleware struct{}
+
+func NewAdminMiddleware() *AdminMiddleware {
+	return &AdminMiddleware{}
+}
+
+// Handle 仅允许系统管理员访问，需挂在 JWT 中间件之后
+func (m *AdminMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("currentUser")
+		user, ok := value.(*model.User)
+		if !ok || user == nil || user.Role != model.SystemRoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin privileges required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -9,10 +9,10 @@ import (
 )
 
 type AuthMiddleware struct {
-	redisClient *redis.Client
+	redisClient redis.UniversalClient
 }
 
-func NewAuthMiddleware(redisClient *redis.Client) *AuthMiddleware {
+func NewAuthMiddleware(redisClient redis.UniversalClient) *AuthMiddleware {
 	return &AuthMiddleware{redisClient: redisClient}
 }
 
@@ -0,0 +1,31 @@
+// internal/middleware/deadline.go
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeadlineMiddleware struct {
+	timeout time.Duration
+}
+
+func NewDeadlineMiddleware(config *config.Config) *DeadlineMiddleware {
+	return &DeadlineMiddleware{timeout: config.App.RequestTimeout}
+}
+
+// Handle 为请求上下文设置截止时间。下游的数据库和 Redis 操作都使用该上下文，
+// 超时或客户端断开时会一并取消
+func (m *DeadlineMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), m.timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
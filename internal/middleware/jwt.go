@@ -2,12 +2,14 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/dto"
 	"gin-wire-demo/internal/model"
 	"gin-wire-demo/internal/service"
 	"gin-wire-demo/pkg/jwtauth"
@@ -21,19 +23,29 @@ import (
 )
 
 const (
-	identityKey = "id"
+	identityKey     = "id"
+	tokenVersionKey = "tv"
+	tenantIDKey     = "tid"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid username or password")
 	ErrUserDisabled       = errors.New("user account is disabled")
 	ErrAccountLocked      = errors.New("account locked due to too many failed attempts")
+	ErrNotOrgMember       = errors.New("user is not a member of the organization")
+	ErrLoginUnavailable   = errors.New("login is temporarily unavailable")
 )
 
+// TokenSubject 签发令牌的主体：用户及当前选择的组织（租户）
+type TokenSubject struct {
+	User     *model.User
+	TenantID uint
+}
+
 type JWT struct {
 	AuthMiddleware   *jwt.GinJWTMiddleware
 	Logger           logger.Logger
-	RedisClient      *redis.Client
+	RedisClient      redis.UniversalClient
 	Config           *config.Config
 	JwtBlacklist     *jwtauth.JwtBlacklist
 	JwtLoginLocked   *jwtauth.LoginLocked
@@ -42,9 +54,10 @@ type JWT struct {
 
 func NewJWT(
 	userService service.UserService,
+	orgService service.OrganizationService,
 	logger logger.Logger,
 	config *config.Config,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	blacklist *jwtauth.JwtBlacklist,
 	loginLock *jwtauth.LoginLocked,
 	cacheUserinfo *jwtauth.JwtCacheUserinfo,
@@ -60,21 +73,20 @@ func NewJWT(
 
 		// 登录回调函数
 		Authenticator: func(c *gin.Context) (interface{}, error) {
-			var login struct {
-				Username string `json:"username" binding:"required"`
-				Password string `json:"password" binding:"required"`
-			}
-
+			var login dto.LoginRequest
 			if err := c.ShouldBindJSON(&login); err != nil {
 				return nil, jwt.ErrMissingLoginValues
 			}
+			ctx := c.Request.Context()
 			// 1. 检查账户是否被锁定
-			if locked, err := loginLock.IsAccountLocked(login.Username); err != nil {
+			if locked, err := loginLock.IsAccountLocked(ctx, login.Username); err != nil {
+				// 只有降级策略为 fail_closed 时才会出错，此时拒绝登录
 				logger.Error(fmt.Sprintf("Account lock check error: %v", err))
+				return nil, ErrLoginUnavailable
 			} else if locked {
 				return nil, ErrAccountLocked
 			}
-			user, err := userService.GetUserByUsername(login.Username)
+			user, err := userService.GetUserByUsername(ctx, login.Username)
 			if err != nil {
 				logger.Warn(fmt.Sprintf("User not found: %s", login.Username))
 				return nil, ErrInvalidCredentials
@@ -92,20 +104,25 @@ func NewJWT(
 				[]byte(login.Password),
 			); err != nil {
 				// 2. 密码错误时增加失败计数
-				if err := loginLock.IncrementLoginFailure(login.Username); err != nil {
+				if err := loginLock.IncrementLoginFailure(ctx, login.Username); err != nil {
 					logger.Error(fmt.Sprintf("Failed to increment login counter: %v", err))
 				}
 				logger.Warn(fmt.Sprintf("Invalid password for user: %s", login.Username))
 				return nil, ErrInvalidCredentials
 			}
 			// 3. 登录成功重置失败计数
-			if err := loginLock.ClearLoginFailures(login.Username); err != nil {
+			if err := loginLock.ClearLoginFailures(ctx, login.Username); err != nil {
 				logger.Warn(fmt.Sprintf("Failed to clear login failures: %v", err))
 			}
+			// 4. 选择登录的组织
+			tenantID, err := resolveLoginTenant(c, orgService, user.ID, login.OrganizationID)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("Login organization rejected for user %s: %v", login.Username, err))
+				return nil, ErrNotOrgMember
+			}
 			// 登录成功后清除旧缓存
-			key := fmt.Sprintf(jwtauth.Cacheuserinfokey, config.App.Name, user.ID)
-			cacheUserinfo.ClearCacheUserinfo(key)
-			return user, nil
+			cacheUserinfo.ClearCacheUserinfo(ctx, user.ID)
+			return &TokenSubject{User: user, TenantID: tenantID}, nil
 		},
 
 		// 登录成功后返回数据
@@ -145,7 +162,7 @@ func NewJWT(
 			}
 
 			// 从缓存或数据库获取用户
-			user, fromCache, err := cacheUserinfo.GetUserWithCache(userID)
+			user, fromCache, err := cacheUserinfo.GetUserWithCache(c.Request.Context(), userID)
 			if err != nil {
 				logger.Error(fmt.Sprintf("User lookup error: %v", err))
 				return false
@@ -156,19 +173,24 @@ func NewJWT(
 				return false
 			}
 
+			// 令牌版本落后说明密码已修改或账户已注销，旧令牌作废
+			if claimTokenVersion(c) != user.TokenVersion {
+				logger.Info(fmt.Sprintf("Stale token version for user: %d", userID))
+				return false
+			}
+
 			// 3. 验证用户状态
 			if user.Status != "active" {
 				// 如果是缓存数据且状态不合法，清除缓存
 				if fromCache {
-					key := fmt.Sprintf(jwtauth.Cacheuserinfokey, config.App.Name, user.ID)
-					cacheUserinfo.ClearCacheUserinfo(key)
+					cacheUserinfo.ClearCacheUserinfo(c.Request.Context(), user.ID)
 				}
 
 				logger.Info(fmt.Sprintf("Inactive user access: %d", userID))
 				return false
 			}
 
-			// 将用户信息存入上下文，供后续使用
+			// 将用户信息存入上下文，供后续使用（只含鉴权所需字段，见 GetUserWithCache）
 			c.Set("currentUser", user)
 			c.Set("userID", user.ID) // 存储常用字段
 
@@ -195,9 +217,15 @@ func NewJWT(
 		// 在 JWT 中存储额外信息
 		PayloadFunc: func(data interface{}) jwt.MapClaims {
 			if user, ok := data.(*model.User); ok {
+				data = &TokenSubject{User: user}
+			}
+			if subject, ok := data.(*TokenSubject); ok {
+				user := subject.User
 				now := time.Now()
 				return jwt.MapClaims{
-					identityKey: user.ID,
+					identityKey:     user.ID,
+					tokenVersionKey: user.TokenVersion,
+					tenantIDKey:     subject.TenantID,
 					// 标准claims
 					"iss": config.App.Name,                    // 签发者
 					"sub": "authentication",                   // 主题
@@ -224,15 +252,53 @@ func NewJWT(
 	}
 
 	return &JWT{
-		AuthMiddleware: authMiddleware,
-		Logger:         logger,
-		RedisClient:    redisClient,
-		Config:         config,
-		JwtBlacklist:   blacklist,
-		JwtLoginLocked: loginLock,
+		AuthMiddleware:   authMiddleware,
+		Logger:           logger,
+		RedisClient:      redisClient,
+		Config:           config,
+		JwtBlacklist:     blacklist,
+		JwtLoginLocked:   loginLock,
+		JwtCacheUserinfo: cacheUserinfo,
 	}, nil
 }
 
+// claimTokenVersion 读取令牌中的版本号，旧令牌没有该字段时视为 0
+func claimTokenVersion(c *gin.Context) uint {
+	claims := jwt.ExtractClaims(c)
+	if tv, ok := claims[tokenVersionKey].(float64); ok {
+		return uint(tv)
+	}
+	return 0
+}
+
+// ClaimTenantID 读取令牌中当前选择的组织ID，未选择时为 0
+func ClaimTenantID(c *gin.Context) uint {
+	claims := jwt.ExtractClaims(c)
+	if tid, ok := claims[tenantIDKey].(float64); ok {
+		return uint(tid)
+	}
+	return 0
+}
+
+// resolveLoginTenant 校验登录时指定的组织；未指定时若用户只属于一个组织则默认选择它
+func resolveLoginTenant(c *gin.Context, orgService service.OrganizationService, userID, orgID uint) (uint, error) {
+	ctx := c.Request.Context()
+	if orgID != 0 {
+		if _, err := orgService.GetMembership(ctx, orgID, userID); err != nil {
+			return 0, err
+		}
+		return orgID, nil
+	}
+	memberships, err := orgService.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(memberships) == 1 {
+		return memberships[0].TenantID, nil
+	}
+	return 0, nil
+}
+
 func (j *JWT) MiddlewareFunc() gin.HandlerFunc {
 	return j.AuthMiddleware.MiddlewareFunc()
 }
@@ -272,3 +338,13 @@ func (j *JWT) LogoutHandler(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
 }
+
+// GenerateToken 为用户签发指定组织下的新令牌
+func (j *JWT) GenerateToken(user *model.User, tenantID uint) (string, time.Time, error) {
+	return j.AuthMiddleware.TokenGenerator(&TokenSubject{User: user, TenantID: tenantID})
+}
+
+// ClearUserCache 清除用户信息缓存
+func (j *JWT) ClearUserCache(ctx context.Context, userID uint) {
+	j.JwtCacheUserinfo.ClearCacheUserinfo(ctx, userID)
+}
@@ -2,12 +2,18 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gin-wire-demo/internal/config"
 	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/ratelimit"
+	redisx "gin-wire-demo/pkg/redis"
 	"math"
-	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,92 +21,281 @@ import (
 	"go.uber.org/zap"
 )
 
+// 单次限流判断访问 Redis 的时限
+const rateLimitTimeout = 500 * time.Millisecond
+
+// 策略未指定计数方式时按客户端 IP
+const defaultRateLimitKey = "ip"
+
 // RateLimiter 限流器
 type RateLimiterMiddleware struct {
-	RedisClient *redis.Client
+	RedisClient redis.UniversalClient
 	KeyPrefix   string // Redis key前缀
 	Logger      logger.Logger
+	Degrader    *redisx.Degrader
+	limiter     *ratelimit.Limiter
+	local       *localLimiter // Redis 不可用且降级策略为 local 时使用
+	table       atomic.Pointer[rateLimitTable]
+}
+
+// rateLimitPolicy 校验后的策略
+type rateLimitPolicy struct {
+	name string
+	rule ratelimit.Rule
+	key  rateLimitKeyFunc
+}
+
+// rateLimitBinding 路由与策略的绑定
+type rateLimitBinding struct {
+	method string // 为空时匹配任意方法
+	path   string
+	prefix bool // path 以 /* 结尾，匹配该前缀下的全部路由
+	policy *rateLimitPolicy
+}
+
+// rateLimitTable 某一版配置的全部绑定，热更新时整体替换
+type rateLimitTable struct {
+	bindings []rateLimitBinding
 }
 
 func NewRateLimiterMiddleware(
-	redisClient *redis.Client,
-	config *config.Config,
+	redisClient redis.UniversalClient,
+	health *redisx.HealthWatcher,
+	cfg *config.Config,
 	logger logger.Logger,
-) *RateLimiterMiddleware {
-	keyPrefix := "cache:" + config.App.Name + ":mid:rl"
-	return &RateLimiterMiddleware{
+) (*RateLimiterMiddleware, error) {
+	keyPrefix := "cache:" + cfg.App.Name + ":mid:rl"
+	rl := &RateLimiterMiddleware{
 		RedisClient: redisClient,
 		KeyPrefix:   keyPrefix,
-		Logger:      logger,
+		Logger:      logger.With(zap.String("module", "rate_limiter")),
+		Degrader:    health.Degrader(redisx.FeatureRateLimit),
+		limiter:     ratelimit.NewLimiter(redisClient),
+		local:       newLocalLimiter(),
+	}
+	if err := rl.Reload(cfg.RateLimits); err != nil {
+		return nil, err
 	}
+	// 预加载脚本，失败时首次执行会自动回退为 EVAL
+	loadCtx, cancel := context.WithTimeout(context.Background(), rateLimitTimeout)
+	defer cancel()
+	if err := rl.limiter.Load(loadCtx); err != nil {
+		rl.Logger.Warn("Failed to preload rate limit scripts", zap.Error(err))
+	}
+	config.OnChange(func(cfg *config.Config) {
+		if err := rl.Reload(cfg.RateLimits); err != nil {
+			rl.Logger.Error("Rate limit reload failed, keeping previous rules", zap.Error(err))
+			return
+		}
+		rl.Logger.Info("Rate limit rules reloaded", zap.Int("routes", len(cfg.RateLimits.Routes)))
+	})
+	return rl, nil
 }
 
-// RateLimiter 限流中间件
-func (rl *RateLimiterMiddleware) Handle(limit int64, window time.Duration) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		key := fmt.Sprintf("%s:%s", rl.KeyPrefix, c.ClientIP())
-		now := time.Now().UnixMilli() // 毫秒时间戳
-		windowStart := now - window.Milliseconds()
-		ttlSeconds := int64(math.Ceil(window.Seconds()))
-
-		// 原子化执行Lua脚本
-		script := `
-        local key = KEYS[1]
-        local now = tonumber(ARGV[1])
-        local window_start = tonumber(ARGV[2])
-        local limit = tonumber(ARGV[3])
-        local window_ttl = tonumber(ARGV[4])
-        local member = ARGV[5]
-        
-        -- 移除旧时间戳
-        redis.call('ZREMRANGEBYSCORE', key, 0, window_start)
-        
-        -- 获取当前请求数
-        local count = redis.call('ZCARD', key)
-        
-        if count >= limit then
-            return 0
-        end
-        
-        -- 记录当前请求
-        redis.call('ZADD', key, now, member)
-        redis.call('EXPIRE', key, window_ttl)
-        return 1
-        `
-
-		// 生成唯一成员ID (时间戳+随机数)
-		member := fmt.Sprintf("%d:%d", now, rand.Intn(10000))
-
-		// 创建带超时的独立上下文
-		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-		defer cancel()
-
-		// 使用请求的上下文
-		res, err := rl.RedisClient.Eval(ctx, script, []string{key},
-			now, windowStart, limit, ttlSeconds, member).Result()
-
+// Reload 校验并替换限流规则，出错时保留原规则
+func (rl *RateLimiterMiddleware) Reload(cfg config.RateLimitConfig) error {
+	proxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	apiKeys, err := parseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return err
+	}
+	policies := make(map[string]*rateLimitPolicy, len(cfg.Policies))
+	for name, p := range cfg.Policies {
+		algorithm := p.Algorithm
+		if algorithm == "" {
+			algorithm = ratelimit.SlidingWindow
+		}
+		if !ratelimit.Supported(algorithm) {
+			return fmt.Errorf("rate limit policy %q: unsupported algorithm %q", name, algorithm)
+		}
+		keyName := p.Key
+		if keyName == "" {
+			keyName = defaultRateLimitKey
+		}
+		key, err := parseRateLimitKey(keyName, proxies, apiKeys)
 		if err != nil {
-			// 修复：添加详细的错误日志记录
-			rl.Logger.Error("Redis rate limiter error",
-				zap.String("key", key),
-				zap.Int64("limit", limit),
-				zap.Duration("window", window),
-				zap.Error(err))
+			return fmt.Errorf("rate limit policy %q: %w", name, err)
+		}
+		policies[name] = &rateLimitPolicy{
+			name: name,
+			rule: ratelimit.Rule{Algorithm: algorithm, Limit: p.Limit, Window: p.Window, Burst: p.Burst},
+			key:  key,
+		}
+	}
+
+	table := &rateLimitTable{}
+	for _, r := range cfg.Routes {
+		policy, ok := policies[r.Policy]
+		if !ok {
+			return fmt.Errorf("rate limit route %q uses unknown policy %q", r.Route, r.Policy)
+		}
+		b := rateLimitBinding{policy: policy}
+		route := strings.TrimSpace(r.Route)
+		if method, path, ok := strings.Cut(route, " "); ok {
+			b.method = strings.ToUpper(method)
+			route = strings.TrimSpace(path)
+		}
+		if strings.HasSuffix(route, "/*") {
+			b.prefix = true
+			route = strings.TrimSuffix(route, "*")
+		}
+		b.path = route
+		table.bindings = append(table.bindings, b)
+	}
+	rl.table.Store(table)
+	return nil
+}
+
+// match 返回路由匹配的全部策略
+func (t *rateLimitTable) match(method, path string) []*rateLimitPolicy {
+	var matched []*rateLimitPolicy
+	for _, b := range t.bindings {
+		if b.method != "" && b.method != method {
+			continue
+		}
+		if b.prefix && strings.HasPrefix(path, b.path) || !b.prefix && path == b.path {
+			matched = append(matched, b.policy)
+		}
+	}
+	return matched
+}
 
-			// 修复：继续处理请求而不是拒绝（fail-open策略）
-			// 在实际生产环境中，这取决于您的安全要求
+// Handle 按配置的路由绑定限流，未绑定策略的路由直接放行。
+// 匹配多条策略时按配置顺序逐条判断并计入用量，被后面的策略拒绝的请求已计入前面策略的用量。
+// 需注册在路由组上，身份类的计数方式要放在认证中间件之后
+func (rl *RateLimiterMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
 			c.Next()
 			return
 		}
+		for _, policy := range rl.table.Load().match(c.Request.Method, path) {
+			if !rl.allow(c, policy) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
 
-		// 处理Lua脚本返回结果
-		if result, ok := res.(int64); !ok || result == 0 {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"error": "Too many requests",
+// allow 判断单个策略，拒绝时写入响应并中止请求
+func (rl *RateLimiterMiddleware) allow(c *gin.Context, policy *rateLimitPolicy) bool {
+	key := fmt.Sprintf("%s:%s:%s", rl.KeyPrefix, policy.name, policy.key(c))
+
+	// 基于请求上下文设置超时，客户端断开时同步取消
+	ctx, cancel := context.WithTimeout(c.Request.Context(), rateLimitTimeout)
+	defer cancel()
+
+	var res ratelimit.Result
+	var err error
+	if !rl.Degrader.Call(func() error {
+		res, err = rl.limiter.Allow(ctx, key, policy.rule)
+		return err
+	}) {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			rl.Logger.Error("Redis rate limiter error",
+				zap.String("key", key),
+				zap.String("policy", policy.name),
+				zap.Error(err))
+		}
+		switch rl.Degrader.Policy() {
+		case config.DegradeFailClosed:
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Service temporarily unavailable",
 			})
-			return
+			return false
+		case config.DegradeLocal:
+			// 按实例计数，多实例时总体限额相应放大
+			res = rl.local.allow(key, policy.rule)
+		default:
+			return true
 		}
+	}
 
-		c.Next()
+	c.Header("X-RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many requests",
+		})
+		return false
+	}
+	return true
+}
+
+// 进程内计数的键数量超过该值时清理已过期的状态
+const localLimiterSweepSize = 10000
+
+// localLimiter 进程内计数。滑动窗口以固定窗口近似；令牌桶和 GCRA 按相同的速率和突发量以令牌桶计算
+type localLimiter struct {
+	mu      sync.Mutex
+	entries map[string]*localEntry
+}
+
+type localEntry struct {
+	start   time.Time // 固定窗口的起点，令牌桶为上次补充令牌的时间
+	count   int64     // 固定窗口内已放行的请求数
+	tokens  float64   // 令牌桶剩余的令牌
+	expires time.Time // 此后状态等同于不存在，可以清理
+}
+
+func newLocalLimiter() *localLimiter {
+	return &localLimiter{entries: make(map[string]*localEntry)}
+}
+
+func (l *localLimiter) allow(key string, rule ratelimit.Rule) ratelimit.Result {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok || !now.Before(e.expires) {
+		if !ok && len(l.entries) >= localLimiterSweepSize {
+			l.sweep(now)
+		}
+		e = &localEntry{start: now, tokens: float64(rule.Capacity())}
+		l.entries[key] = e
+	}
+	if rule.Algorithm == ratelimit.SlidingWindow {
+		return e.fixedWindow(now, rule)
+	}
+	return e.tokenBucket(now, rule)
+}
+
+func (e *localEntry) fixedWindow(now time.Time, rule ratelimit.Rule) ratelimit.Result {
+	e.expires = e.start.Add(rule.Window)
+	if e.count >= rule.Limit {
+		return ratelimit.Result{Limit: rule.Limit, RetryAfter: e.expires.Sub(now)}
+	}
+	e.count++
+	return ratelimit.Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit - e.count}
+}
+
+func (e *localEntry) tokenBucket(now time.Time, rule ratelimit.Rule) ratelimit.Result {
+	capacity := float64(rule.Capacity())
+	rate := float64(rule.Limit) / float64(rule.Window) // 每纳秒补充的令牌
+	e.tokens = min(capacity, e.tokens+float64(now.Sub(e.start))*rate)
+	e.start = now
+	res := ratelimit.Result{Limit: rule.Capacity()}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - e.tokens) / rate))
+	}
+	res.Remaining = int64(e.tokens)
+	e.expires = now.Add(time.Duration((capacity - e.tokens) / rate))
+	return res
+}
+
+func (l *localLimiter) sweep(now time.Time) {
+	for key, e := range l.entries {
+		if !now.Before(e.expires) {
+			delete(l.entries, key)
+		}
 	}
 }
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader 携带 API key 的请求头
+const APIKeyHeader = "X-API-Key"
+
+// rateLimitKeyFunc 返回请求的计数依据
+type rateLimitKeyFunc func(c *gin.Context) string
+
+// rateLimitKeyPart 组合键的一部分，返回 false 表示请求缺少该身份
+type rateLimitKeyPart func(c *gin.Context) (string, bool)
+
+// parseRateLimitKey 解析策略的 key：
+//
+//	ip            客户端 IP，仅信任 trusted_proxies 中代理添加的 X-Forwarded-For
+//	user          JWT 中的用户 ID，需放在认证中间件之后
+//	api_key       X-API-Key 请求头对应的 api_keys 中的 ID，未配置的 key 视为缺失
+//	header:<名称>  指定请求头的值，按摘要计数，只采信可信代理转发的请求
+//
+// 多个 key 用 + 连接为组合键，如 user+ip。任一部分缺失时以客户端 IP 代替，
+// 缺少身份的请求同样受限，伪造请求头也无法换到新的计数
+func parseRateLimitKey(spec string, proxies trustedProxies, apiKeys apiKeyIndex) (rateLimitKeyFunc, error) {
+	specs := strings.Split(spec, "+")
+	parts := make([]rateLimitKeyPart, 0, len(specs))
+	for _, s := range specs {
+		part, err := parseRateLimitKeyPart(strings.TrimSpace(s), proxies, apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return func(c *gin.Context) string {
+		values := make([]string, 0, len(parts)+1)
+		missing := false
+		for _, part := range parts {
+			v, ok := part(c)
+			if !ok {
+				missing = true
+				continue
+			}
+			values = append(values, v)
+		}
+		if missing {
+			if ip := proxies.key(c); !slices.Contains(values, ip) {
+				values = append(values, ip)
+			}
+		}
+		return strings.Join(values, "|")
+	}, nil
+}
+
+func parseRateLimitKeyPart(spec string, proxies trustedProxies, apiKeys apiKeyIndex) (rateLimitKeyPart, error) {
+	switch {
+	case spec == "ip":
+		return func(c *gin.Context) (string, bool) {
+			return proxies.key(c), true
+		}, nil
+	case spec == "user":
+		return func(c *gin.Context) (string, bool) {
+			id, ok := c.Get("userID")
+			if !ok {
+				return "", false
+			}
+			userID, ok := id.(uint)
+			if !ok {
+				return "", false
+			}
+			return "user:" + strconv.FormatUint(uint64(userID), 10), true
+		}, nil
+	case spec == "api_key":
+		return func(c *gin.Context) (string, bool) {
+			id, ok := apiKeys.lookup(c.GetHeader(APIKeyHeader))
+			if !ok {
+				return "", false
+			}
+			return "ak:" + id, true
+		}, nil
+	case strings.HasPrefix(spec, "header:"):
+		name := strings.TrimSpace(strings.TrimPrefix(spec, "header:"))
+		if name == "" {
+			return nil, fmt.Errorf("rate limit key %q: header name cannot be empty", spec)
+		}
+		return headerKey("h:"+strings.ToLower(name)+":", name, proxies), nil
+	}
+	return nil, fmt.Errorf("unsupported rate limit key %q", spec)
+}
+
+// headerKey 以请求头的摘要计数，避免 Redis 键中出现超长的值。
+// 客户端可以随意设置请求头，只有可信代理转发的请求才采信
+func headerKey(prefix, header string, proxies trustedProxies) rateLimitKeyPart {
+	return func(c *gin.Context) (string, bool) {
+		value := c.GetHeader(header)
+		if value == "" || !proxies.forwarded(c) {
+			return "", false
+		}
+		sum := sha256.Sum256([]byte(value))
+		return prefix + hex.EncodeToString(sum[:16]), true
+	}
+}
+
+// apiKeyIndex API key 的 SHA-256 摘要（十六进制）到 ID 的映射
+type apiKeyIndex map[string]string
+
+// parseAPIKeys 校验配置中 ID 到摘要的映射并按摘要建立索引
+func parseAPIKeys(keys map[string]string) (apiKeyIndex, error) {
+	index := make(apiKeyIndex, len(keys))
+	for id, digest := range keys {
+		digest = strings.ToLower(strings.TrimSpace(digest))
+		if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("rate limit api key %q: value must be a hex SHA-256 digest", id)
+		}
+		if other, ok := index[digest]; ok {
+			return nil, fmt.Errorf("rate limit api keys %q and %q have the same digest", other, id)
+		}
+		index[digest] = id
+	}
+	return index, nil
+}
+
+// lookup 返回 key 对应的 ID，未配置的 key 返回 false
+func (idx apiKeyIndex) lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(key))
+	id, ok := idx[hex.EncodeToString(sum[:])]
+	return id, ok
+}
+
+// trustedProxies 可信代理的网段
+type trustedProxies []*net.IPNet
+
+func parseTrustedProxies(cidrs []string) (trustedProxies, error) {
+	proxies := make(trustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			// 单个地址
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// key 客户端 IP 的计数依据，也是其他身份缺失时的替代
+func (p trustedProxies) key(c *gin.Context) string {
+	return "ip:" + p.clientIP(c)
+}
+
+// forwarded 请求是否由可信代理转发
+func (p trustedProxies) forwarded(c *gin.Context) bool {
+	ip := net.ParseIP(remoteHost(c))
+	return ip != nil && p.contains(ip)
+}
+
+func (p trustedProxies) contains(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 直连地址不是可信代理时即为客户端；否则从 X-Forwarded-For 右侧起跳过可信代理，
+// 第一个不可信的地址为客户端。不使用 gin 的 ClientIP，其默认信任任何来源的转发头
+func (p trustedProxies) clientIP(c *gin.Context) string {
+	remote := remoteHost(c)
+	client := net.ParseIP(remote)
+	if client == nil {
+		return remote
+	}
+	if !p.contains(client) {
+		return client.String()
+	}
+	hops := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		client = hop
+		if !p.contains(hop) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// remoteHost 直连地址，不含端口
+func remoteHost(c *gin.Context) string {
+	remote, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return remote
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newKeyTestContext(remoteAddr string, headers map[string]string, userID interface{}) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/test", nil)
+	c.Request.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	if userID != nil {
+		c.Set("userID", userID)
+	}
+	return c
+}
+
+func mustParseKey(t *testing.T, spec string, apiKeys map[string]string) rateLimitKeyFunc {
+	t.Helper()
+	proxies, err := parseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := parseAPIKeys(apiKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := parseRateLimitKey(spec, proxies, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func digest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRateLimitKeyFallsBackToClientIP(t *testing.T) {
+	apiKeys := map[string]string{"partner-a": digest("secret-a")}
+	tests := []struct {
+		name    string
+		spec    string
+		remote  string
+		headers map[string]string
+		userID  interface{}
+		want    string
+	}{
+		{name: "ip", spec: "ip", remote: "1.2.3.4:5000", want: "ip:1.2.3.4"},
+		{name: "ip via trusted proxy", spec: "ip", remote: "10.0.0.1:5000",
+			headers: map[string]string{"X-Forwarded-For": "9.9.9.9, 1.2.3.4"}, want: "ip:1.2.3.4"},
+		{name: "forwarded header from client ignored", spec: "ip", remote: "1.2.3.4:5000",
+			headers: map[string]string{"X-Forwarded-For": "9.9.9.9"}, want: "ip:1.2.3.4"},
+		{name: "user", spec: "user", remote: "1.2.3.4:5000", userID: uint(7), want: "user:7"},
+		{name: "anonymous user", spec: "user", remote: "1.2.3.4:5000", want: "ip:1.2.3.4"},
+		{name: "configured api key", spec: "api_key", remote: "1.2.3.4:5000",
+			headers: map[string]string{APIKeyHeader: "secret-a"}, want: "ak:partner-a"},
+		{name: "unknown api key", spec: "api_key", remote: "1.2.3.4:5000",
+			headers: map[string]string{APIKeyHeader: "forged"}, want: "ip:1.2.3.4"},
+		{name: "missing api key", spec: "api_key", remote: "1.2.3.4:5000", want: "ip:1.2.3.4"},
+		{name: "header via trusted proxy", spec: "header:X-Device-ID", remote: "10.0.0.1:5000",
+			headers: map[string]string{"X-Device-ID": "d1", "X-Forwarded-For": "1.2.3.4"},
+			want:    "h:x-device-id:" + digest("d1")[:32]},
+		{name: "header from client ignored", spec: "header:X-Device-ID", remote: "1.2.3.4:5000",
+			headers: map[string]string{"X-Device-ID": "d1"}, want: "ip:1.2.3.4"},
+		{name: "missing header", spec: "header:X-Device-ID", remote: "10.0.0.1:5000",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"}, want: "ip:1.2.3.4"},
+		{name: "composite", spec: "user+ip", remote: "1.2.3.4:5000", userID: uint(7), want: "user:7|ip:1.2.3.4"},
+		{name: "composite anonymous", spec: "user+ip", remote: "1.2.3.4:5000", want: "ip:1.2.3.4"},
+		{name: "composite partly missing", spec: "user+api_key", remote: "1.2.3.4:5000",
+			headers: map[string]string{APIKeyHeader: "secret-a"}, want: "ak:partner-a|ip:1.2.3.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := mustParseKey(t, tt.spec, apiKeys)
+			got := fn(newKeyTestContext(tt.remote, tt.headers, tt.userID))
+			if got != tt.want {
+				t.Fatalf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeysRejectsInvalidDigests(t *testing.T) {
+	for name, keys := range map[string]map[string]string{
+		"plain key": {"a": "secret"},
+		"short":     {"a": digest("x")[:32]},
+		"duplicate": {"a": digest("x"), "b": digest("x")},
+	} {
+		if _, err := parseAPIKeys(keys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/ratelimit"
+)
+
+func TestLocalLimiterHonorsBurst(t *testing.T) {
+	for _, algorithm := range []string{ratelimit.TokenBucket, ratelimit.GCRA} {
+		t.Run(algorithm, func(t *testing.T) {
+			l := newLocalLimiter()
+			// 平均每分钟 1 次，允许连续 3 次
+			rule := ratelimit.Rule{Algorithm: algorithm, Limit: 1, Window: time.Minute, Burst: 3}
+			for i := int64(1); i <= 3; i++ {
+				res := l.allow("k", rule)
+				if !res.Allowed || res.Limit != 3 || res.Remaining != 3-i {
+					t.Fatalf("request %d: %+v", i, res)
+				}
+			}
+			res := l.allow("k", rule)
+			if res.Allowed {
+				t.Fatal("burst exceeded")
+			}
+			if res.RetryAfter < 59*time.Second || res.RetryAfter > time.Minute {
+				t.Fatalf("retry after %v, want about 1m", res.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestLocalLimiterFixedWindow(t *testing.T) {
+	l := newLocalLimiter()
+	rule := ratelimit.Rule{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: 50 * time.Millisecond}
+	for i := 0; i < 2; i++ {
+		if res := l.allow("k", rule); !res.Allowed {
+			t.Fatalf("request %d rejected", i+1)
+		}
+	}
+	res := l.allow("k", rule)
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 50*time.Millisecond {
+		t.Fatalf("over limit: %+v", res)
+	}
+	time.Sleep(res.RetryAfter)
+	if res := l.allow("k", rule); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("next window: %+v", res)
+	}
+}
+
+func TestLocalLimiterRefills(t *testing.T) {
+	l := newLocalLimiter()
+	rule := ratelimit.Rule{Algorithm: ratelimit.TokenBucket, Limit: 20, Window: time.Second, Burst: 1}
+	if res := l.allow("k", rule); !res.Allowed {
+		t.Fatal("first request rejected")
+	}
+	res := l.allow("k", rule)
+	if res.Allowed {
+		t.Fatal("second request allowed without waiting")
+	}
+	time.Sleep(res.RetryAfter)
+	if res := l.allow("k", rule); !res.Allowed {
+		t.Fatalf("rejected after waiting retry after: %+v", res)
+	}
+}
+
+func testRateLimitConfig() config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Policies: map[string]config.RateLimitPolicy{
+			"public": {Limit: 2, Window: 5 * time.Second},
+			"user":   {Limit: 120, Window: time.Minute, Algorithm: ratelimit.TokenBucket, Key: "user"},
+			"admin":  {Limit: 10, Window: time.Minute},
+		},
+		Routes: []config.RateLimitRoute{
+			{Route: "POST /api/login", Policy: "public"},
+			{Route: "/api/*", Policy: "user"},
+			{Route: "delete /api/admin/users/:id", Policy: "admin"},
+		},
+		TrustedProxies: []string{"10.0.0.0/8"},
+	}
+}
+
+func policyNames(policies []*rateLimitPolicy) []string {
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		names[i] = p.name
+	}
+	return names
+}
+
+func TestRateLimitTableMatch(t *testing.T) {
+	rl := &RateLimiterMiddleware{}
+	if err := rl.Reload(testRateLimitConfig()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method, path string
+		want         []string
+	}{
+		// 按配置顺序返回全部匹配的策略
+		{"POST", "/api/login", []string{"public", "user"}},
+		// 方法不符时只匹配不限方法的绑定
+		{"GET", "/api/login", []string{"user"}},
+		// 方法不区分大小写，路径按路由模板精确匹配
+		{"DELETE", "/api/admin/users/:id", []string{"user", "admin"}},
+		{"DELETE", "/api/admin/users/:id/roles", []string{"user"}},
+		// /* 匹配前缀下的路由，不匹配前缀本身以外的路径
+		{"GET", "/api/", []string{"user"}},
+		{"GET", "/api", nil},
+		{"GET", "/apix/users", nil},
+		{"GET", "/health", nil},
+	}
+	table := rl.table.Load()
+	for _, tt := range tests {
+		got := policyNames(table.match(tt.method, tt.path))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("match(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitReloadKeepsPreviousTableOnError(t *testing.T) {
+	rl := &RateLimiterMiddleware{}
+	if err := rl.Reload(testRateLimitConfig()); err != nil {
+		t.Fatal(err)
+	}
+	previous := rl.table.Load()
+
+	invalid := map[string]func(*config.RateLimitConfig){
+		"unknown algorithm": func(c *config.RateLimitConfig) {
+			c.Policies["public"] = config.RateLimitPolicy{Limit: 1, Window: time.Second, Algorithm: "leaky"}
+		},
+		"unknown key": func(c *config.RateLimitConfig) {
+			c.Policies["public"] = config.RateLimitPolicy{Limit: 1, Window: time.Second, Key: "session"}
+		},
+		"unknown policy": func(c *config.RateLimitConfig) {
+			c.Routes = append(c.Routes, config.RateLimitRoute{Route: "/api/x", Policy: "missing"})
+		},
+		"bad proxy": func(c *config.RateLimitConfig) {
+			c.TrustedProxies = []string{"not-an-ip"}
+		},
+		"bad api key": func(c *config.RateLimitConfig) {
+			c.APIKeys = map[string]string{"partner": "plaintext"}
+		},
+	}
+	for name, mutate := range invalid {
+		cfg := testRateLimitConfig()
+		mutate(&cfg)
+		if err := rl.Reload(cfg); err == nil {
+			t.Errorf("%s: reload succeeded, want error", name)
+		}
+		if rl.table.Load() != previous {
+			t.Fatalf("%s: table replaced by invalid config", name)
+		}
+	}
+
+	// 合法的配置整体替换
+	cfg := testRateLimitConfig()
+	cfg.Routes = cfg.Routes[:1]
+	if err := rl.Reload(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := policyNames(rl.table.Load().match("GET", "/api/users")); len(got) != 0 {
+		t.Fatalf("after reload, /api/users matched %v", got)
+	}
+}
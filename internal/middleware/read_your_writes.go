@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// primaryUntilCookie 记录客户端最近一次写操作后需读主库到何时（Unix 毫秒）
+const primaryUntilCookie = "rw_primary_until"
+
+// 读写用户标记访问 Redis 的时限，超时按未写过处理
+const readYourWritesTimeout = 100 * time.Millisecond
+
+// ReadYourWritesMiddleware 写操作后的一段时间内，同一客户端（cookie）或同一用户（Redis 标记）
+// 的后续请求也读主库。时长为从库允许的最大延迟加一次健康检查间隔，超过后从库要么已同步，要么已被移出轮询
+type ReadYourWritesMiddleware struct {
+	enabled     bool
+	sticky      bool // 配置了从库时才需要跨请求保持
+	window      time.Duration
+	keyPrefix   string
+	redisClient redis.UniversalClient
+	logger      logger.Logger
+}
+
+func NewReadYourWritesMiddleware(config *config.Config, redisClient redis.UniversalClient, logger logger.Logger) *ReadYourWritesMiddleware {
+	return &ReadYourWritesMiddleware{
+		enabled:     config.Database.ReadYourWrites,
+		sticky:      config.Database.ReadYourWrites && len(config.Database.Replicas) > 0,
+		window:      config.Database.ReplicaMaxLag + config.Database.ReplicaCheckInterval,
+		keyPrefix:   "cache:" + config.App.Name + ":rw:user:",
+		redisClient: redisClient,
+		logger:      logger.With(zap.String("module", "read_your_writes")),
+	}
+}
+
+// Handle 请求内发生写操作后，后续读操作改走主库，避免从库延迟读到旧数据；
+// 请求带有未过期的 cookie 时整个请求读主库，写过数据的响应写入 cookie 和用户标记
+func (m *ReadYourWritesMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.enabled {
+			c.Next()
+			return
+		}
+		ctx := db.WithReadYourWrites(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		if m.sticky {
+			if v, err := c.Cookie(primaryUntilCookie); err == nil {
+				if until, err := strconv.ParseInt(v, 10, 64); err == nil && time.Now().UnixMilli() < until {
+					db.StickToPrimary(ctx)
+				}
+			}
+			w := &readYourWritesWriter{ResponseWriter: c.Writer, c: c, m: m}
+			c.Writer = w
+			c.Next()
+			// 没有响应体（如 204）时响应头在这之后才发出
+			w.beforeWrite()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HandleUser 用户在其他客户端刚写过数据时读主库，需放在认证中间件之后
+func (m *ReadYourWritesMiddleware) HandleUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("userID")
+		if !m.sticky || !ok {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readYourWritesTimeout)
+		n, err := m.redisClient.Exists(ctx, m.userKey(userID)).Result()
+		cancel()
+		if err != nil {
+			m.logger.Warn("Read-your-writes marker lookup failed", zap.Error(err))
+		} else if n > 0 {
+			db.StickToPrimary(c.Request.Context())
+		}
+		c.Next()
+	}
+}
+
+func (m *ReadYourWritesMiddleware) userKey(userID interface{}) string {
+	id, _ := userID.(uint)
+	return m.keyPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+// markWritten 在响应头发出前调用，请求写过数据时记录 cookie 和用户标记
+func (m *ReadYourWritesMiddleware) markWritten(c *gin.Context) {
+	ctx := c.Request.Context()
+	if !db.HasWritten(ctx) {
+		return
+	}
+	until := time.Now().Add(m.window)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     primaryUntilCookie,
+		Value:    strconv.FormatInt(until.UnixMilli(), 10),
+		Path:     "/",
+		Expires:  until,
+		MaxAge:   int(m.window.Seconds()) + 1,
+		Secure:   c.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	userID, ok := c.Get("userID")
+	if !ok {
+		return
+	}
+	// 请求可能已被取消，标记仍需写入
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readYourWritesTimeout)
+	defer cancel()
+	if err := m.redisClient.Set(ctx, m.userKey(userID), 1, m.window).Err(); err != nil {
+		m.logger.Warn("Read-your-writes marker write failed", zap.Error(err))
+	}
+}
+
+// readYourWritesWriter 在响应头发出前检查请求是否写过数据，
+// 处理函数返回后再设置 cookie 已经来不及
+type readYourWritesWriter struct {
+	gin.ResponseWriter
+	c      *gin.Context
+	m      *ReadYourWritesMiddleware
+	marked bool
+}
+
+func (w *readYourWritesWriter) beforeWrite() {
+	if w.marked || w.ResponseWriter.Written() {
+		return
+	}
+	w.marked = true
+	w.m.markWritten(w.c)
+}
+
+func (w *readYourWritesWriter) WriteHeaderNow() {
+	w.beforeWrite()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *readYourWritesWriter) Write(data []byte) (int, error) {
+	w.beforeWrite()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *readYourWritesWriter) WriteString(s string) (int, error) {
+	w.beforeWrite()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *readYourWritesWriter) Flush() {
+	w.beforeWrite()
+	w.ResponseWriter.Flush()
+}
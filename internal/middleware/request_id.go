@@ -0,0 +1,47 @@
+// internal/middleware/request_id.go
+package middleware
+
+import (
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 64
+)
+
+type RequestIDMiddleware struct{}
+
+func NewRequestIDMiddleware() *RequestIDMiddleware {
+	return &RequestIDMiddleware{}
+}
+
+// Handle 沿用上游传入的请求 ID，没有或不合法时生成新的；
+// 写入请求上下文和响应头，访问日志和 SQL 日志据此关联
+func (m *RequestIDMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度有限的可打印 ASCII，防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+// internal/middleware/tenant.go
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/internal/tenant"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TenantMiddleware struct {
+	orgService service.OrganizationService
+	logger     logger.Logger
+}
+
+func NewTenantMiddleware(
+	orgService service.OrganizationService,
+	logger logger.Logger,
+) *TenantMiddleware {
+	return &TenantMiddleware{
+		orgService: orgService,
+		logger:     logger.With(zap.String("module", "tenant_middleware")),
+	}
+}
+
+// RequireTenant 要求令牌已选择组织且当前用户仍是成员；
+// 通过后将租户写入请求上下文，roles 非空时还会校验组织内角色。需挂在 JWT 中间件之后
+func (m *TenantMiddleware) RequireTenant(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := ClaimTenantID(c)
+		if tenantID == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "no organization selected"})
+			return
+		}
+		userID := c.GetUint("userID")
+
+		membership, err := m.orgService.GetMembership(c.Request.Context(), tenantID, userID)
+		if err != nil {
+			if !errors.Is(err, service.ErrNotMember) {
+				m.logger.Error("membership lookup failed",
+					zap.Uint("tenant_id", tenantID),
+					zap.Uint("user_id", userID),
+					zap.Error(err))
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrNotOrgMember.Error()})
+			return
+		}
+
+		if len(roles) > 0 && !containsRole(roles, membership.Role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), tenantID))
+		c.Set("membership", membership)
+		c.Next()
+	}
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
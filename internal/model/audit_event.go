@@ -0,0 +1,16 @@
+// internal/model/audit_event.go
+package model
+
+import "time"
+
+// AuditEvent 审计事件，按租户隔离
+type AuditEvent struct {
+	ID uint `gorm:"primarykey" json:"id"`
+	TenantModel
+	ActorID    uint      `gorm:"index" json:"actor_id"` // 0 表示系统或匿名操作
+	Action     string    `gorm:"size:64;not null;index" json:"action"`
+	TargetType string    `gorm:"size:64" json:"target_type"`
+	TargetID   uint      `json:"target_id"`
+	Metadata   string    `gorm:"type:text" json:"metadata"` // JSON
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
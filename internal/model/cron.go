@@ -0,0 +1,46 @@
+// internal/model/cron.go
+package model
+
+import "time"
+
+// 定时任务运行状态
+const (
+	CronRunRunning   = "running"
+	CronRunSucceeded = "succeeded"
+	CronRunFailed    = "failed"
+	CronRunSkipped   = "skipped" // 上一次运行尚未结束，按重叠策略跳过
+)
+
+// 定时任务的触发方式
+const (
+	CronTriggerSchedule = "schedule"
+	CronTriggerManual   = "manual"
+)
+
+// CronJobState 定时任务的可变状态，任务定义本身在代码中注册。
+// 没有记录的任务视为未暂停
+type CronJobState struct {
+	Name      string    `gorm:"primaryKey;size:64" json:"name"`
+	Paused    bool      `gorm:"not null;default:false" json:"paused"`
+	UpdatedBy *uint     `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CronJobState) TableName() string {
+	return "cron_jobs"
+}
+
+// CronRun 定时任务的运行记录
+type CronRun struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	JobName     string     `gorm:"size:64;not null;index:idx_cron_runs_job_started,priority:1" json:"job_name"`
+	Trigger     string     `gorm:"size:16;not null" json:"trigger"`
+	TriggeredBy *uint      `json:"triggered_by"` // 手动触发的管理员
+	Status      string     `gorm:"size:16;not null" json:"status"`
+	ScheduledAt time.Time  `gorm:"not null" json:"scheduled_at"`
+	StartedAt   time.Time  `gorm:"not null;index:idx_cron_runs_job_started,priority:2" json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `gorm:"not null;default:0" json:"duration_ms"`
+	Error       string     `gorm:"size:1024" json:"error"`
+	Instance    string     `gorm:"size:255;not null" json:"instance"` // 执行的实例
+}
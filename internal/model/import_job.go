@@ -0,0 +1,36 @@
+// internal/model/import_job.go
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 导入任务状态
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// ImportJob 批量导入用户的后台任务
+type ImportJob struct {
+	gorm.Model
+	Format        string     `gorm:"size:16;not null" json:"format"`
+	Status        string     `gorm:"size:16;not null;index" json:"status"`
+	CreatedBy     uint       `gorm:"not null" json:"created_by"`
+	TotalRows     int        `json:"total_rows"`
+	SucceededRows int        `json:"succeeded_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Errors        string     `gorm:"type:mediumtext" json:"-"` // JSON 编码的 []ImportRowError
+	Message       string     `gorm:"size:1024" json:"message"` // 任务级错误
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// ImportRowError 单行导入错误，Row 为源文件中的行号
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
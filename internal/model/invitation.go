@@ -0,0 +1,27 @@
+// internal/model/invitation.go
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invitation 组织邀请，令牌只保存随机数的哈希，重发时轮换使旧链接失效
+type Invitation struct {
+	gorm.Model
+	TenantModel
+	Email      string     `gorm:"size:255;not null;index" json:"email"`
+	Role       string     `gorm:"size:32;not null" json:"role"`
+	InvitedBy  uint       `gorm:"not null" json:"invited_by"`
+	NonceHash  string     `gorm:"size:64;not null" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	AcceptedBy *uint      `json:"accepted_by"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// IsPending 未接受、未撤销且未过期
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
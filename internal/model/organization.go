@@ -0,0 +1,38 @@
+// internal/model/organization.go
+package model
+
+import "gorm.io/gorm"
+
+// 组织内角色
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+type Organization struct {
+	gorm.Model
+	Name string `gorm:"size:255;not null" json:"name"`
+	Slug string `gorm:"size:64;not null;unique" json:"slug"`
+}
+
+// TenantModel 租户表嵌入该结构（或自行声明 TenantID 字段），
+// tenant 插件会按上下文中的租户自动过滤
+type TenantModel struct {
+	TenantID uint `gorm:"not null;index" json:"tenant_id"` // 即组织ID
+}
+
+// Membership 用户与组织的成员关系
+type Membership struct {
+	gorm.Model
+	TenantID     uint          `gorm:"not null;uniqueIndex:idx_membership_tenant_user,priority:1" json:"tenant_id"`
+	UserID       uint          `gorm:"not null;uniqueIndex:idx_membership_tenant_user,priority:2" json:"user_id"`
+	Role         string        `gorm:"size:32;not null" json:"role"`
+	User         *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Organization *Organization `gorm:"foreignKey:TenantID" json:"organization,omitempty"`
+}
+
+// IsAdmin 是否具有组织管理权限
+func (m *Membership) IsAdmin() bool {
+	return m.Role == RoleOwner || m.Role == RoleAdmin
+}
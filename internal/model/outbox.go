@@ -0,0 +1,33 @@
+// internal/model/outbox.go
+package model
+
+import "time"
+
+// OutboxEvent 待发布的领域事件，与业务数据在同一事务中写入，
+// 由后台中继发布到消息代理，发布成功后删除
+type OutboxEvent struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	EventID       string    `gorm:"size:36;not null;uniqueIndex" json:"event_id"` // 消费方据此去重
+	Type          string    `gorm:"size:128;not null" json:"type"`
+	AggregateType string    `gorm:"size:64;not null" json:"aggregate_type"`
+	AggregateID   string    `gorm:"size:64;not null" json:"aggregate_id"`
+	Payload       string    `gorm:"type:text;not null" json:"payload"` // JSON
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     string    `gorm:"size:1024" json:"last_error"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DeadLetterEvent 超过最大重试次数仍发布失败的事件，需人工处理
+type DeadLetterEvent struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	EventID       string    `gorm:"size:36;not null;uniqueIndex" json:"event_id"`
+	Type          string    `gorm:"size:128;not null" json:"type"`
+	AggregateType string    `gorm:"size:64;not null" json:"aggregate_type"`
+	AggregateID   string    `gorm:"size:64;not null" json:"aggregate_id"`
+	Payload       string    `gorm:"type:text;not null" json:"payload"`
+	Attempts      int       `gorm:"not null" json:"attempts"`
+	LastError     string    `gorm:"size:1024" json:"last_error"`
+	OccurredAt    time.Time `gorm:"not null" json:"occurred_at"` // 原事件的写入时间
+	CreatedAt     time.Time `json:"created_at"`
+}
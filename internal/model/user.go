@@ -3,12 +3,30 @@ package model
 
 import "gorm.io/gorm"
 
+// 系统角色，区别于组织内角色
+const (
+	SystemRoleUser  = "user"
+	SystemRoleAdmin = "admin"
+)
+
 type User struct {
 	gorm.Model
 	Username string `gorm:"size:255;not null;unique" json:"username"`
-	Password string `gorm:"size:255;not null" json:"password"`
-	Email    string `gorm:"size:255;unique" json:"email"`
-	Status   string `gorm:"size:255;unique" json:"status"`
+	Password string `gorm:"size:255;not null" json:"-"`                 // 密码哈希永不序列化
+	Email    string `gorm:"size:512;serializer:encrypted" json:"email"` // 加密存储，按 EmailIndex 查询
+	// EmailIndex 邮箱的盲索引，用于等值查询和唯一约束，由仓储写入
+	EmailIndex *string `gorm:"size:64;uniqueIndex" json:"-"`
+	Status     string  `gorm:"size:32;not null;default:active;index" json:"status"`
+	Role       string  `gorm:"size:32;not null;default:user" json:"role"`
+
+	// 扩展资料
+	DisplayName string `gorm:"size:64" json:"display_name"`
+	Locale      string `gorm:"size:35" json:"locale"`
+	Timezone    string `gorm:"size:64" json:"timezone"`
+	AvatarURL   string `gorm:"size:1024" json:"avatar_url"`
+
+	// 令牌版本号，修改密码或注销账户时递增，使旧令牌全部失效
+	TokenVersion uint `gorm:"not null;default:0" json:"token_version"`
 }
 
 // GetUserID 实现 jwt.Identity 接口
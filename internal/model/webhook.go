@@ -0,0 +1,82 @@
+// internal/model/webhook.go
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookAllEvents 订阅全部事件
+const WebhookAllEvents = "*"
+
+// 投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint 事件回调地址。TenantID 为 0 的是系统管理员注册的应用级端点，
+// 接收全部用户的事件；组织端点只接收本组织成员的事件
+type WebhookEndpoint struct {
+	gorm.Model
+	TenantID            uint       `gorm:"not null;index" json:"tenant_id"`
+	URL                 string     `gorm:"size:2048;not null" json:"url"`
+	Secret              string     `gorm:"size:512;not null;serializer:encrypted" json:"-"` // 签名密钥
+	EventTypes          string     `gorm:"size:1024;not null" json:"event_types"`           // 逗号分隔，* 表示全部
+	Description         string     `gorm:"size:255" json:"description"`
+	Enabled             bool       `gorm:"not null;default:true" json:"enabled"`
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      string     `gorm:"size:255" json:"disabled_reason"`
+}
+
+// EventTypeList 订阅的事件类型
+func (e *WebhookEndpoint) EventTypeList() []string {
+	if e.EventTypes == "" {
+		return nil
+	}
+	return strings.Split(e.EventTypes, ",")
+}
+
+// Subscribes 是否订阅了该事件类型
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	for _, t := range e.EventTypeList() {
+		if t == WebhookAllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一个事件到一个端点的投递，重试和重放都复用该记录
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	TenantID       uint       `gorm:"not null;index" json:"tenant_id"`
+	EndpointID     uint       `gorm:"not null;uniqueIndex:idx_webhook_delivery_endpoint_event,priority:1" json:"endpoint_id"`
+	EventID        string     `gorm:"size:36;not null;uniqueIndex:idx_webhook_delivery_endpoint_event,priority:2" json:"event_id"`
+	EventType      string     `gorm:"size:128;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"` // 请求体
+	Status         string     `gorm:"size:16;not null;index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `gorm:"size:1024" json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookAttempt 投递日志，记录每次请求的结果
+type WebhookAttempt struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	TenantID     uint      `gorm:"not null;index" json:"tenant_id"`
+	DeliveryID   uint      `gorm:"not null;index" json:"delivery_id"`
+	StatusCode   int       `json:"status_code"` // 0 表示未收到响应
+	Error        string    `gorm:"size:1024" json:"error"`
+	ResponseBody string    `gorm:"size:1024" json:"response_body"` // 响应体前 1KB
+	DurationMS   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+// internal/repository/audit_repository.go
+package repository
+
+import (
+	"context"
+
+	"gin-wire-demo/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEvent) error
+}
+
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{db: db}
+}
+
+func (r *AuditRepositoryImpl) Create(ctx context.Context, event *model.AuditEvent) error {
+	return conn(ctx, r.db).Create(event).Error
+}
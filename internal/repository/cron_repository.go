@@ -0,0 +1,87 @@
+// internal/repository/cron_repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin-wire-demo/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CronRepository interface {
+	IsPaused(ctx context.Context, name string) (bool, error)
+	SetPaused(ctx context.Context, name string, paused bool, actorID uint) error
+	ListStates(ctx context.Context) ([]model.CronJobState, error)
+	CreateRun(ctx context.Context, run *model.CronRun) error
+	UpdateRun(ctx context.Context, id uint, fields map[string]interface{}) error
+	ListRuns(ctx context.Context, name string, limit int) ([]model.CronRun, error)
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type CronRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCronRepository(db *gorm.DB) *CronRepositoryImpl {
+	return &CronRepositoryImpl{db: db}
+}
+
+func (r *CronRepositoryImpl) IsPaused(ctx context.Context, name string) (bool, error) {
+	var state model.CronJobState
+	err := conn(ctx, r.db).Where("name = ?", name).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return state.Paused, nil
+}
+
+// SetPaused 暂停或恢复任务，记录不存在时创建
+func (r *CronRepositoryImpl) SetPaused(ctx context.Context, name string, paused bool, actorID uint) error {
+	state := &model.CronJobState{Name: name, Paused: paused, UpdatedBy: &actorID}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_by", "updated_at"}),
+	}).Create(state).Error
+}
+
+func (r *CronRepositoryImpl) ListStates(ctx context.Context) ([]model.CronJobState, error) {
+	var states []model.CronJobState
+	if err := conn(ctx, r.db).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (r *CronRepositoryImpl) CreateRun(ctx context.Context, run *model.CronRun) error {
+	return conn(ctx, r.db).Create(run).Error
+}
+
+func (r *CronRepositoryImpl) UpdateRun(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return conn(ctx, r.db).Model(&model.CronRun{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ListRuns 按开始时间倒序返回任务的运行记录
+func (r *CronRepositoryImpl) ListRuns(ctx context.Context, name string, limit int) ([]model.CronRun, error) {
+	var runs []model.CronRun
+	if err := conn(ctx, r.db).
+		Where("job_name = ?", name).
+		Order("started_at DESC, id DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// DeleteRunsBefore 删除 before 之前开始的运行记录
+func (r *CronRepositoryImpl) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("started_at < ?", before).Delete(&model.CronRun{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,54 @@
+// internal/repository/import_job_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *model.ImportJob) error
+	FindByID(ctx context.Context, id uint) (*model.ImportJob, error)
+	Updates(ctx context.Context, id uint, fields map[string]interface{}) error
+	// FailStale 将 updated_at 早于 before 的未结束任务标记为失败，返回更新的任务数
+	FailStale(ctx context.Context, before time.Time, message string) (int64, error)
+}
+
+type ImportJobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) *ImportJobRepositoryImpl {
+	return &ImportJobRepositoryImpl{db: db}
+}
+
+func (r *ImportJobRepositoryImpl) Create(ctx context.Context, job *model.ImportJob) error {
+	return conn(ctx, r.db).Create(job).Error
+}
+
+func (r *ImportJobRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.ImportJob, error) {
+	var job model.ImportJob
+	if err := conn(ctx, r.db).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ImportJobRepositoryImpl) Updates(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return conn(ctx, r.db).Model(&model.ImportJob{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *ImportJobRepositoryImpl) FailStale(ctx context.Context, before time.Time, message string) (int64, error) {
+	result := conn(ctx, r.db).Model(&model.ImportJob{}).
+		Where("status IN ? AND updated_at < ?", []string{model.ImportJobPending, model.ImportJobRunning}, before).
+		Updates(map[string]interface{}{
+			"status":      model.ImportJobFailed,
+			"message":     message,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
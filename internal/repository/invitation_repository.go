@@ -0,0 +1,90 @@
+// internal/repository/invitation_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// InvitationRepository 组织邀请，所有查询按上下文中的租户自动过滤
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *model.Invitation) error
+	FindByID(ctx context.Context, id uint) (*model.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string) (*model.Invitation, error)
+	ListPending(ctx context.Context) ([]model.Invitation, error)
+	Updates(ctx context.Context, id uint, fields map[string]interface{}) error
+	MarkAccepted(ctx context.Context, id, userID uint) (bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type InvitationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *InvitationRepositoryImpl {
+	return &InvitationRepositoryImpl{db: db}
+}
+
+func (r *InvitationRepositoryImpl) Create(ctx context.Context, invitation *model.Invitation) error {
+	return conn(ctx, r.db).Create(invitation).Error
+}
+
+func (r *InvitationRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := conn(ctx, r.db).First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepositoryImpl) FindPendingByEmail(ctx context.Context, email string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := r.pending(ctx).Where("email = ?", email).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepositoryImpl) ListPending(ctx context.Context) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	if err := r.pending(ctx).Order("id").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *InvitationRepositoryImpl) Updates(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return conn(ctx, r.db).Model(&model.Invitation{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// MarkAccepted 条件更新保证邀请只能被接受一次，返回是否抢占成功
+func (r *InvitationRepositoryImpl) MarkAccepted(ctx context.Context, id, userID uint) (bool, error) {
+	result := conn(ctx, r.db).Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"accepted_at": time.Now(),
+			"accepted_by": userID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteExpired 软删除 before 之前过期且未被接受或撤销的邀请，跨全部租户
+func (r *InvitationRepositoryImpl) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(tenant.WithoutScope(ctx), r.db).
+		Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at < ?", before).
+		Delete(&model.Invitation{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *InvitationRepositoryImpl) pending(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.db).
+		Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now())
+}
@@ -0,0 +1,101 @@
+// internal/repository/organization_repository.go
+package repository
+
+import (
+	"context"
+
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository 组织与成员关系，成员查询按上下文中的租户自动过滤
+type OrganizationRepository interface {
+	CreateWithOwner(ctx context.Context, org *model.Organization, ownerID uint) error
+	FindByID(ctx context.Context, id uint) (*model.Organization, error)
+	FindBySlug(ctx context.Context, slug string) (*model.Organization, error)
+	FindMembership(ctx context.Context, userID uint) (*model.Membership, error)
+	ListMemberships(ctx context.Context) ([]model.Membership, error)
+	ListUserMemberships(ctx context.Context, userID uint) ([]model.Membership, error)
+	CreateMembership(ctx context.Context, membership *model.Membership) error
+	UpdateMembershipRole(ctx context.Context, userID uint, role string) error
+	DeleteMembership(ctx context.Context, userID uint) error
+}
+
+type OrganizationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepositoryImpl {
+	return &OrganizationRepositoryImpl{db: db}
+}
+
+// CreateWithOwner 创建组织并将创建者设为 owner
+func (r *OrganizationRepositoryImpl) CreateWithOwner(ctx context.Context, org *model.Organization, ownerID uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		membership := &model.Membership{UserID: ownerID, Role: model.RoleOwner}
+		return tx.WithContext(tenant.WithTenant(ctx, org.ID)).Create(membership).Error
+	})
+}
+
+func (r *OrganizationRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.Organization, error) {
+	var org model.Organization
+	if err := conn(ctx, r.db).First(&org, id).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepositoryImpl) FindBySlug(ctx context.Context, slug string) (*model.Organization, error) {
+	var org model.Organization
+	if err := conn(ctx, r.db).Where("slug = ?", slug).First(&org).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepositoryImpl) FindMembership(ctx context.Context, userID uint) (*model.Membership, error) {
+	var membership model.Membership
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&membership).Error; err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *OrganizationRepositoryImpl) ListMemberships(ctx context.Context) ([]model.Membership, error) {
+	var memberships []model.Membership
+	if err := conn(ctx, r.db).Preload("User").Order("id").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// ListUserMemberships 列出用户加入的全部组织，属于跨租户查询
+func (r *OrganizationRepositoryImpl) ListUserMemberships(ctx context.Context, userID uint) ([]model.Membership, error) {
+	var memberships []model.Membership
+	if err := conn(tenant.WithoutScope(ctx), r.db).
+		Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *OrganizationRepositoryImpl) CreateMembership(ctx context.Context, membership *model.Membership) error {
+	return conn(ctx, r.db).Create(membership).Error
+}
+
+func (r *OrganizationRepositoryImpl) UpdateMembershipRole(ctx context.Context, userID uint, role string) error {
+	return conn(ctx, r.db).Model(&model.Membership{}).Where("user_id = ?", userID).Update("role", role).Error
+}
+
+// DeleteMembership 物理删除成员关系，便于之后重新加入
+func (r *OrganizationRepositoryImpl) DeleteMembership(ctx context.Context, userID uint) error {
+	return conn(ctx, r.db).Unscoped().Where("user_id = ?", userID).Delete(&model.Membership{}).Error
+}
@@ -0,0 +1,99 @@
+// internal/repository/outbox_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...*model.OutboxEvent) error
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+	Delete(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastErr string) error
+	MoveToDeadLetter(ctx context.Context, event *model.OutboxEvent, lastErr string) error
+}
+
+type OutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepositoryImpl {
+	return &OutboxRepositoryImpl{db: db}
+}
+
+// Add 写入事件，应与产生事件的业务写操作处于同一事务
+func (r *OutboxRepositoryImpl) Add(ctx context.Context, events ...*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Create(events).Error
+}
+
+// ClaimDue 领取到期的事件：把 next_attempt_at 推后 lease 作为占用标记，
+// 条件更新失败说明已被其他实例领取。占用期内未删除或重新排期的事件，到期后会被再次领取
+func (r *OutboxRepositoryImpl) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	var due []*model.OutboxEvent
+	if err := conn(ctx, r.db).
+		Where("next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := due[:0]
+	leaseUntil := now.Add(lease)
+	for _, event := range due {
+		result := conn(ctx, r.db).Model(&model.OutboxEvent{}).
+			Where("id = ? AND next_attempt_at <= ?", event.ID, now).
+			Update("next_attempt_at", leaseUntil)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			event.NextAttemptAt = leaseUntil
+			claimed = append(claimed, event)
+		}
+	}
+	return claimed, nil
+}
+
+// Delete 删除已成功发布的事件
+func (r *OutboxRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&model.OutboxEvent{}, id).Error
+}
+
+// MarkFailed 记录发布失败并安排下次重试
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastErr string) error {
+	return conn(ctx, r.db).Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastErr,
+		}).Error
+}
+
+// MoveToDeadLetter 在同一事务中写入死信表并删除 outbox 记录
+func (r *OutboxRepositoryImpl) MoveToDeadLetter(ctx context.Context, event *model.OutboxEvent, lastErr string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.DeadLetterEvent{
+			EventID:       event.EventID,
+			Type:          event.Type,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       event.Payload,
+			Attempts:      event.Attempts,
+			LastError:     lastErr,
+			OccurredAt:    event.CreatedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.OutboxEvent{}, event.ID).Error
+	})
+}
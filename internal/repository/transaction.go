@@ -0,0 +1,105 @@
+// internal/repository/transaction.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	mysqlErrLockDeadlock  = 1213 // ER_LOCK_DEADLOCK
+	sqlStateSerialization = "40001"
+	sqlStateDeadlock      = "40P01" // postgres deadlock_detected
+	txRetryBaseDelay      = 20 * time.Millisecond
+)
+
+// TxManager 在事务上下文中执行函数。仓储通过上下文取得当前事务，
+// 因此 fn 内经由 ctx 调用的所有仓储方法都在同一事务中
+type TxManager interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type GormTxManager struct {
+	db         *gorm.DB
+	maxRetries int
+	logger     logger.Logger
+}
+
+func NewTxManager(
+	db *gorm.DB,
+	config *config.Config,
+	logger logger.Logger,
+) *GormTxManager {
+	return &GormTxManager{
+		db:         db,
+		maxRetries: config.Database.TxMaxRetries,
+		logger:     logger.With(zap.String("module", "tx_manager")),
+	}
+}
+
+// Transaction 开启事务执行 fn。已处于事务中时使用保存点实现嵌套，
+// 内层失败只回滚到保存点；最外层事务遇到死锁或序列化冲突时整体重试，
+// 所以 fn 除数据库操作外应当可以安全地重复执行
+func (m *GormTxManager) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx).Transaction(func(nested *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, nested))
+		})
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || attempt >= m.maxRetries || !isRetryableTxError(err) {
+			return err
+		}
+
+		// 指数退避加随机抖动，避免冲突的事务同时重试
+		delay := txRetryBaseDelay << attempt
+		delay += time.Duration(rand.Int63n(int64(delay)))
+		m.logger.Warn("transaction conflict, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// conn 返回上下文中的事务，没有事务时返回普通连接
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrLockDeadlock ||
+			string(mysqlErr.SQLState[:]) == sqlStateSerialization
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == sqlStateSerialization || pgErr.Code == sqlStateDeadlock
+	}
+	return false
+}
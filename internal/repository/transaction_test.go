@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func newTestTxManager(t *testing.T, db *gorm.DB, maxRetries int) *GormTxManager {
+	t.Helper()
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{TxMaxRetries: maxRetries},
+		Log:      config.LogConfig{Level: "error"},
+	}
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTxManager(db, cfg, log)
+}
+
+func usernames(t *testing.T, repo *UserRepositoryImpl) []string {
+	t.Helper()
+	var names []string
+	err := repo.FindInBatches(context.Background(), 100, func(users []model.User) error {
+		for _, u := range users {
+			names = append(names, u.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestNestedTransactionRollsBackToSavepoint(t *testing.T) {
+	gdb, keyring := newTestDB(t)
+	repo := NewUserRepository(gdb, keyring)
+	txManager := newTestTxManager(t, gdb, 0)
+	errInner := errors.New("inner failed")
+
+	err := txManager.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repo.Create(ctx, &model.User{Username: "outer", Password: "hash"}); err != nil {
+			return err
+		}
+		// 内层失败只回滚内层的写入
+		err := txManager.Transaction(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, &model.User{Username: "inner", Password: "hash"}); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Fatalf("inner transaction: got %v, want errInner", err)
+		}
+		// 内层成功的写入随外层提交
+		return txManager.Transaction(ctx, func(ctx context.Context) error {
+			return repo.Create(ctx, &model.User{Username: "inner-ok", Password: "hash"})
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := usernames(t, repo)
+	if len(got) != 2 || got[0] != "outer" || got[1] != "inner-ok" {
+		t.Fatalf("users = %v, want [outer inner-ok]", got)
+	}
+
+	// 外层失败时内层已完成的写入一并回滚
+	errOuter := errors.New("outer failed")
+	err = txManager.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := txManager.Transaction(ctx, func(ctx context.Context) error {
+			return repo.Create(ctx, &model.User{Username: "nested", Password: "hash"})
+		}); err != nil {
+			return err
+		}
+		return errOuter
+	})
+	if !errors.Is(err, errOuter) {
+		t.Fatalf("outer transaction: got %v, want errOuter", err)
+	}
+	if got := usernames(t, repo); len(got) != 2 {
+		t.Fatalf("users after outer rollback = %v", got)
+	}
+}
+
+func TestTransactionRetriesConflicts(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: mysqlErrLockDeadlock}
+	serialization := &pgconn.PgError{Code: sqlStateSerialization}
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       []error // 依次作为每次执行的结果，用完后返回 nil
+		wantCalls  int
+		wantErr    error
+	}{
+		{"succeeds after retries", 3, []error{deadlock, serialization}, 3, nil},
+		{"gives up after max retries", 2, []error{deadlock, deadlock, deadlock, deadlock}, 3, deadlock},
+		{"retries disabled", 0, []error{deadlock}, 1, deadlock},
+		{"other errors are not retried", 3, []error{gorm.ErrInvalidData}, 1, gorm.ErrInvalidData},
+		{"postgres deadlock", 1, []error{&pgconn.PgError{Code: sqlStateDeadlock}}, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gdb, keyring := newTestDB(t)
+			repo := NewUserRepository(gdb, keyring)
+			txManager := newTestTxManager(t, gdb, tt.maxRetries)
+
+			calls := 0
+			err := txManager.Transaction(context.Background(), func(ctx context.Context) error {
+				calls++
+				if err := repo.Create(ctx, &model.User{Username: "alice", Password: "hash"}); err != nil {
+					return err
+				}
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			// 失败的尝试均已回滚，唯一约束不会冲突
+			want := 1
+			if tt.wantErr != nil {
+				want = 0
+			}
+			if got := usernames(t, repo); len(got) != want {
+				t.Fatalf("users = %v", got)
+			}
+		})
+	}
+}
+
+func TestTransactionRetryStopsWhenContextCanceled(t *testing.T) {
+	gdb, _ := newTestDB(t)
+	txManager := newTestTxManager(t, gdb, 5)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := txManager.Transaction(ctx, func(ctx context.Context) error {
+		calls++
+		return &mysql.MySQLError{Number: mysqlErrLockDeadlock}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	// 首次退避至少 20ms，超时前只执行一次
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+}
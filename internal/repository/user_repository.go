@@ -2,41 +2,196 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/tenant"
+	"gin-wire-demo/pkg/fieldcrypt"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	Create(user *model.User) error
-	FindByID(id uint) (*model.User, error)
-	FindByUsername(username string) (*model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	Updates(ctx context.Context, id uint, fields map[string]interface{}) error
+	Delete(ctx context.Context, id uint) error
+	CreateBatch(ctx context.Context, users []*model.User) error
+	FindInBatches(ctx context.Context, batchSize int, fn func(users []model.User) error) error
+	ReencryptEmails(ctx context.Context, batchSize int) (int, error)
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error)
 }
 
+// UserRepositoryImpl 邮箱由 encrypted 序列化器加密存储，
+// 写入时同步维护盲索引 email_index，按邮箱查询走盲索引
 type UserRepositoryImpl struct {
-	db *gorm.DB
+	db      *gorm.DB
+	keyring *fieldcrypt.Keyring
+}
+
+func NewUserRepository(db *gorm.DB, keyring *fieldcrypt.Keyring) *UserRepositoryImpl {
+	return &UserRepositoryImpl{db: db, keyring: keyring}
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepositoryImpl {
-	return &UserRepositoryImpl{db: db}
+func (r *UserRepositoryImpl) Create(ctx context.Context, user *model.User) error {
+	user.EmailIndex = r.emailIndex(user.Email)
+	return conn(ctx, r.db).Create(user).Error
 }
 
-func (r *UserRepositoryImpl) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := conn(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (r *UserRepositoryImpl) FindByID(id uint) (*model.User, error) {
+func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := conn(ctx, r.db).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *UserRepositoryImpl) FindByUsername(username string) (*model.User, error) {
+func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := conn(ctx, r.db).Where("email_index = ?", r.keyring.BlindIndex(email)).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// Updates 按字段更新用户，fields 的键为列名。
+// map 更新不经过序列化器，邮箱在这里加密并同步盲索引
+func (r *UserRepositoryImpl) Updates(ctx context.Context, id uint, fields map[string]interface{}) error {
+	if email, ok := fields["email"].(string); ok {
+		encrypted, err := r.encryptEmail(email)
+		if err != nil {
+			return err
+		}
+		copied := make(map[string]interface{}, len(fields)+1)
+		for k, v := range fields {
+			copied[k] = v
+		}
+		copied["email"] = encrypted
+		copied["email_index"] = r.emailIndex(email)
+		fields = copied
+	}
+	return conn(ctx, r.db).Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// Delete 软删除用户
+func (r *UserRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&model.User{}, id).Error
+}
+
+// CreateBatch 在同一事务内批量插入，任意一行失败整批回滚
+func (r *UserRepositoryImpl) CreateBatch(ctx context.Context, users []*model.User) error {
+	for _, user := range users {
+		user.EmailIndex = r.emailIndex(user.Email)
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(users, len(users)).Error
+	})
+}
+
+// FindInBatches 按主键顺序分批遍历全部用户
+func (r *UserRepositoryImpl) FindInBatches(ctx context.Context, batchSize int, fn func(users []model.User) error) error {
+	var users []model.User
+	return conn(ctx, r.db).FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(users)
+	}).Error
+}
+
+// PurgeDeleted 彻底删除 before 之前软删除的用户及其组织成员关系，每次最多 limit 个，返回删除的用户数
+func (r *UserRepositoryImpl) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	var ids []uint
+	if err := conn(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := conn(tenant.WithoutScope(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id IN ?", ids).Delete(&model.Membership{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.User{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// emailRow 绕过序列化器读取邮箱列的原始内容
+type emailRow struct {
+	ID         uint
+	Email      string
+	EmailIndex *string
+}
+
+// ReencryptEmails 将未使用当前密钥加密的邮箱（含加密上线前的明文和旧版本密文）
+// 用当前密钥重新加密并补齐盲索引，包括已软删除的用户。返回更新的行数
+func (r *UserRepositoryImpl) ReencryptEmails(ctx context.Context, batchSize int) (int, error) {
+	active := r.keyring.ActiveVersion()
+	updated := 0
+	var rows []emailRow
+	// 使用 Table 而不是 Model，让 GORM 按 emailRow 解析结构，不套用序列化器和软删除条件
+	err := conn(ctx, r.db).Table("users").
+		Select("id", "email", "email_index").
+		FindInBatches(&rows, batchSize, func(tx *gorm.DB, batch int) error {
+			for _, row := range rows {
+				version, encrypted := fieldcrypt.KeyVersion(row.Email)
+				if row.Email == "" || (encrypted && version == active && row.EmailIndex != nil) {
+					continue
+				}
+				plaintext := row.Email
+				if encrypted {
+					var err error
+					if plaintext, err = r.keyring.Decrypt(row.Email); err != nil {
+						return fmt.Errorf("user %d: %w", row.ID, err)
+					}
+				}
+				ciphertext, err := r.keyring.Encrypt(plaintext)
+				if err != nil {
+					return err
+				}
+				if err := conn(ctx, r.db).Model(&model.User{}).Unscoped().Where("id = ?", row.ID).
+					UpdateColumns(map[string]interface{}{
+						"email":       ciphertext,
+						"email_index": r.emailIndex(plaintext),
+					}).Error; err != nil {
+					return fmt.Errorf("user %d: %w", row.ID, err)
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
+
+func (r *UserRepositoryImpl) encryptEmail(email string) (string, error) {
+	if email == "" {
+		return "", nil
+	}
+	return r.keyring.Encrypt(email)
+}
+
+// emailIndex 空邮箱不建索引，避免唯一约束冲突
+func (r *UserRepositoryImpl) emailIndex(email string) *string {
+	if email == "" {
+		return nil
+	}
+	index := r.keyring.BlindIndex(email)
+	return &index
+}
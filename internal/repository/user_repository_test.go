@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/migrations"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/fieldcrypt"
+	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func randomKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// newTestDB 打开进程内的 SQLite 内存库并执行内嵌的迁移
+func newTestDB(t *testing.T) (*gorm.DB, *fieldcrypt.Keyring) {
+	t.Helper()
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: config.DriverSQLite, DBname: ":memory:"},
+		Log:      config.LogConfig{Level: "error"},
+		PII: config.PIIConfig{
+			ActiveKey: 1,
+			Keys:      []config.PIIKey{{Version: 1, Key: randomKey(t)}},
+			IndexKey:  randomKey(t),
+		},
+	}
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gdb, cleanup, err := db.NewDB(cfg, log, keyring)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	// 内存库只有一个连接，迁移需在同一个 sql.DB 上执行
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := migrations.For(config.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, config.DriverSQLite, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return gdb, keyring
+}
+
+func TestUserRepositoryCreateAndFind(t *testing.T) {
+	gdb, keyring := newTestDB(t)
+	repo := NewUserRepository(gdb, keyring)
+	ctx := context.Background()
+
+	user := &model.User{Username: "alice", Password: "hash", Email: "Alice@example.com", Status: "active"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.ID == 0 {
+		t.Fatal("create did not assign an id")
+	}
+
+	byID, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("find by id: %v", err)
+	}
+	if byID.Username != "alice" || byID.Email != "Alice@example.com" || byID.Role != "user" {
+		t.Fatalf("find by id got %+v", byID)
+	}
+	byName, err := repo.FindByUsername(ctx, "alice")
+	if err != nil || byName.ID != user.ID {
+		t.Fatalf("find by username: %+v, %v", byName, err)
+	}
+	byEmail, err := repo.FindByEmail(ctx, "alice@example.com")
+	if err != nil || byEmail.ID != user.ID {
+		t.Fatalf("find by email: %+v, %v", byEmail, err)
+	}
+
+	// 邮箱以密文存储
+	var stored string
+	if err := gdb.Raw("SELECT email FROM users WHERE id = ?", user.ID).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored == "" || strings.Contains(stored, "example.com") {
+		t.Fatalf("email stored as %q", stored)
+	}
+
+	// 唯一约束由迁移创建
+	err = repo.Create(ctx, &model.User{Username: "alice", Password: "hash", Email: "other@example.com"})
+	if err == nil {
+		t.Fatal("duplicate username was accepted")
+	}
+
+	if _, err := repo.FindByID(ctx, user.ID+1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing user: got %v, want ErrRecordNotFound", err)
+	}
+}
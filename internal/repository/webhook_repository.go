@@ -0,0 +1,204 @@
+// internal/repository/webhook_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/tenant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository webhook 端点与投递。端点分应用级（tenantID 为 0）和组织级，
+// 投递任务跨租户执行，因此所有方法显式按 tenantID 过滤，不依赖上下文中的租户
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error
+	FindEndpoint(ctx context.Context, tenantID, id uint) (*model.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, tenantID uint) ([]model.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, tenantID, id uint, fields map[string]interface{}) error
+	DeleteEndpoint(ctx context.Context, tenantID, id uint) error
+	FindSubscribers(ctx context.Context, tenantIDs []uint) ([]model.WebhookEndpoint, error)
+	RecordEndpointFailure(ctx context.Context, tenantID, id uint, disableAfter int, reason string) (bool, error)
+	ResetEndpointFailures(ctx context.Context, tenantID, id uint) error
+
+	CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	FindDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, tenantID, endpointID uint, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, id uint, fields map[string]interface{}) error
+	CreateAttempt(ctx context.Context, attempt *model.WebhookAttempt) error
+	ListAttempts(ctx context.Context, tenantID, deliveryID uint) ([]model.WebhookAttempt, error)
+}
+
+type WebhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepositoryImpl {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+// scoped 跳过租户插件，改为显式按 tenantID 过滤，应用级记录的 tenant_id 为 0
+func (r *WebhookRepositoryImpl) scoped(ctx context.Context, tenantID uint) *gorm.DB {
+	return conn(tenant.WithoutScope(ctx), r.db).Where("tenant_id = ?", tenantID)
+}
+
+func (r *WebhookRepositoryImpl) CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	return conn(tenant.WithoutScope(ctx), r.db).Create(endpoint).Error
+}
+
+func (r *WebhookRepositoryImpl) FindEndpoint(ctx context.Context, tenantID, id uint) (*model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	if err := r.scoped(ctx, tenantID).First(&endpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepositoryImpl) ListEndpoints(ctx context.Context, tenantID uint) ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	if err := r.scoped(ctx, tenantID).Order("id").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// UpdateEndpoint 更新端点字段，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *WebhookRepositoryImpl) UpdateEndpoint(ctx context.Context, tenantID, id uint, fields map[string]interface{}) error {
+	result := r.scoped(ctx, tenantID).Model(&model.WebhookEndpoint{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryImpl) DeleteEndpoint(ctx context.Context, tenantID, id uint) error {
+	result := r.scoped(ctx, tenantID).Delete(&model.WebhookEndpoint{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindSubscribers 列出指定租户（含应用级）下启用的端点
+func (r *WebhookRepositoryImpl) FindSubscribers(ctx context.Context, tenantIDs []uint) ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	if err := conn(tenant.WithoutScope(ctx), r.db).
+		Where("tenant_id IN ? AND enabled = ?", tenantIDs, true).
+		Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// RecordEndpointFailure 累加端点的连续失败次数，达到 disableAfter 时停用端点。
+// 返回本次调用是否停用了端点
+func (r *WebhookRepositoryImpl) RecordEndpointFailure(ctx context.Context, tenantID, id uint, disableAfter int, reason string) (bool, error) {
+	if err := r.scoped(ctx, tenantID).Model(&model.WebhookEndpoint{}).Where("id = ?", id).
+		UpdateColumn("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error; err != nil {
+		return false, err
+	}
+	result := r.scoped(ctx, tenantID).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND enabled = ? AND consecutive_failures >= ?", id, true, disableAfter).
+		Updates(map[string]interface{}{
+			"enabled":         false,
+			"disabled_at":     time.Now(),
+			"disabled_reason": reason,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ResetEndpointFailures 投递成功后清零连续失败次数
+func (r *WebhookRepositoryImpl) ResetEndpointFailures(ctx context.Context, tenantID, id uint) error {
+	return r.scoped(ctx, tenantID).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND consecutive_failures <> 0", id).
+		UpdateColumn("consecutive_failures", 0).Error
+}
+
+// CreateDeliveries 创建投递记录，同一端点的同一事件已存在时忽略，保证事件重复中继时不会重复投递
+func (r *WebhookRepositoryImpl) CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return conn(tenant.WithoutScope(ctx), r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(deliveries).Error
+}
+
+// ClaimDueDeliveries 领取到期的待投递记录，占用方式与 outbox 相同
+func (r *WebhookRepositoryImpl) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	ctx = tenant.WithoutScope(ctx)
+	var due []*model.WebhookDelivery
+	if err := conn(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := due[:0]
+	leaseUntil := now.Add(lease)
+	for _, delivery := range due {
+		result := conn(ctx, r.db).Model(&model.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, model.WebhookDeliveryPending, now).
+			UpdateColumn("next_attempt_at", leaseUntil)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			delivery.NextAttemptAt = leaseUntil
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *WebhookRepositoryImpl) FindDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := r.scoped(ctx, tenantID).Where("endpoint_id = ?", endpointID).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries 按时间倒序列出端点最近的投递
+func (r *WebhookRepositoryImpl) ListDeliveries(ctx context.Context, tenantID, endpointID uint, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	if err := r.scoped(ctx, tenantID).
+		Where("endpoint_id = ?", endpointID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepositoryImpl) UpdateDelivery(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return conn(tenant.WithoutScope(ctx), r.db).Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *WebhookRepositoryImpl) CreateAttempt(ctx context.Context, attempt *model.WebhookAttempt) error {
+	return conn(tenant.WithoutScope(ctx), r.db).Create(attempt).Error
+}
+
+func (r *WebhookRepositoryImpl) ListAttempts(ctx context.Context, tenantID, deliveryID uint) ([]model.WebhookAttempt, error) {
+	var attempts []model.WebhookAttempt
+	if err := r.scoped(ctx, tenantID).
+		Where("delivery_id = ?", deliveryID).
+		Order("id").
+		Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
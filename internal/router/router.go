@@ -7,10 +7,12 @@ import (
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"gin-wire-demo/internal/config"
 	"gin-wire-demo/internal/controller"
 	"gin-wire-demo/internal/middleware"
+	"gin-wire-demo/internal/model"
 	"gin-wire-demo/pkg/logger"
 )
 
@@ -22,10 +24,23 @@ type Router struct {
 
 func NewRouter(
 	userController *controller.UserController,
+	profileController *controller.ProfileController,
+	orgController *controller.OrganizationController,
+	inviteController *controller.InvitationController,
+	importController *controller.UserImportController,
+	webhookController *controller.WebhookController,
+	queueController *controller.QueueController,
+	cronController *controller.CronController,
+	redisController *controller.RedisController,
 	authMiddleware *middleware.AuthMiddleware,
 	authController *controller.AuthController,
 	jwtMiddleware *middleware.JWT,
 	rateLimiter *middleware.RateLimiterMiddleware,
+	tenantMiddleware *middleware.TenantMiddleware,
+	adminMiddleware *middleware.AdminMiddleware,
+	deadlineMiddleware *middleware.DeadlineMiddleware,
+	readYourWrites *middleware.ReadYourWritesMiddleware,
+	requestID *middleware.RequestIDMiddleware,
 	cfg *config.Config,
 	logger logger.Logger,
 ) *Router {
@@ -40,42 +55,123 @@ func NewRouter(
 	}
 
 	r := gin.New()
+	// 使 gin.Context 的 Done/Err/Value 回落到 Request.Context()
+	r.ContextWithFallback = true
 
 	// 添加 Zap 日志中间件
 	zapLogger, ok := logger.(interface {
 		GetZapLogger() *zap.Logger
 	})
+	r.Use(requestID.Handle())
 	if ok {
-		r.Use(ginzap.Ginzap(zapLogger.GetZapLogger(), time.RFC3339, true))
+		// 访问日志带上请求 ID，与同一请求的 SQL 日志关联
+		r.Use(ginzap.GinzapWithConfig(zapLogger.GetZapLogger(), &ginzap.Config{
+			TimeFormat:   time.RFC3339,
+			UTC:          true,
+			DefaultLevel: zapcore.InfoLevel,
+			Context: func(c *gin.Context) []zapcore.Field {
+				return []zapcore.Field{zap.String("request_id", c.Writer.Header().Get(middleware.RequestIDHeader))}
+			},
+		}))
 		r.Use(ginzap.RecoveryWithZap(zapLogger.GetZapLogger(), true))
 	} else {
 		logger.Warn("zap logger not available, using default gin logger")
 		r.Use(gin.Logger(), gin.Recovery())
 	}
+	r.Use(readYourWrites.Handle())
 
 	//注册自定义验证函数
 	registerValidator()
 
+	// 健康检查不限流，负载均衡的探测常来自同一地址
+	r.GET("/api/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
 	// 公共路由
 	public := r.Group("/api")
-	public.Use(rateLimiter.Handle(2, 5*time.Second))
+	public.Use(deadlineMiddleware.Handle(), rateLimiter.Handle())
 	{
 		//公共路由
-		public.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{"status": "ok"})
-		})
 		public.POST("/register", userController.Register)
 		public.POST("/login", authController.LoginHandler)
+		public.POST("/email/verify", profileController.VerifyEmail)
+		public.POST("/invitations/accept", inviteController.Accept)
 		// public.POST("/refresh", authController.RefreshHandler)
 
 	}
 	// 需要 JWT 认证的路由
 	auth := r.Group("/api")
-	auth.Use(jwtMiddleware.MiddlewareFunc())
+	auth.Use(deadlineMiddleware.Handle(), jwtMiddleware.MiddlewareFunc(), readYourWrites.HandleUser(), rateLimiter.Handle())
 	{
 		auth.POST("/logout", authController.LogoutHandler)
 		auth.GET("/userinfo", authController.UserInfo)
 		auth.GET("/users/:username", userController.GetUser)
+
+		// 当前用户自助管理
+		auth.GET("/me", profileController.GetMe)
+		auth.PATCH("/me", profileController.UpdateMe)
+		auth.DELETE("/me", profileController.CloseAccount)
+		auth.POST("/me/password", profileController.ChangePassword)
+		auth.POST("/me/email", profileController.ChangeEmail)
+
+		// 组织
+		auth.POST("/orgs", orgController.Create)
+		auth.GET("/orgs", orgController.List)
+		auth.POST("/orgs/:id/switch", orgController.Switch)
+	}
+
+	// 当前组织（租户）内的路由
+	org := auth.Group("/org")
+	org.Use(tenantMiddleware.RequireTenant())
+	{
+		org.GET("/members", orgController.ListMembers)
+	}
+	orgAdmin := auth.Group("/org")
+	orgAdmin.Use(tenantMiddleware.RequireTenant(model.RoleOwner, model.RoleAdmin))
+	{
+		orgAdmin.POST("/members", orgController.AddMember)
+		orgAdmin.PATCH("/members/:user_id", orgController.UpdateMemberRole)
+		orgAdmin.DELETE("/members/:user_id", orgController.RemoveMember)
+
+		orgAdmin.POST("/invitations", inviteController.Create)
+		orgAdmin.GET("/invitations", inviteController.List)
+		orgAdmin.POST("/invitations/:id/resend", inviteController.Resend)
+		orgAdmin.DELETE("/invitations/:id", inviteController.Revoke)
+
+		registerWebhookRoutes(orgAdmin, webhookController)
+	}
+	// 系统管理员路由
+	admin := r.Group("/api/admin")
+	admin.Use(deadlineMiddleware.Handle(), jwtMiddleware.MiddlewareFunc(), readYourWrites.HandleUser(), adminMiddleware.Handle(), rateLimiter.Handle())
+	{
+		admin.POST("/users/import", importController.Import)
+		admin.GET("/users/import/:id", importController.GetJob)
+
+		// 应用级 webhook，接收全部用户的事件
+		registerWebhookRoutes(admin, webhookController)
+
+		// 后台任务队列
+		admin.GET("/queues", queueController.Stats)
+		admin.GET("/queues/:queue/dead", queueController.ListDead)
+		admin.POST("/queues/:queue/dead/:id/retry", queueController.RetryDead)
+		admin.DELETE("/queues/:queue/dead/:id", queueController.DeleteDead)
+
+		// 定时任务
+		admin.GET("/cron/jobs", cronController.List)
+		admin.GET("/cron/jobs/:name/runs", cronController.ListRuns)
+		admin.POST("/cron/jobs/:name/trigger", cronController.Trigger)
+		admin.POST("/cron/jobs/:name/pause", cronController.Pause)
+		admin.POST("/cron/jobs/:name/resume", cronController.Resume)
+
+		// Redis 可用状态和降级统计
+		admin.GET("/redis/health", redisController.Health)
+	}
+	// 流式导出耗时与数据量相关，不设截止时间，客户端断开时仍会取消
+	adminStream := r.Group("/api/admin")
+	adminStream.Use(jwtMiddleware.MiddlewareFunc(), readYourWrites.HandleUser(), adminMiddleware.Handle(), rateLimiter.Handle())
+	{
+		adminStream.GET("/users/export", importController.Export)
 	}
 	return &Router{
 		Engine: r,
@@ -83,3 +179,15 @@ func NewRouter(
 		Logger: logger,
 	}
 }
+
+// registerWebhookRoutes 注册 webhook 管理接口，组织和应用级共用
+func registerWebhookRoutes(g *gin.RouterGroup, webhookController *controller.WebhookController) {
+	g.POST("/webhooks", webhookController.Create)
+	g.GET("/webhooks", webhookController.List)
+	g.GET("/webhooks/:id", webhookController.Get)
+	g.PATCH("/webhooks/:id", webhookController.Update)
+	g.DELETE("/webhooks/:id", webhookController.Delete)
+	g.GET("/webhooks/:id/deliveries", webhookController.ListDeliveries)
+	g.GET("/webhooks/:id/deliveries/:delivery_id", webhookController.GetDelivery)
+	g.POST("/webhooks/:id/deliveries/:delivery_id/replay", webhookController.Replay)
+}
@@ -11,6 +11,7 @@ func registerValidator() {
 	// 注册自定义验证器
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("mobile", mobileValidator)
+		v.RegisterValidation("slug", slugValidator)
 	}
 }
 
@@ -20,3 +21,9 @@ func mobileValidator(fl validator.FieldLevel) bool {
 	reg := `^1[3-9]\d{9}$`
 	return regexp.MustCompile(reg).MatchString(fl.Field().String())
 }
+
+// 组织标识：小写字母、数字与中划线，不能以中划线开头或结尾
+func slugValidator(fl validator.FieldLevel) bool {
+	reg := `^[a-z0-9]+(-[a-z0-9]+)*$`
+	return regexp.MustCompile(reg).MatchString(fl.Field().String())
+}
@@ -0,0 +1,53 @@
+// internal/service/audit_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// AuditService 记录审计事件。写入失败只记日志，不影响业务流程
+type AuditService interface {
+	Record(ctx context.Context, actorID uint, action, targetType string, targetID uint, metadata map[string]interface{})
+}
+
+type AuditServiceImpl struct {
+	auditRepo repository.AuditRepository
+	logger    logger.Logger
+}
+
+func NewAuditService(
+	auditRepo repository.AuditRepository,
+	logger logger.Logger,
+) *AuditServiceImpl {
+	return &AuditServiceImpl{
+		auditRepo: auditRepo,
+		logger:    logger.With(zap.String("module", "audit_service")),
+	}
+}
+
+func (s *AuditServiceImpl) Record(ctx context.Context, actorID uint, action, targetType string, targetID uint, metadata map[string]interface{}) {
+	event := &model.AuditEvent{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if len(metadata) > 0 {
+		if encoded, err := json.Marshal(metadata); err == nil {
+			event.Metadata = string(encoded)
+		}
+	}
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		s.logger.Error("record audit event failed",
+			zap.String("action", action),
+			zap.Uint("actor_id", actorID),
+			zap.Error(err))
+	}
+}
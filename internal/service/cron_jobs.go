@@ -0,0 +1,118 @@
+// internal/service/cron_jobs.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const purgeBatchSize = 500
+
+// PurgeDeletedUsersJob 彻底删除注销超过 cron.purge_deleted_users_after 的用户
+type PurgeDeletedUsersJob struct {
+	userRepo repository.UserRepository
+	after    time.Duration
+	logger   logger.Logger
+}
+
+func NewPurgeDeletedUsersJob(userRepo repository.UserRepository, config *config.Config, logger logger.Logger) *PurgeDeletedUsersJob {
+	return &PurgeDeletedUsersJob{
+		userRepo: userRepo,
+		after:    config.Cron.PurgeDeletedUsersAfter,
+		logger:   logger.With(zap.String("module", "purge_deleted_users")),
+	}
+}
+
+func (j *PurgeDeletedUsersJob) Name() string     { return "purge_deleted_users" }
+func (j *PurgeDeletedUsersJob) Schedule() string { return "30 3 * * *" }
+
+func (j *PurgeDeletedUsersJob) Run(ctx context.Context) error {
+	before := time.Now().Add(-j.after)
+	total := 0
+	for {
+		n, err := j.userRepo.PurgeDeleted(ctx, before, purgeBatchSize)
+		total += n
+		if err != nil {
+			return err
+		}
+		if n < purgeBatchSize {
+			break
+		}
+	}
+	j.logger.Info("deleted users purged", zap.Int("count", total))
+	return nil
+}
+
+// ExpireInvitationsJob 清理已过期且未被接受的邀请
+type ExpireInvitationsJob struct {
+	invitationRepo repository.InvitationRepository
+	logger         logger.Logger
+}
+
+func NewExpireInvitationsJob(invitationRepo repository.InvitationRepository, logger logger.Logger) *ExpireInvitationsJob {
+	return &ExpireInvitationsJob{
+		invitationRepo: invitationRepo,
+		logger:         logger.With(zap.String("module", "expire_invitations")),
+	}
+}
+
+func (j *ExpireInvitationsJob) Name() string     { return "expire_invitations" }
+func (j *ExpireInvitationsJob) Schedule() string { return "0 * * * *" }
+
+func (j *ExpireInvitationsJob) Run(ctx context.Context) error {
+	n, err := j.invitationRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	j.logger.Info("expired invitations removed", zap.Int64("count", n))
+	return nil
+}
+
+// ReencryptPIIJob 将仍使用旧密钥的邮箱用当前密钥重新加密，密钥轮换后无需手动执行 `server pii reencrypt`
+type ReencryptPIIJob struct {
+	userRepo repository.UserRepository
+	logger   logger.Logger
+}
+
+func NewReencryptPIIJob(userRepo repository.UserRepository, logger logger.Logger) *ReencryptPIIJob {
+	return &ReencryptPIIJob{
+		userRepo: userRepo,
+		logger:   logger.With(zap.String("module", "reencrypt_pii")),
+	}
+}
+
+func (j *ReencryptPIIJob) Name() string     { return "reencrypt_pii" }
+func (j *ReencryptPIIJob) Schedule() string { return "0 4 * * *" }
+
+func (j *ReencryptPIIJob) Run(ctx context.Context) error {
+	n, err := j.userRepo.ReencryptEmails(ctx, purgeBatchSize)
+	if err != nil {
+		return err
+	}
+	j.logger.Info("user emails re-encrypted", zap.Int("count", n))
+	return nil
+}
+
+// PruneCronRunsJob 删除超过 cron.history_retention 的运行记录
+type PruneCronRunsJob struct {
+	cronRepo  repository.CronRepository
+	retention time.Duration
+}
+
+func NewPruneCronRunsJob(cronRepo repository.CronRepository, config *config.Config) *PruneCronRunsJob {
+	return &PruneCronRunsJob{cronRepo: cronRepo, retention: config.Cron.HistoryRetention}
+}
+
+func (j *PruneCronRunsJob) Name() string     { return "prune_cron_runs" }
+func (j *PruneCronRunsJob) Schedule() string { return "0 5 * * *" }
+
+func (j *PruneCronRunsJob) Run(ctx context.Context) error {
+	_, err := j.cronRepo.DeleteRunsBefore(ctx, time.Now().Add(-j.retention))
+	return err
+}
@@ -0,0 +1,424 @@
+// internal/service/cron_scheduler.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/cron"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/logger"
+	redisx "gin-wire-demo/pkg/redis"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCronJobNotFound    = errors.New("定时任务不存在")
+	ErrCronJobRunning     = errors.New("定时任务正在运行")
+	ErrSchedulerNotActive = errors.New("定时任务调度器未运行")
+)
+
+const (
+	// tickLease 每次触发的租约时长，需大于实例间的时钟偏差
+	tickLease = 5 * time.Minute
+	// runningLockTTL 运行锁的有效期，运行期间自动续期，实例崩溃后锁在该时长内过期
+	runningLockTTL = 30 * time.Second
+	cronOpTimeout  = 5 * time.Second
+	maxCronRuns    = 200
+)
+
+// CronJob 定时任务，由 Wire provider 创建后在 cmd/server/app.go 的 NewCronJobs 中注册
+type CronJob interface {
+	Name() string
+	// Schedule 默认的 cron 表达式，可通过 cron.jobs.<name>.schedule 覆盖
+	Schedule() string
+	Run(ctx context.Context) error
+}
+
+// CronJobInfo 定时任务的定义及当前状态
+type CronJobInfo struct {
+	Name      string
+	Schedule  string
+	Overlap   string
+	Timeout   time.Duration
+	Paused    bool
+	NextRunAt *time.Time
+	LastRun   *model.CronRun
+}
+
+type CronService interface {
+	ListJobs(ctx context.Context) ([]CronJobInfo, error)
+	ListRuns(ctx context.Context, name string, limit int) ([]model.CronRun, error)
+	Trigger(ctx context.Context, name string, actorID uint) (*model.CronRun, error)
+	SetPaused(ctx context.Context, name string, paused bool, actorID uint) error
+}
+
+type scheduledJob struct {
+	CronJob
+	spec     string
+	schedule cron.Schedule
+	overlap  string
+	timeout  time.Duration
+}
+
+// CronScheduler 在每个实例上按表达式计算触发时间，通过 Redis 租约保证每次触发只有一个实例执行。
+// 重叠策略为 skip 的任务另持有运行锁，上一次运行未结束时跳过本次触发
+type CronScheduler struct {
+	jobs     map[string]*scheduledJob
+	names    []string
+	cronRepo repository.CronRepository
+	rdb      redis.UniversalClient
+	locker   *redisx.Locker
+	app      string
+	instance string
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	runCtx  context.Context // Run 期间有效，任务运行在该上下文下
+	running sync.WaitGroup
+}
+
+func NewCronScheduler(
+	jobs []CronJob,
+	cronRepo repository.CronRepository,
+	rdb redis.UniversalClient,
+	locker *redisx.Locker,
+	cfg *config.Config,
+	logger logger.Logger,
+) (*CronScheduler, error) {
+	loc := time.Local
+	if cfg.Cron.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Cron.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	s := &CronScheduler{
+		jobs:     make(map[string]*scheduledJob, len(jobs)),
+		cronRepo: cronRepo,
+		rdb:      rdb,
+		locker:   locker,
+		app:      cfg.App.Name,
+		instance: instanceName(),
+		logger:   logger.With(zap.String("module", "cron_scheduler")),
+	}
+	for _, job := range jobs {
+		name := job.Name()
+		if _, ok := s.jobs[name]; ok {
+			return nil, fmt.Errorf("duplicate cron job %q", name)
+		}
+		override := cfg.Cron.Jobs[name]
+		j := &scheduledJob{
+			CronJob: job,
+			spec:    job.Schedule(),
+			overlap: config.CronOverlapSkip,
+			timeout: cfg.Cron.Timeout,
+		}
+		if override.Schedule != "" {
+			j.spec = override.Schedule
+		}
+		if override.Overlap != "" {
+			j.overlap = override.Overlap
+		}
+		if override.Timeout > 0 {
+			j.timeout = override.Timeout
+		}
+		schedule, err := cron.Parse(j.spec, loc)
+		if err != nil {
+			return nil, fmt.Errorf("cron job %q: %w", name, err)
+		}
+		j.schedule = schedule
+		s.jobs[name] = j
+		s.names = append(s.names, name)
+	}
+	for name := range cfg.Cron.Jobs {
+		if _, ok := s.jobs[name]; !ok {
+			return nil, fmt.Errorf("unknown cron job %q in config", name)
+		}
+	}
+	sort.Strings(s.names)
+	return s, nil
+}
+
+// Run 按计划触发任务直到 ctx 取消，返回前等待本实例上运行中的任务退出
+func (s *CronScheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.mu.Unlock()
+
+	var loops sync.WaitGroup
+	for _, name := range s.names {
+		loops.Add(1)
+		go func(j *scheduledJob) {
+			defer loops.Done()
+			s.loop(ctx, j)
+		}(s.jobs[name])
+	}
+	loops.Wait()
+
+	s.mu.Lock()
+	s.runCtx = nil
+	s.mu.Unlock()
+	s.running.Wait()
+}
+
+func (s *CronScheduler) loop(ctx context.Context, j *scheduledJob) {
+	next := j.schedule.Next(time.Now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.tick(ctx, j, next)
+
+		now := time.Now()
+		if now.Before(next) {
+			now = next
+		}
+		next = j.schedule.Next(now)
+	}
+	s.logger.Warn("cron job will never run again", zap.String("job", j.Name()), zap.String("schedule", j.spec))
+}
+
+// tick 处理一次计划触发：暂停的任务不执行，各实例争抢该触发时间的租约，抢到的实例执行
+func (s *CronScheduler) tick(ctx context.Context, j *scheduledJob, scheduledAt time.Time) {
+	log := s.logger.With(zap.String("job", j.Name()), zap.Time("scheduled_at", scheduledAt))
+
+	// 暂停状态刚在主库修改时，从库可能尚未同步
+	paused, err := s.cronRepo.IsPaused(db.UsePrimary(ctx), j.Name())
+	if err != nil {
+		log.Error("check cron job state failed", zap.Error(err))
+		return
+	}
+	if paused {
+		log.Debug("cron job paused, skipped")
+		return
+	}
+
+	key := fmt.Sprintf("cron:%s:%s:tick:%d", s.app, j.Name(), scheduledAt.Unix())
+	acquired, err := s.rdb.SetNX(ctx, key, s.instance, tickLease).Result()
+	if err != nil {
+		log.Error("acquire cron tick lease failed", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+	if _, err := s.start(ctx, j, scheduledAt, model.CronTriggerSchedule, nil); err != nil {
+		if errors.Is(err, ErrCronJobRunning) {
+			log.Info("previous run still in progress, skipped")
+			return
+		}
+		log.Error("start cron job failed", zap.Error(err))
+	}
+}
+
+// start 记录运行并在后台执行。重叠策略为 skip 且已有运行时记录为 skipped 并返回 ErrCronJobRunning
+func (s *CronScheduler) start(ctx context.Context, j *scheduledJob, scheduledAt time.Time, trigger string, actorID *uint) (*model.CronRun, error) {
+	// 在锁内登记，保证停机时 Run 等待到这次运行
+	s.mu.Lock()
+	runCtx := s.runCtx
+	if runCtx != nil {
+		s.running.Add(1)
+	}
+	s.mu.Unlock()
+	if runCtx == nil {
+		return nil, ErrSchedulerNotActive
+	}
+	started := false
+	defer func() {
+		if !started {
+			s.running.Done()
+		}
+	}()
+
+	run := &model.CronRun{
+		JobName:     j.Name(),
+		Trigger:     trigger,
+		TriggeredBy: actorID,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+		Instance:    s.instance,
+	}
+
+	var lock *redisx.Lock
+	if j.overlap == config.CronOverlapSkip {
+		var err error
+		lock, err = s.locker.Obtain(ctx, "cron:"+j.Name(), runningLockTTL, redisx.AutoExtend())
+		if errors.Is(err, redisx.ErrNotObtained) {
+			run.Status = model.CronRunSkipped
+			run.FinishedAt = &run.StartedAt
+			if err := s.cronRepo.CreateRun(ctx, run); err != nil {
+				return nil, err
+			}
+			return run, ErrCronJobRunning
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	run.Status = model.CronRunRunning
+	if err := s.cronRepo.CreateRun(ctx, run); err != nil {
+		s.releaseRunning(j, lock)
+		return nil, err
+	}
+	started = true
+	go func() {
+		defer s.running.Done()
+		s.execute(runCtx, j, run, lock)
+	}()
+	return run, nil
+}
+
+func (s *CronScheduler) execute(ctx context.Context, j *scheduledJob, run *model.CronRun, lock *redisx.Lock) {
+	log := s.logger.With(zap.String("job", j.Name()), zap.Uint("run_id", run.ID))
+	defer s.releaseRunning(j, lock)
+
+	runCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	if lock != nil {
+		// 运行锁丢失后其他实例可能开始新的运行，中止本次运行
+		go func() {
+			select {
+			case <-lock.Lost():
+				log.Warn("cron lock lost, cancelling run")
+				cancel()
+			case <-runCtx.Done():
+			}
+		}()
+	}
+	err := s.call(runCtx, j)
+	cancel()
+
+	finished := time.Now()
+	fields := map[string]interface{}{
+		"status":      model.CronRunSucceeded,
+		"finished_at": finished,
+		"duration_ms": finished.Sub(run.StartedAt).Milliseconds(),
+	}
+	if err != nil {
+		fields["status"] = model.CronRunFailed
+		fields["error"] = truncate(err.Error(), maxLastErrorLen)
+		log.Error("cron job failed", zap.Error(err))
+	} else {
+		log.Info("cron job succeeded", zap.Duration("duration", finished.Sub(run.StartedAt)))
+	}
+
+	// 停机时 ctx 已取消，使用独立上下文写入结果
+	opCtx, opCancel := context.WithTimeout(context.Background(), cronOpTimeout)
+	defer opCancel()
+	if err := s.cronRepo.UpdateRun(opCtx, run.ID, fields); err != nil {
+		log.Error("update cron run failed", zap.Error(err))
+	}
+}
+
+// call 执行任务，panic 视为失败
+func (s *CronScheduler) call(ctx context.Context, j *scheduledJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("cron job panicked", zap.String("job", j.Name()), zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.Run(ctx)
+}
+
+// releaseRunning 释放运行锁，重叠策略为 allow 时 lock 为 nil
+func (s *CronScheduler) releaseRunning(j *scheduledJob, lock *redisx.Lock) {
+	if lock == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cronOpTimeout)
+	defer cancel()
+	if err := lock.Release(ctx); err != nil {
+		s.logger.Error("release cron lock failed", zap.String("job", j.Name()), zap.Error(err))
+	}
+}
+
+// ListJobs 列出全部任务及其暂停状态、下次触发时间和最近一次运行
+func (s *CronScheduler) ListJobs(ctx context.Context) ([]CronJobInfo, error) {
+	states, err := s.cronRepo.ListStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paused := make(map[string]bool, len(states))
+	for _, state := range states {
+		paused[state.Name] = state.Paused
+	}
+
+	now := time.Now()
+	infos := make([]CronJobInfo, 0, len(s.names))
+	for _, name := range s.names {
+		j := s.jobs[name]
+		info := CronJobInfo{
+			Name:     name,
+			Schedule: j.spec,
+			Overlap:  j.overlap,
+			Timeout:  j.timeout,
+			Paused:   paused[name],
+		}
+		if next := j.schedule.Next(now); !next.IsZero() && !info.Paused {
+			info.NextRunAt = &next
+		}
+		runs, err := s.cronRepo.ListRuns(ctx, name, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 {
+			info.LastRun = &runs[0]
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListRuns 任务的运行记录，按开始时间倒序
+func (s *CronScheduler) ListRuns(ctx context.Context, name string, limit int) ([]model.CronRun, error) {
+	if _, ok := s.jobs[name]; !ok {
+		return nil, ErrCronJobNotFound
+	}
+	if limit <= 0 || limit > maxCronRuns {
+		limit = maxCronRuns
+	}
+	return s.cronRepo.ListRuns(ctx, name, limit)
+}
+
+// Trigger 立即在本实例上运行一次，暂停的任务也可手动触发，重叠策略同样生效
+func (s *CronScheduler) Trigger(ctx context.Context, name string, actorID uint) (*model.CronRun, error) {
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrCronJobNotFound
+	}
+	return s.start(ctx, j, time.Now(), model.CronTriggerManual, &actorID)
+}
+
+// SetPaused 暂停或恢复任务的计划触发，对全部实例生效
+func (s *CronScheduler) SetPaused(ctx context.Context, name string, paused bool, actorID uint) error {
+	if _, ok := s.jobs[name]; !ok {
+		return ErrCronJobNotFound
+	}
+	return s.cronRepo.SetPaused(ctx, name, paused, actorID)
+}
+
+// instanceName 主机名加进程号，用于运行记录和租约
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
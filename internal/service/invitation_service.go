@@ -0,0 +1,313 @@
+// internal/service/invitation_service.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/internal/tenant"
+	"gin-wire-demo/pkg/mailer"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidInvitation = errors.New("邀请链接无效或已过期")
+	ErrInvitationPending = errors.New("该邮箱已有待处理的邀请")
+	ErrUsernameRequired  = errors.New("创建账户需要提供用户名")
+	ErrWeakPassword      = errors.New("密码长度至少8位")
+)
+
+// 审计事件
+const (
+	AuditInvitationCreated  = "invitation.created"
+	AuditInvitationResent   = "invitation.resent"
+	AuditInvitationRevoked  = "invitation.revoked"
+	AuditInvitationAccepted = "invitation.accepted"
+)
+
+type invitationTokenClaims struct {
+	InvitationID uint   `json:"iid"`
+	TenantID     uint   `json:"tid"`
+	Nonce        string `json:"n"`
+	Expire       int64  `json:"exp"`
+}
+
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, inviterID uint, email, role string) (*model.Invitation, error)
+	ListPending(ctx context.Context) ([]model.Invitation, error)
+	ResendInvitation(ctx context.Context, actorID, id uint) (*model.Invitation, error)
+	RevokeInvitation(ctx context.Context, actorID, id uint) error
+	AcceptInvitation(ctx context.Context, token, username, password string) (*model.Membership, error)
+}
+
+type InvitationServiceImpl struct {
+	inviteRepo   repository.InvitationRepository
+	orgRepo      repository.OrganizationRepository
+	userRepo     repository.UserRepository
+	userService  UserService
+	auditService AuditService
+	txManager    repository.TxManager
+	mailer       mailer.Mailer
+	config       *config.Config
+}
+
+func NewInvitationService(
+	inviteRepo repository.InvitationRepository,
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	userService UserService,
+	auditService AuditService,
+	txManager repository.TxManager,
+	mailer mailer.Mailer,
+	config *config.Config,
+) *InvitationServiceImpl {
+	return &InvitationServiceImpl{
+		inviteRepo:   inviteRepo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		userService:  userService,
+		auditService: auditService,
+		txManager:    txManager,
+		mailer:       mailer,
+		config:       config,
+	}
+}
+
+// CreateInvitation 邀请邮箱加入当前租户
+func (s *InvitationServiceImpl) CreateInvitation(ctx context.Context, inviterID uint, email, role string) (*model.Invitation, error) {
+	if _, err := s.inviteRepo.FindPendingByEmail(ctx, email); err == nil {
+		return nil, ErrInvitationPending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user, err := s.userRepo.FindByEmail(ctx, email); err == nil {
+		if _, err := s.orgRepo.FindMembership(ctx, user.ID); err == nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	nonce, nonceHash, err := newInvitationNonce()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &model.Invitation{
+		Email:     email,
+		Role:      role,
+		InvitedBy: inviterID,
+		NonceHash: nonceHash,
+		ExpiresAt: time.Now().Add(s.config.Invite.TTL),
+	}
+	if err := s.inviteRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+	if err := s.sendInvitation(ctx, invitation, nonce); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(ctx, inviterID, AuditInvitationCreated, "invitation", invitation.ID, map[string]interface{}{
+		"email": email,
+		"role":  role,
+	})
+	return invitation, nil
+}
+
+// ListPending 列出当前租户待处理的邀请
+func (s *InvitationServiceImpl) ListPending(ctx context.Context) ([]model.Invitation, error) {
+	return s.inviteRepo.ListPending(ctx)
+}
+
+// ResendInvitation 轮换邀请令牌并延长有效期，旧链接随即失效
+func (s *InvitationServiceImpl) ResendInvitation(ctx context.Context, actorID, id uint) (*model.Invitation, error) {
+	invitation, err := s.findOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	nonce, nonceHash, err := newInvitationNonce()
+	if err != nil {
+		return nil, err
+	}
+	invitation.NonceHash = nonceHash
+	invitation.ExpiresAt = time.Now().Add(s.config.Invite.TTL)
+	if err := s.inviteRepo.Updates(ctx, invitation.ID, map[string]interface{}{
+		"nonce_hash": invitation.NonceHash,
+		"expires_at": invitation.ExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.sendInvitation(ctx, invitation, nonce); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(ctx, actorID, AuditInvitationResent, "invitation", invitation.ID, map[string]interface{}{
+		"email": invitation.Email,
+	})
+	return invitation, nil
+}
+
+// RevokeInvitation 撤销邀请
+func (s *InvitationServiceImpl) RevokeInvitation(ctx context.Context, actorID, id uint) error {
+	invitation, err := s.findOpen(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.inviteRepo.Updates(ctx, invitation.ID, map[string]interface{}{"revoked_at": time.Now()}); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, actorID, AuditInvitationRevoked, "invitation", invitation.ID, map[string]interface{}{
+		"email": invitation.Email,
+	})
+	return nil
+}
+
+// AcceptInvitation 接受邀请：邮箱已注册时校验该账户密码并加入组织，
+// 否则通过 UserService.CreateUser 创建账户后加入
+func (s *InvitationServiceImpl) AcceptInvitation(ctx context.Context, token, username, password string) (*model.Membership, error) {
+	var claims invitationTokenClaims
+	if err := parseToken(s.config.JWT.SigningKey, "invitation", token, &claims); err != nil {
+		return nil, ErrInvalidInvitation
+	}
+	if time.Now().Unix() > claims.Expire {
+		return nil, ErrInvalidInvitation
+	}
+	ctx = tenant.WithTenant(ctx, claims.TenantID)
+
+	invitation, err := s.inviteRepo.FindByID(ctx, claims.InvitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if !invitation.IsPending(time.Now()) || !nonceMatches(claims.Nonce, invitation.NonceHash) {
+		return nil, ErrInvalidInvitation
+	}
+
+	// 创建账户、占用邀请、加入组织和审计记录在同一事务中完成
+	var membership *model.Membership
+	err = s.txManager.Transaction(ctx, func(ctx context.Context) error {
+		user, created, err := s.resolveInvitee(ctx, invitation.Email, username, password)
+		if err != nil {
+			return err
+		}
+		if _, err := s.orgRepo.FindMembership(ctx, user.ID); err == nil {
+			return ErrAlreadyMember
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 先抢占邀请，保证并发接受时只有一个成功
+		claimed, err := s.inviteRepo.MarkAccepted(ctx, invitation.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidInvitation
+		}
+		membership = &model.Membership{UserID: user.ID, Role: invitation.Role}
+		if err := s.orgRepo.CreateMembership(ctx, membership); err != nil {
+			return err
+		}
+		membership.User = user
+
+		s.auditService.Record(ctx, user.ID, AuditInvitationAccepted, "invitation", invitation.ID, map[string]interface{}{
+			"email":       invitation.Email,
+			"role":        invitation.Role,
+			"new_account": created,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// resolveInvitee 找到或创建受邀用户，第二个返回值表示是否新建了账户
+func (s *InvitationServiceImpl) resolveInvitee(ctx context.Context, email, username, password string) (*model.User, bool, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err == nil {
+		user, err = s.userService.VerifyPassword(ctx, user.ID, password)
+		if err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if username == "" {
+		return nil, false, ErrUsernameRequired
+	}
+	if len(password) < 8 {
+		return nil, false, ErrWeakPassword
+	}
+	user = &model.User{
+		Username: username,
+		Password: password,
+		Email:    email,
+	}
+	if err := s.userService.CreateUser(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+func (s *InvitationServiceImpl) findOpen(ctx context.Context, id uint) (*model.Invitation, error) {
+	invitation, err := s.inviteRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvalidInvitation
+	}
+	return invitation, nil
+}
+
+func (s *InvitationServiceImpl) sendInvitation(ctx context.Context, invitation *model.Invitation, nonce string) error {
+	token, err := signToken(s.config.JWT.SigningKey, "invitation", invitationTokenClaims{
+		InvitationID: invitation.ID,
+		TenantID:     invitation.TenantID,
+		Nonce:        nonce,
+		Expire:       invitation.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	orgName := ""
+	if org, err := s.orgRepo.FindByID(ctx, invitation.TenantID); err == nil {
+		orgName = org.Name
+	}
+	body := fmt.Sprintf("You have been invited to join %s. Use the following token to accept the invitation before %s: %s",
+		orgName, invitation.ExpiresAt.Format(time.RFC3339), token)
+	return s.mailer.Send(invitation.Email, "You're invited", body)
+}
+
+func newInvitationNonce() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	nonce := hex.EncodeToString(buf)
+	return nonce, hashNonce(nonce), nil
+}
+
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func nonceMatches(nonce, nonceHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashNonce(nonce)), []byte(nonceHash)) == 1
+}
@@ -0,0 +1,126 @@
+// internal/service/organization_service.go
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotMember      = errors.New("不是该组织成员")
+	ErrAlreadyMember  = errors.New("用户已是该组织成员")
+	ErrSlugTaken      = errors.New("组织标识已被使用")
+	ErrOwnerImmutable = errors.New("不能修改或移除组织所有者")
+)
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, ownerID uint, name, slug string) (*model.Organization, error)
+	ListUserOrganizations(ctx context.Context, userID uint) ([]model.Membership, error)
+	GetMembership(ctx context.Context, orgID, userID uint) (*model.Membership, error)
+	ListMembers(ctx context.Context) ([]model.Membership, error)
+	AddMember(ctx context.Context, username, role string) (*model.Membership, error)
+	UpdateMemberRole(ctx context.Context, userID uint, role string) error
+	RemoveMember(ctx context.Context, userID uint) error
+}
+
+type OrganizationServiceImpl struct {
+	orgRepo  repository.OrganizationRepository
+	userRepo repository.UserRepository
+}
+
+func NewOrganizationService(
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+) *OrganizationServiceImpl {
+	return &OrganizationServiceImpl{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *OrganizationServiceImpl) CreateOrganization(ctx context.Context, ownerID uint, name, slug string) (*model.Organization, error) {
+	if _, err := s.orgRepo.FindBySlug(ctx, slug); err == nil {
+		return nil, ErrSlugTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	org := &model.Organization{Name: name, Slug: slug}
+	if err := s.orgRepo.CreateWithOwner(ctx, org, ownerID); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationServiceImpl) ListUserOrganizations(ctx context.Context, userID uint) ([]model.Membership, error) {
+	return s.orgRepo.ListUserMemberships(ctx, userID)
+}
+
+// GetMembership 查询用户在指定组织中的成员关系，登录选择组织和切换组织时使用
+func (s *OrganizationServiceImpl) GetMembership(ctx context.Context, orgID, userID uint) (*model.Membership, error) {
+	membership, err := s.orgRepo.FindMembership(tenant.WithTenant(ctx, orgID), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+// ListMembers 列出当前租户的成员
+func (s *OrganizationServiceImpl) ListMembers(ctx context.Context) ([]model.Membership, error) {
+	return s.orgRepo.ListMemberships(ctx)
+}
+
+// AddMember 将已注册用户加入当前租户
+func (s *OrganizationServiceImpl) AddMember(ctx context.Context, username, role string) (*model.Membership, error) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.FindMembership(ctx, user.ID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	membership := &model.Membership{UserID: user.ID, Role: role}
+	if err := s.orgRepo.CreateMembership(ctx, membership); err != nil {
+		return nil, err
+	}
+	membership.User = user
+	return membership, nil
+}
+
+func (s *OrganizationServiceImpl) UpdateMemberRole(ctx context.Context, userID uint, role string) error {
+	if err := s.ensureMutable(ctx, userID); err != nil {
+		return err
+	}
+	return s.orgRepo.UpdateMembershipRole(ctx, userID, role)
+}
+
+func (s *OrganizationServiceImpl) RemoveMember(ctx context.Context, userID uint) error {
+	if err := s.ensureMutable(ctx, userID); err != nil {
+		return err
+	}
+	return s.orgRepo.DeleteMembership(ctx, userID)
+}
+
+func (s *OrganizationServiceImpl) ensureMutable(ctx context.Context, userID uint) error {
+	membership, err := s.orgRepo.FindMembership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if membership.Role == model.RoleOwner {
+		return ErrOwnerImmutable
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// internal/service/outbox_relay.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/event"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/broker"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const maxLastErrorLen = 1024
+
+// recordEvents 将领域事件写入 outbox，必须在产生事件的业务写操作所在的事务中调用，
+// 保证事件与数据同时提交或回滚
+func recordEvents(ctx context.Context, outboxRepo repository.OutboxRepository, events ...event.Event) error {
+	now := time.Now()
+	records := make([]*model.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		record, err := e.ToOutbox(now)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	return outboxRepo.Add(ctx, records...)
+}
+
+// OutboxRelay 后台轮询 outbox 表，发布到消息代理并分发给订阅的 webhook。
+// 两者都成功后才删除记录，因此至少投递一次，消费方需按事件 ID 去重
+type OutboxRelay struct {
+	outboxRepo repository.OutboxRepository
+	broker     broker.Broker
+	webhooks   WebhookService
+	config     config.EventsConfig
+	logger     logger.Logger
+}
+
+func NewOutboxRelay(
+	outboxRepo repository.OutboxRepository,
+	broker broker.Broker,
+	webhooks WebhookService,
+	config *config.Config,
+	logger logger.Logger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		broker:     broker,
+		webhooks:   webhooks,
+		config:     config.Events,
+		logger:     logger.With(zap.String("module", "outbox_relay")),
+	}
+}
+
+// Run 持续中继直到 ctx 取消
+func (r *OutboxRelay) Run(ctx context.Context) {
+	runPoller(ctx, r.config.PollInterval, r.config.BatchSize, r.logger, r.RelayOnce)
+}
+
+// RelayOnce 领取并发布一批到期事件，返回领取的事件数
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	// outbox 刚在主库写入，从库可能尚未同步
+	ctx = db.UsePrimary(ctx)
+	events, err := r.outboxRepo.ClaimDue(ctx, time.Now(), r.config.BatchSize, r.config.PublishLease)
+	if err != nil {
+		return len(events), err
+	}
+	for _, e := range events {
+		if ctx.Err() != nil {
+			// 未处理的事件在占用到期后重新发布
+			return len(events), ctx.Err()
+		}
+		r.relay(ctx, e)
+	}
+	return len(events), nil
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, e *model.OutboxEvent) {
+	log := r.logger.With(
+		zap.String("event_id", e.EventID),
+		zap.String("event_type", e.Type),
+		zap.Int("attempts", e.Attempts))
+
+	msg := broker.Message{
+		ID:            e.EventID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		Payload:       []byte(e.Payload),
+		OccurredAt:    e.CreatedAt,
+	}
+	err := r.broker.Publish(ctx, msg)
+	if err == nil {
+		err = r.webhooks.Enqueue(ctx, msg)
+	}
+	if err == nil {
+		// 删除失败时事件会在占用到期后再次发布
+		if err := r.outboxRepo.Delete(ctx, e.ID); err != nil {
+			log.Warn("delete published event failed", zap.Error(err))
+		}
+		return
+	}
+
+	e.Attempts++
+	lastErr := truncate(err.Error(), maxLastErrorLen)
+	if e.Attempts >= r.config.MaxAttempts {
+		if err := r.outboxRepo.MoveToDeadLetter(ctx, e, lastErr); err != nil {
+			log.Error("move event to dead letter failed", zap.Error(err))
+			return
+		}
+		log.Error("event moved to dead letter", zap.String("last_error", lastErr))
+		return
+	}
+
+	next := time.Now().Add(retryDelay(r.config.RetryBackoff, r.config.MaxBackoff, e.Attempts))
+	if err := r.outboxRepo.MarkFailed(ctx, e.ID, e.Attempts, next, lastErr); err != nil {
+		log.Error("reschedule event failed", zap.Error(err))
+		return
+	}
+	log.Warn("publish event failed, will retry", zap.Time("next_attempt_at", next), zap.Error(err))
+}
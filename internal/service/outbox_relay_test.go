@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/event"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/broker"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const testStream = "events"
+
+type relayFixture struct {
+	outboxRepo  *repository.OutboxRepositoryImpl
+	webhookRepo *repository.WebhookRepositoryImpl
+	db          *gorm.DB
+	mr          *miniredis.Miniredis
+	cfg         *config.Config
+	newRelay    func() *OutboxRelay
+}
+
+// newRelayFixture 事件发布到 miniredis 中的 Stream，outbox 和 webhook 投递使用 SQLite
+func newRelayFixture(t *testing.T, maxAttempts int) *relayFixture {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.Events = config.EventsConfig{
+		Broker:       config.BrokerRedis,
+		Stream:       testStream,
+		PollInterval: time.Second,
+		BatchSize:    50,
+		MaxAttempts:  maxAttempts,
+		RetryBackoff: time.Minute,
+		MaxBackoff:   time.Hour,
+		PublishLease: time.Minute,
+	}
+	gdb, log := newTestDB(t, cfg)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	outboxRepo := repository.NewOutboxRepository(gdb)
+	webhookRepo := repository.NewWebhookRepository(gdb)
+	webhooks := NewWebhookService(webhookRepo, repository.NewOrganizationRepository(gdb), cfg)
+	return &relayFixture{
+		outboxRepo:  outboxRepo,
+		webhookRepo: webhookRepo,
+		db:          gdb,
+		mr:          mr,
+		cfg:         cfg,
+		// 每次调用相当于一个新的实例，共享数据库和 Redis
+		newRelay: func() *OutboxRelay {
+			b, err := broker.NewBroker(cfg, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return NewOutboxRelay(outboxRepo, b, webhooks, cfg, log)
+		},
+	}
+}
+
+func (f *relayFixture) record(t *testing.T, userIDs ...uint) {
+	t.Helper()
+	for _, id := range userIDs {
+		user := &model.User{Username: "user"}
+		user.ID = id
+		user.CreatedAt = time.Now()
+		if err := recordEvents(context.Background(), f.outboxRepo, event.NewUserRegistered(user)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (f *relayFixture) outbox(t *testing.T) []model.OutboxEvent {
+	t.Helper()
+	var events []model.OutboxEvent
+	if err := f.db.Order("id").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func (f *relayFixture) deadLetters(t *testing.T) []model.DeadLetterEvent {
+	t.Helper()
+	var events []model.DeadLetterEvent
+	if err := f.db.Order("id").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// published 返回 Stream 中消息的事件 ID
+func (f *relayFixture) published(t *testing.T) []string {
+	t.Helper()
+	if !f.mr.Exists(testStream) {
+		return nil
+	}
+	entries, err := f.mr.Stream(testStream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		for j := 0; j+1 < len(entry.Values); j += 2 {
+			if entry.Values[j] == "event_id" {
+				ids[i] = entry.Values[j+1]
+			}
+		}
+	}
+	return ids
+}
+
+// makeDue 跳过占用或退避等待，使全部事件立即到期
+func (f *relayFixture) makeDue(t *testing.T) {
+	t.Helper()
+	if err := f.db.Model(&model.OutboxEvent{}).Where("1 = 1").
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func relayOnce(t *testing.T, relay *OutboxRelay, want int) {
+	t.Helper()
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != want {
+		t.Fatalf("relayed %d events, want %d", n, want)
+	}
+}
+
+func TestOutboxRelayPublishesAndFansOutToWebhooks(t *testing.T) {
+	f := newRelayFixture(t, 3)
+	ctx := context.Background()
+	subscriber := &model.WebhookEndpoint{URL: "https://example.com/all", Secret: "s", EventTypes: model.WebhookAllEvents, Enabled: true}
+	other := &model.WebhookEndpoint{URL: "https://example.com/locked", Secret: "s", EventTypes: event.UserLocked, Enabled: true}
+	for _, endpoint := range []*model.WebhookEndpoint{subscriber, other} {
+		if err := f.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.record(t, 1, 2)
+	recorded := f.outbox(t)
+
+	relay := f.newRelay()
+	relayOnce(t, relay, 2)
+	got := f.published(t)
+	if len(got) != 2 || got[0] != recorded[0].EventID || got[1] != recorded[1].EventID {
+		t.Fatalf("published %v, want events in outbox order", got)
+	}
+	if rows := f.outbox(t); len(rows) != 0 {
+		t.Fatalf("outbox still holds %d events", len(rows))
+	}
+	deliveries, err := f.webhookRepo.ListDeliveries(ctx, 0, subscriber.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 || deliveries[0].Status != model.WebhookDeliveryPending {
+		t.Fatalf("subscriber deliveries = %+v", deliveries)
+	}
+	if deliveries, _ := f.webhookRepo.ListDeliveries(ctx, 0, other.ID, 10); len(deliveries) != 0 {
+		t.Fatalf("unsubscribed endpoint got %d deliveries", len(deliveries))
+	}
+	relayOnce(t, relay, 0)
+}
+
+func TestOutboxRelayClaimLease(t *testing.T) {
+	f := newRelayFixture(t, 3)
+	f.record(t, 1)
+
+	// 另一个实例已领取，占用期内不会再次发布
+	claimed, err := f.outboxRepo.ClaimDue(context.Background(), time.Now(), 10, f.cfg.Events.PublishLease)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claim: %d events, %v", len(claimed), err)
+	}
+	if lease := time.Until(claimed[0].NextAttemptAt); lease < 59*time.Second || lease > time.Minute {
+		t.Fatalf("leased for %v, want 1m", lease)
+	}
+	relay := f.newRelay()
+	relayOnce(t, relay, 0)
+	if got := f.published(t); len(got) != 0 {
+		t.Fatalf("published %v while leased", got)
+	}
+
+	// 领取的实例未完成处理，占用到期后被重新领取
+	f.makeDue(t)
+	relayOnce(t, relay, 1)
+	if got := f.published(t); len(got) != 1 {
+		t.Fatalf("published %v after lease expired", got)
+	}
+}
+
+func TestOutboxRelayConcurrentInstancesPublishOnce(t *testing.T) {
+	f := newRelayFixture(t, 3)
+	const events = 20
+	for i := uint(1); i <= events; i++ {
+		f.record(t, i)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 3; i++ {
+		relay := f.newRelay()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := relay.RelayOnce(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			total += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if total != events {
+		t.Fatalf("instances claimed %d events in total, want %d", total, events)
+	}
+	seen := make(map[string]bool)
+	for _, id := range f.published(t) {
+		if seen[id] {
+			t.Fatalf("event %s published twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != events {
+		t.Fatalf("published %d events, want %d", len(seen), events)
+	}
+}
+
+func TestOutboxRelayRetriesWithBackoff(t *testing.T) {
+	f := newRelayFixture(t, 5)
+	f.record(t, 1)
+	relay := f.newRelay()
+
+	f.mr.SetError("ERR broker unavailable")
+	for attempt := 1; attempt <= 2; attempt++ {
+		start := time.Now()
+		relayOnce(t, relay, 1)
+		rows := f.outbox(t)
+		if len(rows) != 1 || rows[0].Attempts != attempt || !strings.Contains(rows[0].LastError, "broker unavailable") {
+			t.Fatalf("attempt %d: outbox = %+v", attempt, rows)
+		}
+		// 退避时间按尝试次数翻倍
+		wait := rows[0].NextAttemptAt.Sub(start)
+		if want := time.Minute << (attempt - 1); wait < want || wait > want+time.Second {
+			t.Fatalf("attempt %d: next attempt in %v, want %v", attempt, wait, want)
+		}
+		relayOnce(t, relay, 0)
+		f.makeDue(t)
+	}
+
+	f.mr.SetError("")
+	relayOnce(t, relay, 1)
+	if got := f.published(t); len(got) != 1 {
+		t.Fatalf("published %v after recovery", got)
+	}
+	if rows := f.outbox(t); len(rows) != 0 {
+		t.Fatalf("outbox still holds %+v", rows)
+	}
+	if dead := f.deadLetters(t); len(dead) != 0 {
+		t.Fatalf("dead letters = %+v", dead)
+	}
+}
+
+func TestOutboxRelayMovesToDeadLetter(t *testing.T) {
+	f := newRelayFixture(t, 3)
+	f.record(t, 1, 2)
+	recorded := f.outbox(t)
+	relay := f.newRelay()
+
+	f.mr.SetError("ERR broker unavailable")
+	for attempt := 1; attempt <= 3; attempt++ {
+		relayOnce(t, relay, 2)
+		f.makeDue(t)
+	}
+	if rows := f.outbox(t); len(rows) != 0 {
+		t.Fatalf("outbox still holds %d events after max attempts", len(rows))
+	}
+	dead := f.deadLetters(t)
+	if len(dead) != 2 {
+		t.Fatalf("dead letters = %+v", dead)
+	}
+	for i, d := range dead {
+		r := recorded[i]
+		if d.EventID != r.EventID || d.Type != event.UserRegistered || d.Payload != r.Payload ||
+			d.Attempts != 3 || !strings.Contains(d.LastError, "broker unavailable") || !d.OccurredAt.Equal(r.CreatedAt) {
+			t.Fatalf("dead letter %d = %+v, outbox record %+v", i, d, r)
+		}
+	}
+
+	// 死信不再发布
+	f.mr.SetError("")
+	relayOnce(t, relay, 0)
+}
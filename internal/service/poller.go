@@ -0,0 +1,56 @@
+// internal/service/poller.go
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// runPoller 反复调用 once 直到 ctx 取消。一批处理满时立即处理下一批，否则等待 interval
+func runPoller(ctx context.Context, interval time.Duration, batchSize int, log logger.Logger, once func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := once(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("poll failed", zap.Error(err))
+		}
+		if n < batchSize || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// retryDelay 第 attempts 次失败后的等待时间：base * 2^(attempts-1)，不超过 max
+func retryDelay(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// truncate 截断到 n 字节以内且不截断多字节字符，用于写入有长度限制的列
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/migrations"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/fieldcrypt"
+	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func randomKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// newTestConfig 使用 SQLite 内存库的最小配置，各测试按需修改其余字段
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		App:      config.AppConfig{Name: "test"},
+		Database: config.DatabaseConfig{Driver: config.DriverSQLite, DBname: ":memory:"},
+		Log:      config.LogConfig{Level: "error"},
+		PII: config.PIIConfig{
+			ActiveKey: 1,
+			Keys:      []config.PIIKey{{Version: 1, Key: randomKey(t)}},
+			IndexKey:  randomKey(t),
+		},
+	}
+}
+
+// newTestDB 按 cfg 打开 SQLite 内存库并执行内嵌的迁移
+func newTestDB(t *testing.T, cfg *config.Config) (*gorm.DB, logger.Logger) {
+	t.Helper()
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gdb, cleanup, err := db.NewDB(cfg, log, keyring)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	// 内存库只有一个连接，迁移需在同一个 sql.DB 上执行
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := migrations.For(config.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, config.DriverSQLite, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return gdb, log
+}
@@ -0,0 +1,585 @@
+// internal/service/user_import_service.go
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-wire-demo/internal/dto"
+	"gin-wire-demo/internal/event"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 支持的导入导出格式
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	importBatchSize     = 500
+	importMaxRowErrors  = 10000 // 错误报告最多保留的行数
+	exportBatchSize     = 1000
+	ndjsonMaxLineLength = 1 << 20
+	// 上传内容先写入 upload 文件，任务创建后改名为 job 文件，文件名带任务 ID
+	importUploadFilePrefix = "user-import-upload-"
+	importJobFilePrefix    = "user-import-job-"
+	// 执行中的任务按该间隔刷新 updated_at，超过 importStaleAfter 未刷新的任务视为所在实例已退出
+	importHeartbeatInterval = 30 * time.Second
+	importStaleAfter        = 5 * importHeartbeatInterval
+	// 超过该时间仍未改名的 upload 文件是上传中途退出留下的
+	importUploadStaleAfter = time.Hour
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("不支持的文件格式")
+	ErrImportStopped     = errors.New("导入服务正在停止")
+	errImportInterrupted = errors.New("import interrupted: server stopped")
+)
+
+type UserImportService interface {
+	StartImport(ctx context.Context, createdBy uint, format string, src io.Reader) (*model.ImportJob, error)
+	GetJob(ctx context.Context, id uint) (*model.ImportJob, error)
+	Export(ctx context.Context, format string, w io.Writer) error
+}
+
+// UserImportServiceImpl 导入在本实例的后台协程中执行，Run 退出前中断并等待它们，
+// 同时定期将其他实例退出后遗留的任务标记为失败
+type UserImportServiceImpl struct {
+	userRepo   repository.UserRepository
+	jobRepo    repository.ImportJobRepository
+	outboxRepo repository.OutboxRepository
+	txManager  repository.TxManager
+	logger     logger.Logger
+
+	ctx     context.Context // 所有导入的根上下文，Run 退出时取消
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	stopped bool
+	running sync.WaitGroup
+}
+
+func NewUserImportService(
+	userRepo repository.UserRepository,
+	jobRepo repository.ImportJobRepository,
+	outboxRepo repository.OutboxRepository,
+	txManager repository.TxManager,
+	logger logger.Logger,
+) *UserImportServiceImpl {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UserImportServiceImpl{
+		userRepo:   userRepo,
+		jobRepo:    jobRepo,
+		outboxRepo: outboxRepo,
+		txManager:  txManager,
+		logger:     logger.With(zap.String("module", "user_import_service")),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Run 定期清理遗留的任务和临时文件；ctx 取消后中断进行中的导入，等待它们标记为失败后返回
+func (s *UserImportServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(importHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		s.reapInterrupted(ctx)
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.stopped = true
+			s.mu.Unlock()
+			s.cancel()
+			s.running.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reapInterrupted 将心跳超时的任务标记为失败，并删除已结束任务和中途退出的上传留下的临时文件
+func (s *UserImportServiceImpl) reapInterrupted(ctx context.Context) {
+	ctx = db.UsePrimary(ctx)
+	n, err := s.jobRepo.FailStale(ctx, time.Now().Add(-importStaleAfter), errImportInterrupted.Error())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("fail stale import jobs failed", zap.Error(err))
+		}
+		return
+	}
+	if n > 0 {
+		s.logger.Warn("marked interrupted import jobs failed", zap.Int64("jobs", n))
+	}
+
+	dir := os.TempDir()
+	uploads, _ := filepath.Glob(filepath.Join(dir, importUploadFilePrefix+"*"))
+	for _, path := range uploads {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > importUploadStaleAfter {
+			os.Remove(path)
+		}
+	}
+	// job 文件可能属于同一主机上其他进程正在执行的任务，只删除任务已结束的
+	files, _ := filepath.Glob(filepath.Join(dir, importJobFilePrefix+"*"))
+	for _, path := range files {
+		name := strings.TrimPrefix(filepath.Base(path), importJobFilePrefix)
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, filepath.Ext(name)), 10, 64)
+		if err != nil {
+			continue
+		}
+		job, err := s.jobRepo.FindByID(ctx, uint(id))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if job == nil || job.Status == model.ImportJobCompleted || job.Status == model.ImportJobFailed {
+			os.Remove(path)
+		}
+	}
+}
+
+// importRow 解析或校验后的单行，err 非空表示该行失败
+type importRow struct {
+	line int
+	row  dto.ImportUserRow
+	user *model.User
+	err  error
+}
+
+// StartImport 将上传内容落盘后创建任务，在后台解析、校验、哈希并分批入库
+func (s *UserImportServiceImpl) StartImport(ctx context.Context, createdBy uint, format string, src io.Reader) (*model.ImportJob, error) {
+	if format != FormatCSV && format != FormatNDJSON {
+		return nil, ErrUnsupportedFormat
+	}
+
+	// 请求结束后上传内容不可再读，先写入临时文件
+	file, err := os.CreateTemp("", importUploadFilePrefix+"*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	if _, err := io.Copy(file, src); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("spool upload: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("spool upload: %w", err)
+	}
+
+	job := &model.ImportJob{
+		Format:    format,
+		Status:    model.ImportJobPending,
+		CreatedBy: createdBy,
+	}
+	// 登记后 Run 会等待该导入结束
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		os.Remove(file.Name())
+		return nil, ErrImportStopped
+	}
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		s.running.Done()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	path := filepath.Join(filepath.Dir(file.Name()), fmt.Sprintf("%s%d.%s", importJobFilePrefix, job.ID, format))
+	if err := os.Rename(file.Name(), path); err != nil {
+		s.running.Done()
+		os.Remove(file.Name())
+		err = fmt.Errorf("spool upload: %w", err)
+		s.finishImport(context.WithoutCancel(ctx), job.ID, nil, err)
+		return nil, err
+	}
+
+	go func() {
+		defer s.running.Done()
+		s.runImport(s.ctx, job, path)
+	}()
+	return job, nil
+}
+
+func (s *UserImportServiceImpl) GetJob(ctx context.Context, id uint) (*model.ImportJob, error) {
+	return s.jobRepo.FindByID(ctx, id)
+}
+
+// runImport 执行导入。runCtx 取消时停止解析，已读取的行写完后将任务标记为失败；
+// 数据库写入使用不随 runCtx 取消的 ctx，批次不会写到一半
+func (s *UserImportServiceImpl) runImport(runCtx context.Context, job *model.ImportJob, path string) {
+	ctx := context.WithoutCancel(runCtx)
+	defer os.Remove(path)
+
+	log := s.logger.With(zap.Uint("job_id", job.ID))
+	if err := s.jobRepo.Updates(ctx, job.ID, map[string]interface{}{"status": model.ImportJobRunning}); err != nil {
+		log.Error("mark import job running failed", zap.Error(err))
+	}
+
+	// 心跳：其他实例据此判断任务是否仍在执行
+	heartbeatCtx, stopHeartbeat := context.WithCancel(runCtx)
+	defer stopHeartbeat()
+	go func() {
+		ticker := time.NewTicker(importHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.jobRepo.Updates(heartbeatCtx, job.ID, map[string]interface{}{"updated_at": time.Now()}); err != nil && heartbeatCtx.Err() == nil {
+					log.Warn("import job heartbeat failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	file, err := os.Open(path)
+	if err != nil {
+		s.finishImport(ctx, job.ID, nil, err)
+		return
+	}
+	defer file.Close()
+
+	// 解析 -> 校验与哈希（工作池）-> 分批入库
+	parsed := make(chan importRow)
+	prepared := make(chan importRow)
+	parseErr := make(chan error, 1)
+	go func() {
+		defer close(parsed)
+		parseErr <- parseImportFile(runCtx, job.Format, file, parsed)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range parsed {
+				select {
+				case prepared <- prepareImportRow(row):
+				case <-runCtx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(prepared)
+	}()
+
+	report := &importReport{}
+	batch := make([]importRow, 0, importBatchSize)
+	for row := range prepared {
+		if runCtx.Err() != nil {
+			// 停止解析后排空管道，让工作协程退出
+			for range prepared {
+			}
+			break
+		}
+		report.total++
+		if row.err != nil {
+			report.fail(row.line, row.err)
+			continue
+		}
+		batch = append(batch, row)
+		if len(batch) >= importBatchSize {
+			s.insertBatch(ctx, batch, report)
+			batch = batch[:0]
+			s.saveProgress(ctx, job.ID, report)
+		}
+	}
+	if len(batch) > 0 {
+		s.insertBatch(ctx, batch, report)
+	}
+
+	jobErr := <-parseErr
+	if runCtx.Err() != nil {
+		jobErr = errImportInterrupted
+	}
+	s.finishImport(ctx, job.ID, report, jobErr)
+}
+
+// insertBatch 整批事务插入；失败时逐行重试以定位出错的行。
+// 每个导入的用户都在插入事务中记录 user.registered 事件
+func (s *UserImportServiceImpl) insertBatch(ctx context.Context, batch []importRow, report *importReport) {
+	users := make([]*model.User, len(batch))
+	err := s.txManager.Transaction(ctx, func(ctx context.Context) error {
+		for i := range batch {
+			users[i] = batch[i].user
+			users[i].ID = 0
+		}
+		if err := s.userRepo.CreateBatch(ctx, users); err != nil {
+			return err
+		}
+		events := make([]event.Event, len(users))
+		for i, user := range users {
+			events[i] = event.NewUserRegistered(user)
+		}
+		return recordEvents(ctx, s.outboxRepo, events...)
+	})
+	if err == nil {
+		report.succeeded += len(batch)
+		return
+	}
+	for i := range batch {
+		user := batch[i].user
+		user.ID = 0
+		if err := s.txManager.Transaction(ctx, func(ctx context.Context) error {
+			if err := s.userRepo.Create(ctx, user); err != nil {
+				return err
+			}
+			return recordEvents(ctx, s.outboxRepo, event.NewUserRegistered(user))
+		}); err != nil {
+			report.fail(batch[i].line, err)
+			continue
+		}
+		report.succeeded++
+	}
+}
+
+func (s *UserImportServiceImpl) saveProgress(ctx context.Context, jobID uint, report *importReport) {
+	if err := s.jobRepo.Updates(ctx, jobID, map[string]interface{}{
+		"total_rows":     report.total,
+		"succeeded_rows": report.succeeded,
+		"failed_rows":    report.failed,
+	}); err != nil {
+		s.logger.Warn("save import progress failed", zap.Uint("job_id", jobID), zap.Error(err))
+	}
+}
+
+func (s *UserImportServiceImpl) finishImport(ctx context.Context, jobID uint, report *importReport, jobErr error) {
+	if report == nil {
+		report = &importReport{}
+	}
+	fields := map[string]interface{}{
+		"status":         model.ImportJobCompleted,
+		"total_rows":     report.total,
+		"succeeded_rows": report.succeeded,
+		"failed_rows":    report.failed,
+		"finished_at":    time.Now(),
+	}
+	if jobErr != nil {
+		fields["status"] = model.ImportJobFailed
+		fields["message"] = jobErr.Error()
+	}
+	if len(report.errors) > 0 {
+		if encoded, err := json.Marshal(report.errors); err == nil {
+			fields["errors"] = string(encoded)
+		}
+	}
+	if err := s.jobRepo.Updates(ctx, jobID, fields); err != nil {
+		s.logger.Error("finish import job failed", zap.Uint("job_id", jobID), zap.Error(err))
+		return
+	}
+	s.logger.Info("import job finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("total", report.total),
+		zap.Int("succeeded", report.succeeded),
+		zap.Int("failed", report.failed),
+		zap.NamedError("job_error", jobErr))
+}
+
+// Export 流式导出全部用户，分批查询并逐批刷新输出，不会一次性加载到内存
+func (s *UserImportServiceImpl) Export(ctx context.Context, format string, w io.Writer) error {
+	flush := func() {
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(dto.ExportUserColumns); err != nil {
+			return err
+		}
+		return s.userRepo.FindInBatches(ctx, exportBatchSize, func(users []model.User) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for i := range users {
+				row := dto.NewExportUserRow(&users[i])
+				if err := writer.Write([]string{
+					strconv.FormatUint(uint64(row.ID), 10),
+					row.Username,
+					row.Email,
+					row.Status,
+					row.DisplayName,
+					row.Locale,
+					row.Timezone,
+					row.CreatedAt.Format(time.RFC3339),
+				}); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			flush()
+			return writer.Error()
+		})
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		return s.userRepo.FindInBatches(ctx, exportBatchSize, func(users []model.User) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for i := range users {
+				if err := encoder.Encode(dto.NewExportUserRow(&users[i])); err != nil {
+					return err
+				}
+			}
+			flush()
+			return nil
+		})
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// importReport 导入统计，只在收集协程中修改
+type importReport struct {
+	total     int
+	succeeded int
+	failed    int
+	errors    []model.ImportRowError
+}
+
+func (r *importReport) fail(line int, err error) {
+	r.failed++
+	if len(r.errors) < importMaxRowErrors {
+		r.errors = append(r.errors, model.ImportRowError{Row: line, Message: err.Error()})
+	}
+}
+
+// prepareImportRow 按注册规则校验并哈希密码
+func prepareImportRow(row importRow) importRow {
+	if row.err != nil {
+		return row
+	}
+	if err := binding.Validator.ValidateStruct(&row.row); err != nil {
+		row.err = err
+		return row
+	}
+	user := row.row.ToModel()
+	if err := prepareNewUser(user); err != nil {
+		row.err = err
+		return row
+	}
+	row.user = user
+	return row
+}
+
+// parseImportFile 解析到 out，ctx 取消时停止并返回 ctx.Err()
+func parseImportFile(ctx context.Context, format string, r io.Reader, out chan<- importRow) error {
+	send := func(row importRow) error {
+		select {
+		case out <- row:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if format == FormatCSV {
+		return parseCSV(r, send)
+	}
+	return parseNDJSON(r, send)
+}
+
+// parseCSV 第一行为表头，列名与 JSON 字段名一致，未知列忽略
+func parseCSV(r io.Reader, send func(importRow) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return errors.New("empty csv file")
+		}
+		return fmt.Errorf("read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// 单行格式错误不影响后续行，读取失败等其他错误终止解析
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return fmt.Errorf("read csv: %w", err)
+			}
+			if err := send(importRow{line: parseErr.StartLine, err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		// 引号内的字段可以跨行，空行会被跳过，行号取记录在文件中的起始行
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := dto.ImportUserRow{
+			DisplayName: value("display_name"),
+			Locale:      value("locale"),
+			Timezone:    value("timezone"),
+		}
+		row.Username = value("username")
+		row.Email = value("email")
+		row.Password = value("password")
+		if err := send(importRow{line: line, row: row}); err != nil {
+			return err
+		}
+	}
+}
+
+// parseNDJSON 每行一个 JSON 对象，空行跳过
+func parseNDJSON(r io.Reader, send func(importRow) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), ndjsonMaxLineLength)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var row dto.ImportUserRow
+		if err := json.Unmarshal(data, &row); err != nil {
+			if err := send(importRow{line: line, err: fmt.Errorf("invalid json: %w", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := send(importRow{line: line, row: row}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
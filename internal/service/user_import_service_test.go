@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func collectRows(t *testing.T, parse func(send func(importRow) error) error) []importRow {
+	t.Helper()
+	var rows []importRow
+	if err := parse(func(row importRow) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestParseCSVReportsFileLines(t *testing.T) {
+	input := strings.Join([]string{
+		"username,email,display_name",
+		`alice,alice@example.com,"Alice`,
+		`Smith"`,
+		"",
+		"bob, bob@example.com ,Bob",
+		`carol,c"arol@example.com,Carol`,
+		"dave,dave@example.com",
+	}, "\n")
+	rows := collectRows(t, func(send func(importRow) error) error {
+		return parseCSV(strings.NewReader(input), send)
+	})
+
+	want := []struct {
+		line     int
+		username string
+		failed   bool
+	}{
+		{2, "alice", false}, // 引号内的字段跨两行
+		{5, "bob", false},   // 跳过空行
+		{6, "", true},
+		{7, "dave", false},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		got := rows[i]
+		if got.line != w.line || got.row.Username != w.username || (got.err != nil) != w.failed {
+			t.Errorf("row %d = line %d, username %q, err %v; want line %d, username %q, failed %v",
+				i, got.line, got.row.Username, got.err, w.line, w.username, w.failed)
+		}
+	}
+	if rows[0].row.DisplayName != "Alice\nSmith" {
+		t.Errorf("display name = %q", rows[0].row.DisplayName)
+	}
+	if rows[1].row.Email != "bob@example.com" {
+		t.Errorf("email = %q, want trimmed", rows[1].row.Email)
+	}
+	var parseErr *csv.ParseError
+	if !errors.As(rows[2].err, &parseErr) {
+		t.Errorf("row error = %v, want csv.ParseError", rows[2].err)
+	}
+	// 缺少的列为空
+	if rows[3].row.DisplayName != "" {
+		t.Errorf("missing column = %q", rows[3].row.DisplayName)
+	}
+}
+
+func TestParseCSVEmptyFile(t *testing.T) {
+	if err := parseCSV(strings.NewReader(""), func(importRow) error { return nil }); err == nil {
+		t.Fatal("expected error for empty file")
+	}
+	rows := collectRows(t, func(send func(importRow) error) error {
+		return parseCSV(strings.NewReader("username,email\n"), send)
+	})
+	if len(rows) != 0 {
+		t.Fatalf("header only file produced %d rows", len(rows))
+	}
+}
+
+func TestParseNDJSONReportsFileLines(t *testing.T) {
+	input := "{\"username\":\"alice\"}\n\n{not json}\n{\"username\":\"bob\"}\n"
+	rows := collectRows(t, func(send func(importRow) error) error {
+		return parseNDJSON(strings.NewReader(input), send)
+	})
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].line != 1 || rows[0].row.Username != "alice" || rows[1].line != 3 || rows[1].err == nil || rows[2].line != 4 || rows[2].row.Username != "bob" {
+		t.Fatalf("rows = %+v", rows)
+	}
+}
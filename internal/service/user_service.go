@@ -2,41 +2,243 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/event"
 	"gin-wire-demo/internal/model"
 	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/mailer"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPassword   = errors.New("密码错误")
+	ErrEmailTaken        = errors.New("邮箱已被使用")
+	ErrInvalidEmailToken = errors.New("邮箱验证链接无效或已过期")
 )
 
 type UserService interface {
-	CreateUser(user *model.User) error
-	GetUserByID(id uint) (*model.User, error)
-	GetUserByUsername(username string) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByID(ctx context.Context, id uint) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	VerifyPassword(ctx context.Context, id uint, password string) (*model.User, error)
+	UpdateProfile(ctx context.Context, id uint, fields map[string]interface{}) (*model.User, error)
+	ChangePassword(ctx context.Context, id uint, oldPassword, newPassword string) (*model.User, error)
+	RequestEmailChange(ctx context.Context, id uint, password, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, token string) (*model.User, error)
+	CloseAccount(ctx context.Context, id uint, password string) error
+	RecordAccountLocked(ctx context.Context, username string, lockedUntil time.Time) error
 }
 
 type UserServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	outboxRepo repository.OutboxRepository
+	txManager  repository.TxManager
+	config     *config.Config
+	mailer     mailer.Mailer
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	outboxRepo repository.OutboxRepository,
+	txManager repository.TxManager,
+	config *config.Config,
+	mailer mailer.Mailer,
+) *UserServiceImpl {
+	return &UserServiceImpl{
+		userRepo:   userRepo,
+		outboxRepo: outboxRepo,
+		txManager:  txManager,
+		config:     config,
+		mailer:     mailer,
+	}
 }
 
-func NewUserService(userRepo repository.UserRepository) *UserServiceImpl {
-	return &UserServiceImpl{userRepo: userRepo}
+// CreateUser 创建用户，并在同一事务中记录 user.registered 事件
+func (s *UserServiceImpl) CreateUser(ctx context.Context, user *model.User) error {
+	if err := prepareNewUser(user); err != nil {
+		return err
+	}
+	return s.txManager.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return recordEvents(ctx, s.outboxRepo, event.NewUserRegistered(user))
+	})
+}
+
+func (s *UserServiceImpl) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	return s.userRepo.FindByID(ctx, id)
+}
+
+func (s *UserServiceImpl) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	return s.userRepo.FindByUsername(ctx, username)
+}
+
+// UpdateProfile 修改扩展资料
+func (s *UserServiceImpl) UpdateProfile(ctx context.Context, id uint, fields map[string]interface{}) (*model.User, error) {
+	if len(fields) == 0 {
+		return s.userRepo.FindByID(ctx, id)
+	}
+	changed := make([]string, 0, len(fields))
+	for field := range fields {
+		changed = append(changed, field)
+	}
+	sort.Strings(changed)
+	return s.updateUser(ctx, id, fields, changed)
 }
 
-func (s *UserServiceImpl) CreateUser(user *model.User) error {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+// ChangePassword 校验旧密码后修改密码，并递增令牌版本使所有已签发令牌失效
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, id uint, oldPassword, newPassword string) (*model.User, error) {
+	user, err := s.VerifyPassword(ctx, id, oldPassword)
 	if err != nil {
-		return errors.New("密码hash失败")
+		return nil, err
 	}
-	user.Password = string(hashed)
-	user.Status = "active"
-	return s.userRepo.Create(user)
+	hashed, err := hashPassword(newPassword)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateUser(ctx, user.ID, map[string]interface{}{
+		"password":      hashed,
+		"token_version": gorm.Expr("token_version + 1"),
+	}, []string{"password"})
+}
+
+// RequestEmailChange 校验密码后向新邮箱发送验证邮件，验证通过前邮箱不变
+func (s *UserServiceImpl) RequestEmailChange(ctx context.Context, id uint, password, newEmail string) error {
+	user, err := s.VerifyPassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+	token, err := s.signEmailToken(user, newEmail)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Use the following token to confirm your new email address: %s", token)
+	return s.mailer.Send(newEmail, "Confirm your email address", body)
+}
+
+// ConfirmEmailChange 使用验证令牌完成邮箱变更
+func (s *UserServiceImpl) ConfirmEmailChange(ctx context.Context, token string) (*model.User, error) {
+	claims, err := s.parseEmailToken(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+	// 邮箱已变更过则令牌失效，保证一次性使用
+	if user.Email != claims.OldEmail {
+		return nil, ErrInvalidEmailToken
+	}
+	if err := s.ensureEmailAvailable(ctx, claims.NewEmail); err != nil {
+		return nil, err
+	}
+	return s.updateUser(ctx, user.ID, map[string]interface{}{"email": claims.NewEmail}, []string{"email"})
 }
 
-func (s *UserServiceImpl) GetUserByID(id uint) (*model.User, error) {
-	return s.userRepo.FindByID(id)
+// CloseAccount 注销账户：校验密码、使令牌失效并软删除
+func (s *UserServiceImpl) CloseAccount(ctx context.Context, id uint, password string) error {
+	user, err := s.VerifyPassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	return s.txManager.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Updates(ctx, user.ID, map[string]interface{}{
+			"status":        "closed",
+			"token_version": gorm.Expr("token_version + 1"),
+		}); err != nil {
+			return err
+		}
+		return s.userRepo.Delete(ctx, user.ID)
+	})
 }
 
-func (s *UserServiceImpl) GetUserByUsername(username string) (*model.User, error) {
-	return s.userRepo.FindByUsername(username)
+// RecordAccountLocked 记录账户因连续登录失败被锁定的事件。用户不存在时忽略
+func (s *UserServiceImpl) RecordAccountLocked(ctx context.Context, username string, lockedUntil time.Time) error {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return recordEvents(ctx, s.outboxRepo, event.NewUserLocked(user, lockedUntil))
+}
+
+// updateUser 更新用户并在同一事务中记录 user.updated 事件，返回更新后的用户
+func (s *UserServiceImpl) updateUser(ctx context.Context, id uint, fields map[string]interface{}, changed []string) (*model.User, error) {
+	var user *model.User
+	err := s.txManager.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Updates(ctx, id, fields); err != nil {
+			return err
+		}
+		var err error
+		if user, err = s.userRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return recordEvents(ctx, s.outboxRepo, event.NewUserUpdated(user, changed))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// VerifyPassword 校验用户密码，成功时返回用户
+func (s *UserServiceImpl) VerifyPassword(ctx context.Context, id uint, password string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return user, nil
+}
+
+func (s *UserServiceImpl) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := s.userRepo.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return ErrEmailTaken
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// prepareNewUser 哈希密码并设置新用户的服务端字段
+func prepareNewUser(user *model.User) error {
+	hashed, err := hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.Status = "active"
+	user.Role = model.SystemRoleUser
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.New("密码hash失败")
+	}
+	return string(hashed), nil
 }
@@ -0,0 +1,206 @@
+// internal/service/webhook_dispatcher.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/db"
+	"gin-wire-demo/pkg/logger"
+	"gin-wire-demo/pkg/webhook"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const maxResponseBodyLen = 1024
+
+// WebhookDispatcher 后台投递 webhook：签名后 POST 到端点，2xx 视为成功，
+// 失败按指数退避重试，端点连续失败达到阈值后自动停用
+type WebhookDispatcher struct {
+	webhookRepo repository.WebhookRepository
+	txManager   repository.TxManager
+	client      *http.Client
+	config      config.WebhookConfig
+	userAgent   string
+	logger      logger.Logger
+}
+
+func NewWebhookDispatcher(
+	webhookRepo repository.WebhookRepository,
+	txManager repository.TxManager,
+	config *config.Config,
+	logger logger.Logger,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		txManager:   txManager,
+		client:      webhook.NewClient(config.Webhook.Timeout, config.Webhook.AllowInsecureURLs),
+		config:      config.Webhook,
+		userAgent:   config.App.Name + "-webhooks",
+		logger:      logger.With(zap.String("module", "webhook_dispatcher")),
+	}
+}
+
+// Run 持续投递直到 ctx 取消
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	runPoller(ctx, d.config.PollInterval, d.config.BatchSize, d.logger, d.DispatchOnce)
+}
+
+// DispatchOnce 领取一批到期的投递并发处理，返回领取的数量
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	ctx = db.UsePrimary(ctx)
+	deliveries, err := d.webhookRepo.ClaimDueDeliveries(ctx, time.Now(), d.config.BatchSize, d.config.DeliveryLease)
+	if err != nil {
+		return len(deliveries), err
+	}
+
+	sem := make(chan struct{}, d.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			d.dispatch(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) dispatch(ctx context.Context, delivery *model.WebhookDelivery) {
+	log := d.logger.With(
+		zap.Uint("delivery_id", delivery.ID),
+		zap.Uint("endpoint_id", delivery.EndpointID),
+		zap.String("event_id", delivery.EventID))
+
+	endpoint, err := d.webhookRepo.FindEndpoint(ctx, delivery.TenantID, delivery.EndpointID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("load webhook endpoint failed", zap.Error(err))
+		return
+	}
+	if endpoint == nil || !endpoint.Enabled {
+		// 端点已删除或停用，重新启用后可通过重放补发
+		reason := "endpoint deleted"
+		if endpoint != nil {
+			reason = "endpoint disabled"
+		}
+		if err := d.webhookRepo.UpdateDelivery(ctx, delivery.ID, map[string]interface{}{
+			"status":     model.WebhookDeliveryFailed,
+			"last_error": reason,
+		}); err != nil {
+			log.Error("update webhook delivery failed", zap.Error(err))
+		}
+		return
+	}
+
+	attempt := d.send(ctx, endpoint, delivery)
+	if ctx.Err() != nil {
+		// 关闭过程中被取消的请求不计入失败，占用到期后重新投递
+		return
+	}
+	delivery.Attempts++
+	success := attempt.Error == ""
+
+	fields := map[string]interface{}{
+		"attempts":         delivery.Attempts,
+		"last_status_code": attempt.StatusCode,
+		"last_error":       attempt.Error,
+	}
+	switch {
+	case success:
+		fields["status"] = model.WebhookDeliverySucceeded
+		fields["delivered_at"] = time.Now()
+	case delivery.Attempts >= d.config.MaxAttempts:
+		fields["status"] = model.WebhookDeliveryFailed
+	default:
+		fields["next_attempt_at"] = time.Now().Add(retryDelay(d.config.RetryBackoff, d.config.MaxBackoff, delivery.Attempts))
+	}
+	if err := d.txManager.Transaction(ctx, func(ctx context.Context) error {
+		if err := d.webhookRepo.CreateAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		return d.webhookRepo.UpdateDelivery(ctx, delivery.ID, fields)
+	}); err != nil {
+		log.Error("record webhook attempt failed", zap.Error(err))
+		return
+	}
+
+	if success {
+		if err := d.webhookRepo.ResetEndpointFailures(ctx, endpoint.TenantID, endpoint.ID); err != nil {
+			log.Warn("reset webhook endpoint failures failed", zap.Error(err))
+		}
+		return
+	}
+	log.Warn("webhook delivery failed",
+		zap.Int("attempts", delivery.Attempts),
+		zap.Int("status_code", attempt.StatusCode),
+		zap.String("error", attempt.Error))
+
+	reason := fmt.Sprintf("%d consecutive delivery failures", d.config.DisableAfter)
+	disabled, err := d.webhookRepo.RecordEndpointFailure(ctx, endpoint.TenantID, endpoint.ID, d.config.DisableAfter, reason)
+	if err != nil {
+		log.Error("record webhook endpoint failure failed", zap.Error(err))
+		return
+	}
+	if disabled {
+		log.Warn("webhook endpoint disabled after repeated failures", zap.Int("disable_after", d.config.DisableAfter))
+	}
+}
+
+// send 发送一次请求，返回投递日志
+func (d *WebhookDispatcher) send(ctx context.Context, endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery) *model.WebhookAttempt {
+	attempt := &model.WebhookAttempt{
+		TenantID:   delivery.TenantID,
+		DeliveryID: delivery.ID,
+	}
+	start := time.Now()
+	defer func() {
+		attempt.DurationMS = time.Since(start).Milliseconds()
+	}()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = truncate(err.Error(), maxLastErrorLen)
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", d.userAgent)
+	req.Header.Set(webhook.HeaderEventID, delivery.EventID)
+	req.Header.Set(webhook.HeaderEventType, delivery.EventType)
+	req.Header.Set(webhook.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(endpoint.Secret, start, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = truncate(err.Error(), maxLastErrorLen)
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyLen))
+	// 读完剩余内容以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseBody = truncate(strings.ToValidUTF8(string(respBody), ""), maxResponseBodyLen)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = "unexpected status " + resp.Status
+	}
+	return attempt
+}
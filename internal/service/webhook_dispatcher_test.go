@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/webhook"
+
+	"gorm.io/gorm"
+)
+
+const testWebhookSecret = "whsec_test"
+
+type dispatcherFixture struct {
+	dispatcher *WebhookDispatcher
+	repo       *repository.WebhookRepositoryImpl
+	db         *gorm.DB
+	endpoint   *model.WebhookEndpoint
+	calls      *atomic.Int32
+}
+
+// newDispatcherFixture 端点指向本地测试服务器，status 决定第 n 次请求（从 1 开始）的响应码
+func newDispatcherFixture(t *testing.T, webhookCfg config.WebhookConfig, status func(n int32) int) *dispatcherFixture {
+	t.Helper()
+	calls := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(testWebhookSecret, r.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()); err != nil {
+			t.Errorf("request %d: %v", n, err)
+		}
+		if r.Header.Get(webhook.HeaderEventID) == "" || r.Header.Get(webhook.HeaderDelivery) == "" {
+			t.Errorf("request %d: missing event headers", n)
+		}
+		w.WriteHeader(status(n))
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := newTestConfig(t)
+	webhookCfg.PollInterval = time.Second
+	webhookCfg.BatchSize = 10
+	webhookCfg.Concurrency = 1
+	webhookCfg.Timeout = time.Second
+	webhookCfg.DeliveryLease = time.Minute
+	webhookCfg.RetryBackoff = time.Minute
+	webhookCfg.MaxBackoff = time.Hour
+	webhookCfg.AllowInsecureURLs = true
+	cfg.Webhook = webhookCfg
+	gdb, log := newTestDB(t, cfg)
+
+	repo := repository.NewWebhookRepository(gdb)
+	endpoint := &model.WebhookEndpoint{URL: server.URL, Secret: testWebhookSecret, EventTypes: model.WebhookAllEvents, Enabled: true}
+	if err := repo.CreateEndpoint(context.Background(), endpoint); err != nil {
+		t.Fatal(err)
+	}
+	return &dispatcherFixture{
+		dispatcher: NewWebhookDispatcher(repo, repository.NewTxManager(gdb, cfg, log), cfg, log),
+		repo:       repo,
+		db:         gdb,
+		endpoint:   endpoint,
+		calls:      calls,
+	}
+}
+
+func (f *dispatcherFixture) createDelivery(t *testing.T, eventID string) *model.WebhookDelivery {
+	t.Helper()
+	delivery := &model.WebhookDelivery{
+		EndpointID:    f.endpoint.ID,
+		EventID:       eventID,
+		EventType:     "user.created",
+		Payload:       `{"id":"` + eventID + `"}`,
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	if err := f.repo.CreateDeliveries(context.Background(), []*model.WebhookDelivery{delivery}); err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func (f *dispatcherFixture) dispatch(t *testing.T, want int) {
+	t.Helper()
+	n, err := f.dispatcher.DispatchOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != want {
+		t.Fatalf("dispatched %d deliveries, want %d", n, want)
+	}
+}
+
+func (f *dispatcherFixture) delivery(t *testing.T, id uint) *model.WebhookDelivery {
+	t.Helper()
+	delivery, err := f.repo.FindDelivery(context.Background(), 0, f.endpoint.ID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func (f *dispatcherFixture) reloadEndpoint(t *testing.T) *model.WebhookEndpoint {
+	t.Helper()
+	endpoint, err := f.repo.FindEndpoint(context.Background(), 0, f.endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+// makeDue 跳过退避等待，使投递立即到期
+func (f *dispatcherFixture) makeDue(t *testing.T, id uint) {
+	t.Helper()
+	if err := f.repo.UpdateDelivery(context.Background(), id, map[string]interface{}{
+		"next_attempt_at": time.Now().Add(-time.Second),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookDispatcherRetriesUntilSuccess(t *testing.T) {
+	f := newDispatcherFixture(t, config.WebhookConfig{MaxAttempts: 5, DisableAfter: 10}, func(n int32) int {
+		if n <= 2 {
+			return http.StatusInternalServerError
+		}
+		return http.StatusNoContent
+	})
+	d := f.createDelivery(t, "evt-1")
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		start := time.Now()
+		f.dispatch(t, 1)
+		got := f.delivery(t, d.ID)
+		if got.Status != model.WebhookDeliveryPending || got.Attempts != attempt || got.LastStatusCode != 500 || got.LastError == "" {
+			t.Fatalf("attempt %d: %+v", attempt, got)
+		}
+		// 退避时间按尝试次数翻倍
+		wait := got.NextAttemptAt.Sub(start)
+		if want := time.Minute << (attempt - 1); wait < want || wait > want+time.Second {
+			t.Fatalf("attempt %d: next attempt in %v, want %v", attempt, wait, want)
+		}
+		if failures := f.reloadEndpoint(t).ConsecutiveFailures; failures != attempt {
+			t.Fatalf("attempt %d: endpoint failures = %d", attempt, failures)
+		}
+		// 未到期的投递不会被领取
+		f.dispatch(t, 0)
+		f.makeDue(t, d.ID)
+	}
+
+	f.dispatch(t, 1)
+	got := f.delivery(t, d.ID)
+	if got.Status != model.WebhookDeliverySucceeded || got.Attempts != 3 || got.LastStatusCode != 204 || got.LastError != "" || got.DeliveredAt == nil {
+		t.Fatalf("after success: %+v", got)
+	}
+	if endpoint := f.reloadEndpoint(t); endpoint.ConsecutiveFailures != 0 || !endpoint.Enabled {
+		t.Fatalf("endpoint after success: %+v", endpoint)
+	}
+	attempts, err := f.repo.ListAttempts(context.Background(), 0, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 3 || attempts[0].StatusCode != 500 || attempts[0].ResponseBody != "ok" || attempts[2].StatusCode != 204 {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+	if calls := f.calls.Load(); calls != 3 {
+		t.Fatalf("server received %d requests, want 3", calls)
+	}
+}
+
+func TestWebhookDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	f := newDispatcherFixture(t, config.WebhookConfig{MaxAttempts: 2, DisableAfter: 10}, func(int32) int {
+		return http.StatusBadGateway
+	})
+	d := f.createDelivery(t, "evt-1")
+
+	f.dispatch(t, 1)
+	f.makeDue(t, d.ID)
+	f.dispatch(t, 1)
+	got := f.delivery(t, d.ID)
+	if got.Status != model.WebhookDeliveryFailed || got.Attempts != 2 || got.LastStatusCode != 502 {
+		t.Fatalf("after max attempts: %+v", got)
+	}
+	f.makeDue(t, d.ID)
+	f.dispatch(t, 0)
+}
+
+func TestWebhookDispatcherDisablesFailingEndpoint(t *testing.T) {
+	f := newDispatcherFixture(t, config.WebhookConfig{MaxAttempts: 5, DisableAfter: 3}, func(int32) int {
+		return http.StatusServiceUnavailable
+	})
+	for _, id := range []string{"evt-1", "evt-2", "evt-3"} {
+		f.createDelivery(t, id)
+	}
+
+	f.dispatch(t, 3)
+	endpoint := f.reloadEndpoint(t)
+	if endpoint.Enabled || endpoint.ConsecutiveFailures != 3 || endpoint.DisabledAt == nil || endpoint.DisabledReason == "" {
+		t.Fatalf("endpoint after 3 failures: %+v", endpoint)
+	}
+
+	// 停用后到期的投递直接标记失败，不再发送请求
+	later := f.createDelivery(t, "evt-4")
+	f.dispatch(t, 1)
+	got := f.delivery(t, later.ID)
+	if got.Status != model.WebhookDeliveryFailed || got.Attempts != 0 || got.LastError != "endpoint disabled" {
+		t.Fatalf("delivery to disabled endpoint: %+v", got)
+	}
+	if calls := f.calls.Load(); calls != 3 {
+		t.Fatalf("server received %d requests, want 3", calls)
+	}
+}
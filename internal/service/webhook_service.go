@@ -0,0 +1,301 @@
+// internal/service/webhook_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/event"
+	"gin-wire-demo/internal/model"
+	"gin-wire-demo/internal/repository"
+	"gin-wire-demo/pkg/broker"
+	"gin-wire-demo/pkg/webhook"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebhookNotFound   = errors.New("webhook 不存在")
+	ErrDeliveryNotFound  = errors.New("投递记录不存在")
+	ErrInvalidWebhookURL = errors.New("webhook 地址无效，需为可公开访问的 https 地址")
+	ErrUnknownEventType  = errors.New("不支持的事件类型")
+)
+
+const (
+	defaultDeliveryPageSize = 50
+	maxDeliveryPageSize     = 200
+)
+
+// WebhookService 管理 webhook 端点并把领域事件分发为投递任务。
+// tenantID 为 0 表示应用级端点，仅系统管理员可管理
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, tenantID uint, url string, eventTypes []string, description string) (*model.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, tenantID uint) ([]model.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, tenantID, id uint) (*model.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, tenantID, id uint, update WebhookUpdate) (*model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, tenantID, id uint) error
+	ListDeliveries(ctx context.Context, tenantID, endpointID uint, limit int) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, []model.WebhookAttempt, error)
+	ReplayDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, error)
+	Enqueue(ctx context.Context, msg broker.Message) error
+}
+
+// WebhookUpdate 端点的可修改字段，nil 表示不修改
+type WebhookUpdate struct {
+	URL         *string
+	EventTypes  []string
+	Description *string
+	Enabled     *bool
+}
+
+type WebhookServiceImpl struct {
+	webhookRepo repository.WebhookRepository
+	orgRepo     repository.OrganizationRepository
+	config      *config.Config
+}
+
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	orgRepo repository.OrganizationRepository,
+	config *config.Config,
+) *WebhookServiceImpl {
+	return &WebhookServiceImpl{
+		webhookRepo: webhookRepo,
+		orgRepo:     orgRepo,
+		config:      config,
+	}
+}
+
+// CreateEndpoint 注册端点并生成签名密钥，密钥只在返回值中出现这一次
+func (s *WebhookServiceImpl) CreateEndpoint(ctx context.Context, tenantID uint, url string, eventTypes []string, description string) (*model.WebhookEndpoint, error) {
+	if err := s.validateURL(url); err != nil {
+		return nil, err
+	}
+	types, err := normalizeEventTypes(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := &model.WebhookEndpoint{
+		TenantID:    tenantID,
+		URL:         url,
+		Secret:      secret,
+		EventTypes:  types,
+		Description: description,
+		Enabled:     true,
+	}
+	if err := s.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookServiceImpl) ListEndpoints(ctx context.Context, tenantID uint) ([]model.WebhookEndpoint, error) {
+	return s.webhookRepo.ListEndpoints(ctx, tenantID)
+}
+
+func (s *WebhookServiceImpl) GetEndpoint(ctx context.Context, tenantID, id uint) (*model.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.FindEndpoint(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	return endpoint, err
+}
+
+// UpdateEndpoint 修改端点。重新启用时清零失败计数
+func (s *WebhookServiceImpl) UpdateEndpoint(ctx context.Context, tenantID, id uint, update WebhookUpdate) (*model.WebhookEndpoint, error) {
+	fields := map[string]interface{}{}
+	if update.URL != nil {
+		if err := s.validateURL(*update.URL); err != nil {
+			return nil, err
+		}
+		fields["url"] = *update.URL
+	}
+	if update.EventTypes != nil {
+		types, err := normalizeEventTypes(update.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		fields["event_types"] = types
+	}
+	if update.Description != nil {
+		fields["description"] = *update.Description
+	}
+	if update.Enabled != nil {
+		fields["enabled"] = *update.Enabled
+		if *update.Enabled {
+			fields["consecutive_failures"] = 0
+			fields["disabled_at"] = nil
+			fields["disabled_reason"] = ""
+		}
+	}
+	if len(fields) > 0 {
+		if err := s.webhookRepo.UpdateEndpoint(ctx, tenantID, id, fields); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrWebhookNotFound
+			}
+			return nil, err
+		}
+	}
+	return s.GetEndpoint(ctx, tenantID, id)
+}
+
+func (s *WebhookServiceImpl) DeleteEndpoint(ctx context.Context, tenantID, id uint) error {
+	err := s.webhookRepo.DeleteEndpoint(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// ListDeliveries 列出端点最近的投递
+func (s *WebhookServiceImpl) ListDeliveries(ctx context.Context, tenantID, endpointID uint, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.GetEndpoint(ctx, tenantID, endpointID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryPageSize
+	}
+	if limit > maxDeliveryPageSize {
+		limit = maxDeliveryPageSize
+	}
+	return s.webhookRepo.ListDeliveries(ctx, tenantID, endpointID, limit)
+}
+
+// GetDelivery 返回投递及其每次请求的日志
+func (s *WebhookServiceImpl) GetDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, []model.WebhookAttempt, error) {
+	delivery, err := s.webhookRepo.FindDelivery(ctx, tenantID, endpointID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrDeliveryNotFound
+		}
+		return nil, nil, err
+	}
+	attempts, err := s.webhookRepo.ListAttempts(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delivery, attempts, nil
+}
+
+// ReplayDelivery 重新投递：重置为待投递并清零尝试次数，请求体和事件 ID 不变
+func (s *WebhookServiceImpl) ReplayDelivery(ctx context.Context, tenantID, endpointID, id uint) (*model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDelivery(ctx, tenantID, endpointID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery.ID, map[string]interface{}{
+		"status":          model.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.FindDelivery(ctx, tenantID, endpointID, id)
+}
+
+// webhookPayload 投递的请求体
+type webhookPayload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Enqueue 为订阅了该事件的端点创建投递任务：应用级端点接收全部事件，
+// 组织端点只接收本组织成员的用户事件。由 outbox 中继调用，重复调用不会重复投递
+func (s *WebhookServiceImpl) Enqueue(ctx context.Context, msg broker.Message) error {
+	tenantIDs := []uint{0}
+	if msg.AggregateType == event.AggregateUser {
+		userID, err := strconv.ParseUint(msg.AggregateID, 10, 64)
+		if err != nil {
+			return err
+		}
+		memberships, err := s.orgRepo.ListUserMemberships(ctx, uint(userID))
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			tenantIDs = append(tenantIDs, m.TenantID)
+		}
+	}
+
+	endpoints, err := s.webhookRepo.FindSubscribers(ctx, tenantIDs)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(webhookPayload{
+		ID:        msg.ID,
+		Type:      msg.Type,
+		CreatedAt: msg.OccurredAt,
+		Data:      msg.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []*model.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(msg.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			TenantID:      endpoint.TenantID,
+			EndpointID:    endpoint.ID,
+			EventID:       msg.ID,
+			EventType:     msg.Type,
+			Payload:       string(body),
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+func (s *WebhookServiceImpl) validateURL(url string) error {
+	if err := webhook.ValidateURL(url, s.config.Webhook.AllowInsecureURLs); err != nil {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// normalizeEventTypes 校验并去重订阅的事件类型，返回逗号分隔的存储格式
+func normalizeEventTypes(types []string) (string, error) {
+	seen := make(map[string]bool, len(types))
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t != model.WebhookAllEvents && !isUserEvent(t) {
+			return "", ErrUnknownEventType
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	if len(result) == 0 {
+		return "", ErrUnknownEventType
+	}
+	return strings.Join(result, ","), nil
+}
+
+func isUserEvent(t string) bool {
+	for _, e := range event.UserEvents {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// internal/tenant/plugin.go
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantField 租户表统一使用的字段名
+const tenantField = "TenantID"
+
+// Plugin GORM 插件：对含 TenantID 字段的表自动追加租户过滤条件，
+// 创建时自动填充 TenantID。上下文中没有租户且未显式跳过时直接报错，避免越权读写。
+type Plugin struct{}
+
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Name() string {
+	return "tenant"
+}
+
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+func tenantSchemaField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(tenantField)
+}
+
+func scopeTenant(db *gorm.DB) {
+	field := tenantSchemaField(db)
+	if field == nil || isScopeSkipped(db.Statement.Context) {
+		return
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+func assignTenant(db *gorm.DB) {
+	field := tenantSchemaField(db)
+	if field == nil || isScopeSkipped(db.Statement.Context) {
+		return
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return
+	}
+
+	assign := func(rv reflect.Value) {
+		value, isZero := field.ValueOf(db.Statement.Context, rv)
+		if isZero {
+			_ = db.AddError(field.Set(db.Statement.Context, rv, tenantID))
+			return
+		}
+		if current, ok := value.(uint); !ok || current != tenantID {
+			_ = db.AddError(ErrCrossTenant)
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
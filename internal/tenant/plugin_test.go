@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// note 测试用的租户表
+type note struct {
+	ID       uint
+	TenantID uint
+	Body     string
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	// 内存库每个连接各自独立，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Use(NewPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestQueryIsScopedToTenant(t *testing.T) {
+	db := newTestDB(t)
+	ctxA := WithTenant(context.Background(), 1)
+	ctxB := WithTenant(context.Background(), 2)
+	if err := db.WithContext(ctxA).Create(&note{Body: "a"}).Error; err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	var b note
+	b.Body = "b"
+	if err := db.WithContext(ctxB).Create(&b).Error; err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+
+	var notes []note
+	if err := db.WithContext(ctxA).Find(&notes).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(notes) != 1 || notes[0].TenantID != 1 {
+		t.Fatalf("tenant 1 got %+v", notes)
+	}
+
+	// 按主键读取其他租户的行也查不到
+	err := db.WithContext(ctxA).First(&note{}, b.ID).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("cross-tenant read: got %v, want ErrRecordNotFound", err)
+	}
+
+	var count int64
+	if err := db.WithContext(ctxA).Model(&note{}).Where("body = ?", "b").Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("tenant 1 counted %d rows of tenant 2", count)
+	}
+
+	// 更新和删除同样按租户过滤
+	if n := db.WithContext(ctxA).Model(&note{}).Where("id = ?", b.ID).Update("body", "x").RowsAffected; n != 0 {
+		t.Fatalf("cross-tenant update affected %d rows", n)
+	}
+	if n := db.WithContext(ctxA).Delete(&note{}, b.ID).RowsAffected; n != 0 {
+		t.Fatalf("cross-tenant delete affected %d rows", n)
+	}
+}
+
+func TestQueryWithoutTenantFails(t *testing.T) {
+	db := newTestDB(t)
+	var notes []note
+	err := db.WithContext(context.Background()).Find(&notes).Error
+	if !errors.Is(err, ErrMissingTenant) {
+		t.Fatalf("find without tenant: got %v, want ErrMissingTenant", err)
+	}
+	err = db.WithContext(context.Background()).Create(&note{Body: "a"}).Error
+	if !errors.Is(err, ErrMissingTenant) {
+		t.Fatalf("create without tenant: got %v, want ErrMissingTenant", err)
+	}
+	if err := db.WithContext(WithoutScope(context.Background())).Find(&notes).Error; err != nil {
+		t.Fatalf("find with scope skipped: %v", err)
+	}
+}
+
+func TestCreateForOtherTenantFails(t *testing.T) {
+	db := newTestDB(t)
+	ctxA := WithTenant(context.Background(), 1)
+	err := db.WithContext(ctxA).Create(&note{TenantID: 2, Body: "b"}).Error
+	if !errors.Is(err, ErrCrossTenant) {
+		t.Fatalf("got %v, want ErrCrossTenant", err)
+	}
+	err = db.WithContext(ctxA).Create([]note{{Body: "a"}, {TenantID: 2, Body: "b"}}).Error
+	if !errors.Is(err, ErrCrossTenant) {
+		t.Fatalf("batch: got %v, want ErrCrossTenant", err)
+	}
+
+	var count int64
+	if err := db.WithContext(WithoutScope(context.Background())).Model(&note{}).Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("rejected creates wrote %d rows", count)
+	}
+}
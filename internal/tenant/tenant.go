@@ -0,0 +1,43 @@
+// internal/tenant/tenant.go
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrMissingTenant = errors.New("tenant not found in context")
+	ErrCrossTenant   = errors.New("cross-tenant access denied")
+)
+
+type tenantKey struct{}
+
+type skipKey struct{}
+
+// WithTenant 将租户（组织）ID 写入上下文，之后的租户表查询都会按该租户过滤
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext 读取上下文中的租户 ID
+func FromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// WithoutScope 显式跳过租户过滤，仅用于跨租户的系统操作（如列出用户加入的全部组织）
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+func isScopeSkipped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	skipped, _ := ctx.Value(skipKey{}).(bool)
+	return skipped
+}
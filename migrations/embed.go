@@ -0,0 +1,29 @@
+// migrations/embed.go
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// Drivers 有迁移文件的数据库驱动，与 go:embed 中的目录一致
+var Drivers = []string{"mysql", "postgres", "sqlite"}
+
+// files 内嵌的版本化迁移文件，每种数据库驱动一个目录，
+// 命名格式为 <版本号>_<名称>.up.sql / .down.sql，各目录版本号保持一致
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For 返回指定驱动的迁移文件
+func For(driver string) (fs.FS, error) {
+	sub, err := fs.Sub(files, driver)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.ReadDir(sub, "."); err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return sub, nil
+}
@@ -0,0 +1,102 @@
+-- 初始表结构，与引入迁移前的模型定义保持一致
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at    DATETIME(3) NULL,
+    updated_at    DATETIME(3) NULL,
+    deleted_at    DATETIME(3) NULL,
+    username      VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NULL,
+    status        VARCHAR(255) NULL,
+    role          VARCHAR(32) NOT NULL DEFAULT 'user',
+    display_name  VARCHAR(64) NULL,
+    locale        VARCHAR(35) NULL,
+    timezone      VARCHAR(64) NULL,
+    avatar_url    VARCHAR(1024) NULL,
+    token_version BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT uni_users_status UNIQUE (status),
+    INDEX idx_users_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(64) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_organizations_slug UNIQUE (slug),
+    INDEX idx_organizations_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS memberships (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    tenant_id  BIGINT UNSIGNED NOT NULL,
+    user_id    BIGINT UNSIGNED NOT NULL,
+    role       VARCHAR(32) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_membership_tenant_user (tenant_id, user_id),
+    INDEX idx_memberships_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at  DATETIME(3) NULL,
+    updated_at  DATETIME(3) NULL,
+    deleted_at  DATETIME(3) NULL,
+    tenant_id   BIGINT UNSIGNED NOT NULL,
+    email       VARCHAR(255) NOT NULL,
+    role        VARCHAR(32) NOT NULL,
+    invited_by  BIGINT UNSIGNED NOT NULL,
+    nonce_hash  VARCHAR(64) NOT NULL,
+    expires_at  DATETIME(3) NOT NULL,
+    accepted_at DATETIME(3) NULL,
+    accepted_by BIGINT UNSIGNED NULL,
+    revoked_at  DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_invitations_tenant_id (tenant_id),
+    INDEX idx_invitations_email (email),
+    INDEX idx_invitations_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id   BIGINT UNSIGNED NOT NULL,
+    actor_id    BIGINT UNSIGNED NULL,
+    action      VARCHAR(64) NOT NULL,
+    target_type VARCHAR(64) NULL,
+    target_id   BIGINT UNSIGNED NULL,
+    metadata    TEXT NULL,
+    created_at  DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_audit_events_tenant_id (tenant_id),
+    INDEX idx_audit_events_actor_id (actor_id),
+    INDEX idx_audit_events_action (action),
+    INDEX idx_audit_events_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at     DATETIME(3) NULL,
+    updated_at     DATETIME(3) NULL,
+    deleted_at     DATETIME(3) NULL,
+    format         VARCHAR(16) NOT NULL,
+    status         VARCHAR(16) NOT NULL,
+    created_by     BIGINT UNSIGNED NOT NULL,
+    total_rows     BIGINT NULL,
+    succeeded_rows BIGINT NULL,
+    failed_rows    BIGINT NULL,
+    errors         MEDIUMTEXT NULL,
+    message        VARCHAR(1024) NULL,
+    finished_at    DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_import_jobs_status (status),
+    INDEX idx_import_jobs_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+-- 表中已有重复的 status 时回滚会失败，需要先人工处理数据
+DROP INDEX idx_users_status ON users;
+ALTER TABLE users MODIFY status VARCHAR(255) NULL;
+ALTER TABLE users ADD CONSTRAINT uni_users_status UNIQUE (status);
@@ -0,0 +1,5 @@
+-- status 是账户状态而非标识，唯一约束导致第二个 active 用户无法写入
+ALTER TABLE users DROP INDEX uni_users_status;
+UPDATE users SET status = 'active' WHERE status IS NULL;
+ALTER TABLE users MODIFY status VARCHAR(32) NOT NULL DEFAULT 'active';
+CREATE INDEX idx_users_status ON users (status);
@@ -0,0 +1,31 @@
+-- 领域事件 outbox 与死信表
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    event_id        VARCHAR(36) NOT NULL,
+    type            VARCHAR(128) NOT NULL,
+    aggregate_type  VARCHAR(64) NOT NULL,
+    aggregate_id    VARCHAR(64) NOT NULL,
+    payload         TEXT NOT NULL,
+    attempts        BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NOT NULL,
+    last_error      VARCHAR(1024) NULL,
+    created_at      DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_outbox_events_event_id (event_id),
+    INDEX idx_outbox_events_next_attempt_at (next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    event_id       VARCHAR(36) NOT NULL,
+    type           VARCHAR(128) NOT NULL,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id   VARCHAR(64) NOT NULL,
+    payload        TEXT NOT NULL,
+    attempts       BIGINT NOT NULL,
+    last_error     VARCHAR(1024) NULL,
+    occurred_at    DATETIME(3) NOT NULL,
+    created_at     DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_dead_letter_events_event_id (event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,55 @@
+-- 对外 webhook：端点、投递及每次请求的日志
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id                   BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at           DATETIME(3) NULL,
+    updated_at           DATETIME(3) NULL,
+    deleted_at           DATETIME(3) NULL,
+    tenant_id            BIGINT UNSIGNED NOT NULL,
+    url                  VARCHAR(2048) NOT NULL,
+    secret               VARCHAR(512) NOT NULL,
+    event_types          VARCHAR(1024) NOT NULL,
+    description          VARCHAR(255) NULL,
+    enabled              BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures BIGINT NOT NULL DEFAULT 0,
+    disabled_at          DATETIME(3) NULL,
+    disabled_reason      VARCHAR(255) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_webhook_endpoints_tenant_id (tenant_id),
+    INDEX idx_webhook_endpoints_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id        BIGINT UNSIGNED NOT NULL,
+    endpoint_id      BIGINT UNSIGNED NOT NULL,
+    event_id         VARCHAR(36) NOT NULL,
+    event_type       VARCHAR(128) NOT NULL,
+    payload          TEXT NOT NULL,
+    status           VARCHAR(16) NOT NULL,
+    attempts         BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME(3) NOT NULL,
+    last_status_code BIGINT NULL,
+    last_error       VARCHAR(1024) NULL,
+    delivered_at     DATETIME(3) NULL,
+    created_at       DATETIME(3) NULL,
+    updated_at       DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_webhook_delivery_endpoint_event (endpoint_id, event_id),
+    INDEX idx_webhook_deliveries_tenant_id (tenant_id),
+    INDEX idx_webhook_deliveries_status (status),
+    INDEX idx_webhook_deliveries_next_attempt_at (next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id     BIGINT UNSIGNED NOT NULL,
+    delivery_id   BIGINT UNSIGNED NOT NULL,
+    status_code   BIGINT NULL,
+    error         VARCHAR(1024) NULL,
+    response_body VARCHAR(1024) NULL,
+    duration_ms   BIGINT NULL,
+    created_at    DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_webhook_attempts_tenant_id (tenant_id),
+    INDEX idx_webhook_attempts_delivery_id (delivery_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,24 @@
+-- 定时任务状态及运行记录
+CREATE TABLE IF NOT EXISTS cron_jobs (
+    name       VARCHAR(64) NOT NULL,
+    paused     BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by BIGINT UNSIGNED NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    job_name     VARCHAR(64) NOT NULL,
+    `trigger`    VARCHAR(16) NOT NULL,
+    triggered_by BIGINT UNSIGNED NULL,
+    status       VARCHAR(16) NOT NULL,
+    scheduled_at DATETIME(3) NOT NULL,
+    started_at   DATETIME(3) NOT NULL,
+    finished_at  DATETIME(3) NULL,
+    duration_ms  BIGINT NOT NULL DEFAULT 0,
+    error        VARCHAR(1024) NULL,
+    instance     VARCHAR(255) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_cron_runs_job_started (job_name, started_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
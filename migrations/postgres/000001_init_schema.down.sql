@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS import_jobs;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS users;
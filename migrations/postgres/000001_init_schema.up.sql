@@ -0,0 +1,96 @@
+-- 初始表结构，与 MySQL 的 000001 保持一致
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGSERIAL PRIMARY KEY,
+    created_at    TIMESTAMPTZ NULL,
+    updated_at    TIMESTAMPTZ NULL,
+    deleted_at    TIMESTAMPTZ NULL,
+    username      VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NULL,
+    status        VARCHAR(255) NULL,
+    role          VARCHAR(32) NOT NULL DEFAULT 'user',
+    display_name  VARCHAR(64) NULL,
+    locale        VARCHAR(35) NULL,
+    timezone      VARCHAR(64) NULL,
+    avatar_url    VARCHAR(1024) NULL,
+    token_version BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT uni_users_status UNIQUE (status)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(64) NOT NULL,
+    CONSTRAINT uni_organizations_slug UNIQUE (slug)
+);
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    tenant_id  BIGINT NOT NULL,
+    user_id    BIGINT NOT NULL,
+    role       VARCHAR(32) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_membership_tenant_user ON memberships (tenant_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_memberships_deleted_at ON memberships (deleted_at);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ NULL,
+    updated_at  TIMESTAMPTZ NULL,
+    deleted_at  TIMESTAMPTZ NULL,
+    tenant_id   BIGINT NOT NULL,
+    email       VARCHAR(255) NOT NULL,
+    role        VARCHAR(32) NOT NULL,
+    invited_by  BIGINT NOT NULL,
+    nonce_hash  VARCHAR(64) NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ NULL,
+    accepted_by BIGINT NULL,
+    revoked_at  TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_tenant_id ON invitations (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+CREATE INDEX IF NOT EXISTS idx_invitations_deleted_at ON invitations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    tenant_id   BIGINT NOT NULL,
+    actor_id    BIGINT NULL,
+    action      VARCHAR(64) NOT NULL,
+    target_type VARCHAR(64) NULL,
+    target_id   BIGINT NULL,
+    metadata    TEXT NULL,
+    created_at  TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_id ON audit_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ NULL,
+    updated_at     TIMESTAMPTZ NULL,
+    deleted_at     TIMESTAMPTZ NULL,
+    format         VARCHAR(16) NOT NULL,
+    status         VARCHAR(16) NOT NULL,
+    created_by     BIGINT NOT NULL,
+    total_rows     BIGINT NULL,
+    succeeded_rows BIGINT NULL,
+    failed_rows    BIGINT NULL,
+    errors         TEXT NULL,
+    message        VARCHAR(1024) NULL,
+    finished_at    TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_deleted_at ON import_jobs (deleted_at);
@@ -0,0 +1,7 @@
+-- 表中已有重复的 status 时回滚会失败，需要先人工处理数据
+DROP INDEX idx_users_status;
+ALTER TABLE users
+    ALTER COLUMN status DROP NOT NULL,
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN status TYPE VARCHAR(255);
+ALTER TABLE users ADD CONSTRAINT uni_users_status UNIQUE (status);
@@ -0,0 +1,8 @@
+-- status 是账户状态而非标识，唯一约束导致第二个 active 用户无法写入
+ALTER TABLE users DROP CONSTRAINT uni_users_status;
+UPDATE users SET status = 'active' WHERE status IS NULL;
+ALTER TABLE users
+    ALTER COLUMN status TYPE VARCHAR(32),
+    ALTER COLUMN status SET DEFAULT 'active',
+    ALTER COLUMN status SET NOT NULL;
+CREATE INDEX idx_users_status ON users (status);
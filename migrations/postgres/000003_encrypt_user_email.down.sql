@@ -0,0 +1,5 @@
+-- 回滚前邮箱必须是明文，已加密的数据回滚后无法按邮箱查询
+DROP INDEX idx_users_email_index;
+ALTER TABLE users DROP COLUMN email_index;
+ALTER TABLE users ADD CONSTRAINT uni_users_email UNIQUE (email);
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
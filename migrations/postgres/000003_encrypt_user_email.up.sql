@@ -0,0 +1,6 @@
+-- 邮箱改为加密存储：密文更长，唯一约束移到盲索引列。
+-- 执行后运行 `server pii reencrypt` 加密已有数据并生成盲索引
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(512);
+ALTER TABLE users DROP CONSTRAINT uni_users_email;
+ALTER TABLE users ADD COLUMN email_index VARCHAR(64) NULL;
+CREATE UNIQUE INDEX idx_users_email_index ON users (email_index);
@@ -0,0 +1,29 @@
+-- 领域事件 outbox 与死信表
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        VARCHAR(36) NOT NULL,
+    type            VARCHAR(128) NOT NULL,
+    aggregate_type  VARCHAR(64) NOT NULL,
+    aggregate_id    VARCHAR(64) NOT NULL,
+    payload         TEXT NOT NULL,
+    attempts        BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      VARCHAR(1024) NULL,
+    created_at      TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_next_attempt_at ON outbox_events (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id             BIGSERIAL PRIMARY KEY,
+    event_id       VARCHAR(36) NOT NULL,
+    type           VARCHAR(128) NOT NULL,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id   VARCHAR(64) NOT NULL,
+    payload        TEXT NOT NULL,
+    attempts       BIGINT NOT NULL,
+    last_error     VARCHAR(1024) NULL,
+    occurred_at    TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dead_letter_events_event_id ON dead_letter_events (event_id);
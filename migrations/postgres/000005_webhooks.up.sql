@@ -0,0 +1,52 @@
+-- 对外 webhook：端点、投递及每次请求的日志
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id                   BIGSERIAL PRIMARY KEY,
+    created_at           TIMESTAMPTZ NULL,
+    updated_at           TIMESTAMPTZ NULL,
+    deleted_at           TIMESTAMPTZ NULL,
+    tenant_id            BIGINT NOT NULL,
+    url                  VARCHAR(2048) NOT NULL,
+    secret               VARCHAR(512) NOT NULL,
+    event_types          VARCHAR(1024) NOT NULL,
+    description          VARCHAR(255) NULL,
+    enabled              BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures BIGINT NOT NULL DEFAULT 0,
+    disabled_at          TIMESTAMPTZ NULL,
+    disabled_reason      VARCHAR(255) NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_tenant_id ON webhook_endpoints (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               BIGSERIAL PRIMARY KEY,
+    tenant_id        BIGINT NOT NULL,
+    endpoint_id      BIGINT NOT NULL,
+    event_id         VARCHAR(36) NOT NULL,
+    event_type       VARCHAR(128) NOT NULL,
+    payload          TEXT NOT NULL,
+    status           VARCHAR(16) NOT NULL,
+    attempts         BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL,
+    last_status_code BIGINT NULL,
+    last_error       VARCHAR(1024) NULL,
+    delivered_at     TIMESTAMPTZ NULL,
+    created_at       TIMESTAMPTZ NULL,
+    updated_at       TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_endpoint_event ON webhook_deliveries (endpoint_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant_id ON webhook_deliveries (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id            BIGSERIAL PRIMARY KEY,
+    tenant_id     BIGINT NOT NULL,
+    delivery_id   BIGINT NOT NULL,
+    status_code   BIGINT NULL,
+    error         VARCHAR(1024) NULL,
+    response_body VARCHAR(1024) NULL,
+    duration_ms   BIGINT NULL,
+    created_at    TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_tenant_id ON webhook_attempts (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id);
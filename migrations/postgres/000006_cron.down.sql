@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cron_runs;
+DROP TABLE IF EXISTS cron_jobs;
@@ -0,0 +1,22 @@
+-- 定时任务状态及运行记录
+CREATE TABLE IF NOT EXISTS cron_jobs (
+    name       VARCHAR(64) PRIMARY KEY,
+    paused     BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by BIGINT NULL,
+    updated_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id           BIGSERIAL PRIMARY KEY,
+    job_name     VARCHAR(64) NOT NULL,
+    "trigger"    VARCHAR(16) NOT NULL,
+    triggered_by BIGINT NULL,
+    status       VARCHAR(16) NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    started_at   TIMESTAMPTZ NOT NULL,
+    finished_at  TIMESTAMPTZ NULL,
+    duration_ms  BIGINT NOT NULL DEFAULT 0,
+    error        VARCHAR(1024) NULL,
+    instance     VARCHAR(255) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cron_runs_job_started ON cron_runs (job_name, started_at);
@@ -0,0 +1,97 @@
+-- 初始表结构，与 MySQL 的 000001 保持一致。
+-- SQLite 无法删除表内声明的约束，唯一约束统一以独立索引创建
+CREATE TABLE IF NOT EXISTS users (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at    DATETIME NULL,
+    updated_at    DATETIME NULL,
+    deleted_at    DATETIME NULL,
+    username      VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NULL,
+    status        VARCHAR(255) NULL,
+    role          VARCHAR(32) NOT NULL DEFAULT 'user',
+    display_name  VARCHAR(64) NULL,
+    locale        VARCHAR(35) NULL,
+    timezone      VARCHAR(64) NULL,
+    avatar_url    VARCHAR(1024) NULL,
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_users_status ON users (status);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(64) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uni_organizations_slug ON organizations (slug);
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    tenant_id  INTEGER NOT NULL,
+    user_id    INTEGER NOT NULL,
+    role       VARCHAR(32) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_membership_tenant_user ON memberships (tenant_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_memberships_deleted_at ON memberships (deleted_at);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at  DATETIME NULL,
+    updated_at  DATETIME NULL,
+    deleted_at  DATETIME NULL,
+    tenant_id   INTEGER NOT NULL,
+    email       VARCHAR(255) NOT NULL,
+    role        VARCHAR(32) NOT NULL,
+    invited_by  INTEGER NOT NULL,
+    nonce_hash  VARCHAR(64) NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    accepted_at DATETIME NULL,
+    accepted_by INTEGER NULL,
+    revoked_at  DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_tenant_id ON invitations (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+CREATE INDEX IF NOT EXISTS idx_invitations_deleted_at ON invitations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id   INTEGER NOT NULL,
+    actor_id    INTEGER NULL,
+    action      VARCHAR(64) NOT NULL,
+    target_type VARCHAR(64) NULL,
+    target_id   INTEGER NULL,
+    metadata    TEXT NULL,
+    created_at  DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_id ON audit_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at     DATETIME NULL,
+    updated_at     DATETIME NULL,
+    deleted_at     DATETIME NULL,
+    format         VARCHAR(16) NOT NULL,
+    status         VARCHAR(16) NOT NULL,
+    created_by     INTEGER NOT NULL,
+    total_rows     INTEGER NULL,
+    succeeded_rows INTEGER NULL,
+    failed_rows    INTEGER NULL,
+    errors         TEXT NULL,
+    message        VARCHAR(1024) NULL,
+    finished_at    DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_deleted_at ON import_jobs (deleted_at);
@@ -0,0 +1,3 @@
+-- 表中已有重复的 status 时回滚会失败，需要先人工处理数据
+DROP INDEX idx_users_status;
+CREATE UNIQUE INDEX uni_users_status ON users (status);
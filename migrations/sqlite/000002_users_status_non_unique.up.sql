@@ -0,0 +1,5 @@
+-- status 是账户状态而非标识，唯一约束导致第二个 active 用户无法写入。
+-- SQLite 不支持修改列定义，列保持可空，由应用保证写入状态
+DROP INDEX uni_users_status;
+UPDATE users SET status = 'active' WHERE status IS NULL;
+CREATE INDEX idx_users_status ON users (status);
@@ -0,0 +1,4 @@
+-- 回滚前邮箱必须是明文，已加密的数据回滚后无法按邮箱查询
+DROP INDEX idx_users_email_index;
+ALTER TABLE users DROP COLUMN email_index;
+CREATE UNIQUE INDEX uni_users_email ON users (email);
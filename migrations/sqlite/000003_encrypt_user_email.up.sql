@@ -0,0 +1,5 @@
+-- 邮箱改为加密存储，唯一约束移到盲索引列。SQLite 不限制 VARCHAR 长度，无需修改 email 列。
+-- 执行后运行 `server pii reencrypt` 加密已有数据并生成盲索引
+DROP INDEX uni_users_email;
+ALTER TABLE users ADD COLUMN email_index VARCHAR(64) NULL;
+CREATE UNIQUE INDEX idx_users_email_index ON users (email_index);
@@ -0,0 +1,22 @@
+-- 定时任务状态及运行记录
+CREATE TABLE IF NOT EXISTS cron_jobs (
+    name       VARCHAR(64) PRIMARY KEY,
+    paused     NUMERIC NOT NULL DEFAULT false,
+    updated_by INTEGER NULL,
+    updated_at DATETIME NULL
+);
+
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_name     VARCHAR(64) NOT NULL,
+    "trigger"    VARCHAR(16) NOT NULL,
+    triggered_by INTEGER NULL,
+    status       VARCHAR(16) NOT NULL,
+    scheduled_at DATETIME NOT NULL,
+    started_at   DATETIME NOT NULL,
+    finished_at  DATETIME NULL,
+    duration_ms  INTEGER NOT NULL DEFAULT 0,
+    error        VARCHAR(1024) NULL,
+    instance     VARCHAR(255) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cron_runs_job_started ON cron_runs (job_name, started_at);
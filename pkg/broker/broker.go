@@ -0,0 +1,39 @@
+// pkg/broker/broker.go
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-wire-demo/internal/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Message 发布到代理的事件
+type Message struct {
+	ID            string // 事件 ID，同一事件重复投递时不变
+	Type          string
+	AggregateType string
+	AggregateID   string
+	Payload       []byte
+	OccurredAt    time.Time
+}
+
+// Broker 消息代理。Publish 返回 nil 表示消息已被代理持久接收
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// NewBroker 按配置创建消息代理
+func NewBroker(cfg *config.Config, client redis.UniversalClient) (Broker, error) {
+	switch cfg.Events.Broker {
+	case config.BrokerRedis:
+		return NewRedisStreamBroker(client, cfg.Events.Stream, cfg.Events.StreamMaxLen), nil
+	case config.BrokerMemory:
+		return NewMemoryBroker(), nil
+	default:
+		return nil, fmt.Errorf("unsupported events broker %q", cfg.Events.Broker)
+	}
+}
@@ -0,0 +1,45 @@
+// pkg/broker/memory.go
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker 进程内代理，保存已发布的消息，用于测试和本地开发
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// Messages 返回已发布消息的副本
+func (b *MemoryBroker) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// FailWith 让后续发布都返回 err，传 nil 恢复正常，用于模拟代理故障
+func (b *MemoryBroker) FailWith(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
@@ -0,0 +1,41 @@
+// pkg/broker/redis_stream.go
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStreamBroker 将事件追加到 Redis Stream，消费方用消费组读取
+type RedisStreamBroker struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamBroker(client redis.UniversalClient, stream string, maxLen int64) *RedisStreamBroker {
+	return &RedisStreamBroker{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Publish 以 XADD 追加消息，超过 maxLen 时近似裁剪最旧的消息
+func (b *RedisStreamBroker) Publish(ctx context.Context, msg Message) error {
+	return b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: b.maxLen,
+		Approx: b.maxLen > 0,
+		Values: map[string]interface{}{
+			"event_id":       msg.ID,
+			"type":           msg.Type,
+			"aggregate_type": msg.AggregateType,
+			"aggregate_id":   msg.AggregateID,
+			"payload":        msg.Payload,
+			"occurred_at":    msg.OccurredAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
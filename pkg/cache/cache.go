@@ -0,0 +1,211 @@
+// pkg/cache/cache.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	redisx "gin-wire-demo/pkg/redis"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMiss 键不存在或已过期
+var ErrMiss = errors.New("cache: miss")
+
+// Store 缓存后端，按字节存取。ttl 为 0 表示不过期
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// TTL 返回剩余有效期，不过期的键返回 0，键不存在返回 ErrMiss
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// NewStore 按配置创建缓存后端。redis 后端按 redis.degrade.cache 降级，
+// 设置了 local_ttl 时在其前面加一层进程内缓存
+func NewStore(cfg *config.Config, client redis.UniversalClient, invalidator *Invalidator, health *redisx.HealthWatcher) (Store, error) {
+	switch cfg.Cache.Driver {
+	case config.CacheRedis:
+		remote := NewDegradingStore(
+			NewRedisStore(client, fmt.Sprintf("cache:%s:", cfg.App.Name)),
+			NewMemoryStore(cfg.Cache.MaxEntries),
+			health.Degrader(redisx.FeatureCache),
+		)
+		if cfg.Cache.LocalTTL <= 0 {
+			return remote, nil
+		}
+		return NewTieredStore(NewMemoryStore(cfg.Cache.MaxEntries), remote, cfg.Cache.LocalTTL, invalidator), nil
+	case config.CacheMemory:
+		return NewMemoryStore(cfg.Cache.MaxEntries), nil
+	case config.CacheNone:
+		return NewNoopStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver %q", cfg.Cache.Driver)
+	}
+}
+
+// Cache 在 Store 之上按命名空间存取 T 类型的值，值以 JSON 编码
+type Cache[T any] struct {
+	store     Store
+	namespace string
+	beta      float64
+	group     singleflight.Group
+}
+
+// entry 缓存中保存的值，附带提前刷新所需的过期时间和加载耗时
+type entry struct {
+	Value  json.RawMessage `json:"v"`
+	Expiry int64           `json:"exp,omitempty"`      // 过期时间，毫秒时间戳，0 表示不过期
+	Delta  int64           `json:"delta_us,omitempty"` // 上次加载耗时，微秒。命中内存或缓存的加载常不足 1ms
+}
+
+// Option 缓存选项
+type Option func(*options)
+
+type options struct {
+	beta float64
+}
+
+// EarlyRefresh 按 XFetch 算法在过期前随机提前刷新，beta 越大越早，1 为推荐值，0 表示不提前
+func EarlyRefresh(beta float64) Option {
+	return func(o *options) { o.beta = beta }
+}
+
+// New 创建命名空间为 namespace 的缓存，键为 namespace:key
+func New[T any](store Store, namespace string, opts ...Option) *Cache[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache[T]{store: store, namespace: namespace, beta: o.beta}
+}
+
+func (c *Cache[T]) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+// Get 读取缓存，未命中返回 ErrMiss；无法解码的值视为未命中并删除
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	value, _, err := c.get(ctx, key)
+	return value, err
+}
+
+func (c *Cache[T]) get(ctx context.Context, key string) (T, entry, error) {
+	var value T
+	var e entry
+	data, err := c.store.Get(ctx, c.key(key))
+	if err != nil {
+		return value, e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil || len(e.Value) == 0 {
+		_ = c.store.Delete(ctx, c.key(key))
+		return value, e, ErrMiss
+	}
+	if err := json.Unmarshal(e.Value, &value); err != nil {
+		_ = c.store.Delete(ctx, c.key(key))
+		return value, e, ErrMiss
+	}
+	return value, e, nil
+}
+
+// Set 写入缓存，ttl 为 0 表示不过期
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return c.set(ctx, key, value, ttl, 0)
+}
+
+func (c *Cache[T]) set(ctx context.Context, key string, value T, ttl, delta time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e := entry{Value: raw, Delta: delta.Microseconds()}
+	if ttl > 0 {
+		e.Expiry = time.Now().Add(ttl).UnixMilli()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, c.key(key), data, ttl)
+}
+
+// Loader 缓存未命中时加载值，返回值及其缓存时长，时长不大于 0 时不写入缓存
+type Loader[T any] func(ctx context.Context) (T, time.Duration, error)
+
+// Fetch 读取缓存，未命中时调用 load 加载并写入，第二个返回值表示是否来自缓存。
+// 同一实例上对同一个键的并发加载合并为一次；启用 EarlyRefresh 时，临近过期的值由某一次请求
+// 提前刷新，其他请求继续使用旧值。刷新失败时仍返回未过期的旧值；后端返回 ErrUnavailable 时不回源
+func (c *Cache[T]) Fetch(ctx context.Context, key string, load Loader[T]) (T, bool, error) {
+	value, e, err := c.get(ctx, key)
+	if errors.Is(err, ErrUnavailable) {
+		return value, false, err
+	}
+	if err == nil {
+		if !c.shouldRefresh(e) {
+			return value, true, nil
+		}
+		if fresh, err := c.load(ctx, key, load); err == nil {
+			return fresh, false, nil
+		}
+		return value, true, nil
+	}
+	fresh, err := c.load(ctx, key, load)
+	return fresh, false, err
+}
+
+// load 合并并发加载。加载不随单个调用方取消，调用方取消时只是不再等待
+func (c *Cache[T]) load(ctx context.Context, key string, load Loader[T]) (T, error) {
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		start := time.Now()
+		value, ttl, err := load(loadCtx)
+		if err != nil {
+			return value, err
+		}
+		if ttl > 0 {
+			// 耗时为 0 的条目不会提前刷新，至少按 1µs 记录
+			_ = c.set(loadCtx, key, value, ttl, max(time.Since(start), time.Microsecond))
+		}
+		return value, nil
+	})
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case res := <-ch:
+		value, _ := res.Val.(T)
+		return value, res.Err
+	}
+}
+
+// shouldRefresh XFetch：剩余时间小于 delta*beta*(-ln(rand)) 时提前刷新，
+// 越接近过期、加载越慢，提前刷新的概率越大
+func (c *Cache[T]) shouldRefresh(e entry) bool {
+	if c.beta <= 0 || e.Expiry == 0 || e.Delta <= 0 {
+		return false
+	}
+	gap := float64(e.Delta) * c.beta * -math.Log(1-rand.Float64())
+	return float64(time.Now().UnixMicro())+gap >= float64(e.Expiry)*1000
+}
+
+// Delete 删除缓存，键不存在时不报错
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.key(key)
+	}
+	return c.store.Delete(ctx, full...)
+}
+
+// TTL 剩余有效期
+func (c *Cache[T]) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.store.TTL(ctx, c.key(key))
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFetchRecordsSubMillisecondLoads(t *testing.T) {
+	store := NewMemoryStore(100)
+	ctx := context.Background()
+	// beta 足够大时，只要记录了加载耗时，下一次读取几乎必然提前刷新
+	c := New[int](store, "test", EarlyRefresh(1e12))
+
+	loads := 0
+	load := func(context.Context) (int, time.Duration, error) {
+		loads++
+		return loads, time.Minute, nil
+	}
+	if v, cached, err := c.Fetch(ctx, "k", load); err != nil || cached || v != 1 {
+		t.Fatalf("first fetch: v=%d cached=%v err=%v", v, cached, err)
+	}
+
+	data, err := store.Get(ctx, c.key("k"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Delta <= 0 {
+		t.Fatalf("delta = %d, want > 0", e.Delta)
+	}
+
+	if v, cached, err := c.Fetch(ctx, "k", load); err != nil || cached || v != 2 {
+		t.Fatalf("second fetch: v=%d cached=%v err=%v, want early refresh", v, cached, err)
+	}
+}
+
+func TestFetchWithoutEarlyRefreshUsesCache(t *testing.T) {
+	c := New[int](NewMemoryStore(100), "test")
+	ctx := context.Background()
+
+	loads := 0
+	load := func(context.Context) (int, time.Duration, error) {
+		loads++
+		return loads, time.Minute, nil
+	}
+	for i := 0; i < 3; i++ {
+		if _, _, err := c.Fetch(ctx, "k", load); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+}
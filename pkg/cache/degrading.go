@@ -0,0 +1,105 @@
+// pkg/cache/degrading.go
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	redisx "gin-wire-demo/pkg/redis"
+)
+
+// ErrUnavailable 缓存后端不可用且降级策略为 fail_closed
+var ErrUnavailable = errors.New("cache: unavailable")
+
+// DegradingStore 在 Redis 不可用时按降级策略处理：fail_open 视为未命中、写入直接丢弃，
+// fail_closed 返回 ErrUnavailable，local 改用进程内缓存
+type DegradingStore struct {
+	remote   Store
+	local    *MemoryStore
+	degrader *redisx.Degrader
+}
+
+func NewDegradingStore(remote Store, local *MemoryStore, degrader *redisx.Degrader) *DegradingStore {
+	return &DegradingStore{remote: remote, local: local, degrader: degrader}
+}
+
+func (s *DegradingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	var miss bool
+	if s.degrader.Call(func() (err error) {
+		data, err = s.remote.Get(ctx, key)
+		if errors.Is(err, ErrMiss) {
+			miss = true
+			return nil
+		}
+		return err
+	}) {
+		if miss {
+			return nil, ErrMiss
+		}
+		return data, nil
+	}
+	switch s.degrader.Policy() {
+	case config.DegradeLocal:
+		return s.local.Get(ctx, key)
+	case config.DegradeFailClosed:
+		return nil, ErrUnavailable
+	}
+	return nil, ErrMiss
+}
+
+func (s *DegradingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if s.degrader.Call(func() error {
+		return s.remote.Set(ctx, key, value, ttl)
+	}) {
+		return nil
+	}
+	switch s.degrader.Policy() {
+	case config.DegradeLocal:
+		return s.local.Set(ctx, key, value, ttl)
+	case config.DegradeFailClosed:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Delete 同时删除降级期间写入的本地条目。Redis 不可用时 Redis 中的旧值无法删除，恢复后最多保留到其过期
+func (s *DegradingStore) Delete(ctx context.Context, keys ...string) error {
+	_ = s.local.Delete(ctx, keys...)
+	if s.degrader.Call(func() error {
+		return s.remote.Delete(ctx, keys...)
+	}) {
+		return nil
+	}
+	if s.degrader.Policy() == config.DegradeFailClosed {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+func (s *DegradingStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var ttl time.Duration
+	var miss bool
+	if s.degrader.Call(func() (err error) {
+		ttl, err = s.remote.TTL(ctx, key)
+		if errors.Is(err, ErrMiss) {
+			miss = true
+			return nil
+		}
+		return err
+	}) {
+		if miss {
+			return 0, ErrMiss
+		}
+		return ttl, nil
+	}
+	switch s.degrader.Policy() {
+	case config.DegradeLocal:
+		return s.local.TTL(ctx, key)
+	case config.DegradeFailClosed:
+		return 0, ErrUnavailable
+	}
+	return 0, ErrMiss
+}
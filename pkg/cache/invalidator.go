@@ -0,0 +1,120 @@
+// pkg/cache/invalidator.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 订阅出错后重试的间隔
+const resubscribeInterval = time.Second
+
+// invalidation 频道中的消息
+type invalidation struct {
+	Origin string   `json:"origin"` // 发布方实例，收到自己发出的消息时忽略
+	Keys   []string `json:"keys"`
+}
+
+// Invalidator 通过 Redis 发布订阅在实例间广播失效的键，收到后清除本实例的 L1
+type Invalidator struct {
+	client     redis.UniversalClient
+	channel    string
+	origin     string
+	local      *MemoryStore
+	subscribed atomic.Bool
+	logger     logger.Logger
+}
+
+func NewInvalidator(client redis.UniversalClient, cfg *config.Config, logger logger.Logger) *Invalidator {
+	return &Invalidator{
+		client:  client,
+		channel: fmt.Sprintf("cache:%s:invalidate", cfg.App.Name),
+		origin:  uuid.NewString(),
+		logger:  logger.With(zap.String("module", "cache_invalidator")),
+	}
+}
+
+// watch 指定收到失效消息时清除的本地缓存，需在 Run 之前调用
+func (i *Invalidator) watch(local *MemoryStore) {
+	i.local = local
+}
+
+// Subscribed 是否正在接收失效消息。未订阅时可能错过其他实例的删除，本地缓存不可信
+func (i *Invalidator) Subscribed() bool {
+	return i.subscribed.Load()
+}
+
+// Publish 通知其他实例清除这些键
+func (i *Invalidator) Publish(ctx context.Context, keys ...string) error {
+	payload, err := json.Marshal(invalidation{Origin: i.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return i.client.Publish(ctx, i.channel, payload).Err()
+}
+
+// Run 订阅失效频道直到 ctx 取消。未启用本地缓存时直接返回
+func (i *Invalidator) Run(ctx context.Context) {
+	if i.local == nil {
+		return
+	}
+	pubsub := i.client.Subscribe(ctx, i.channel)
+	// Receive 阻塞读取时不响应 ctx，取消时关闭连接使其返回
+	stop := context.AfterFunc(ctx, func() { _ = pubsub.Close() })
+	defer stop()
+	defer i.subscribed.Store(false)
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// 断线期间的消息会丢失，重新订阅前停用本地缓存
+			if i.subscribed.Swap(false) {
+				i.logger.Warn("Cache invalidation subscription lost, local cache disabled", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(resubscribeInterval):
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				// 首次订阅或断线重连后，清空可能已过时的本地缓存再启用
+				i.local.Purge()
+				i.subscribed.Store(true)
+				i.logger.Info("Cache invalidation subscribed", zap.String("channel", i.channel))
+			}
+		case *redis.Message:
+			i.handle(m.Payload)
+		}
+	}
+}
+
+func (i *Invalidator) handle(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		// 无法识别的消息，保守起见清空本地缓存
+		i.logger.Warn("Invalid cache invalidation message", zap.Error(err))
+		i.local.Purge()
+		return
+	}
+	if msg.Origin == i.origin {
+		return
+	}
+	_ = i.local.Delete(context.Background(), msg.Keys...)
+}
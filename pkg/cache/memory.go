@@ -0,0 +1,123 @@
+// pkg/cache/memory.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内 LRU 缓存，超过 maxEntries 时淘汰最久未使用的键，过期的键在访问时清除。
+// 各实例互不共享，适合单实例开发环境和测试
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // 零值表示不过期
+}
+
+// NewMemoryStore maxEntries 不大于 0 时不限制条目数
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.lookup(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+	s.ll.MoveToFront(s.items[key])
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &memoryEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+	if el, ok := s.items[key]; ok {
+		el.Value = entry
+		s.ll.MoveToFront(el)
+		return nil
+	}
+	s.items[key] = s.ll.PushFront(entry)
+	if s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		s.remove(s.ll.Back())
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := s.items[key]; ok {
+			s.remove(el)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.lookup(key)
+	if !ok {
+		return 0, ErrMiss
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(s.now()), nil
+}
+
+// Purge 清空全部条目
+func (s *MemoryStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ll.Init()
+	s.items = make(map[string]*list.Element)
+}
+
+// Len 当前条目数，含尚未清除的过期条目
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// lookup 返回未过期的条目，过期的顺便删除。调用方需持有锁
+func (s *MemoryStore) lookup(key string) (*memoryEntry, bool) {
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		s.remove(el)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*memoryEntry).key)
+}
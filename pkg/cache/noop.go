@@ -0,0 +1,30 @@
+// pkg/cache/noop.go
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopStore 不缓存任何内容，所有读取都未命中，用于关闭缓存
+type NoopStore struct{}
+
+func NewNoopStore() NoopStore {
+	return NoopStore{}
+}
+
+func (NoopStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+func (NoopStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (NoopStore) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+func (NoopStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrMiss
+}
@@ -0,0 +1,60 @@
+// pkg/cache/redis.go
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStore 多个实例共享的缓存，键带 prefix 前缀
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrMiss
+	}
+	return data, err
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	// 逐个删除，集群模式下这些键可能不在同一个槽
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.prefix+key)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, s.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// go-redis 对这两个特殊值不乘精度：-2 键不存在，-1 未设置过期时间
+	switch ttl {
+	case -2:
+		return 0, ErrMiss
+	case -1:
+		return 0, nil
+	}
+	return ttl, nil
+}
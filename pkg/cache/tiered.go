@@ -0,0 +1,76 @@
+// pkg/cache/tiered.go
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TieredStore 两级缓存：进程内的 L1 在前，多实例共享的 L2 在后。
+// 写入和删除时通过 Invalidator 广播，其他实例随之清除 L1；订阅中断期间不使用 L1，避免读到已失效的值
+type TieredStore struct {
+	local       *MemoryStore
+	remote      Store
+	localTTL    time.Duration
+	invalidator *Invalidator
+}
+
+// NewTieredStore L1 中的条目最多保留 localTTL，这也是广播失败时其他实例可能读到旧值的最长时间
+func NewTieredStore(local *MemoryStore, remote Store, localTTL time.Duration, invalidator *Invalidator) *TieredStore {
+	invalidator.watch(local)
+	return &TieredStore{
+		local:       local,
+		remote:      remote,
+		localTTL:    localTTL,
+		invalidator: invalidator,
+	}
+}
+
+func (s *TieredStore) Get(ctx context.Context, key string) ([]byte, error) {
+	useLocal := s.invalidator.Subscribed()
+	if useLocal {
+		if data, err := s.local.Get(ctx, key); err == nil {
+			return data, nil
+		}
+	}
+	data, err := s.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if useLocal {
+		_ = s.local.Set(ctx, key, data, s.localTTL)
+	}
+	return data, nil
+}
+
+// Set 写入 L2 后通知其他实例清除 L1 中的旧值，下次读取时从 L2 取到新值
+func (s *TieredStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.remote.Set(ctx, key, value, ttl); err != nil {
+		_ = s.local.Delete(ctx, key)
+		return err
+	}
+	publishErr := s.invalidator.Publish(ctx, key)
+	if !s.invalidator.Subscribed() {
+		return publishErr
+	}
+	localTTL := s.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	return errors.Join(publishErr, s.local.Set(ctx, key, value, localTTL))
+}
+
+// Delete 删除 L2 和本实例的 L1，并通知其他实例清除各自的 L1
+func (s *TieredStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := s.remote.Delete(ctx, keys...)
+	_ = s.local.Delete(ctx, keys...)
+	return errors.Join(err, s.invalidator.Publish(ctx, keys...))
+}
+
+func (s *TieredStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.remote.TTL(ctx, key)
+}
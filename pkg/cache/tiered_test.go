@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// newTestInstances 模拟共用同一个 Redis 的多个实例，返回时各实例均已订阅失效频道
+func newTestInstances(t *testing.T, n int) []*TieredStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "test"},
+		Log: config.LogConfig{Level: "error"},
+	}
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	stores := make([]*TieredStore, n)
+	for i := range stores {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		invalidator := NewInvalidator(client, cfg, log)
+		stores[i] = NewTieredStore(NewMemoryStore(100), NewRedisStore(client, "cache:test:"), time.Minute, invalidator)
+		go invalidator.Run(ctx)
+	}
+	for _, s := range stores {
+		waitFor(t, s.invalidator.Subscribed)
+	}
+	return stores
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTieredStoreSetInvalidatesOtherInstances(t *testing.T) {
+	stores := newTestInstances(t, 2)
+	a, b := stores[0], stores[1]
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "k", []byte("v1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// b 读取后 L1 中缓存了 v1
+	if data, err := b.Get(ctx, "k"); err != nil || string(data) != "v1" {
+		t.Fatalf("b first get: %q, %v", data, err)
+	}
+	if err := a.Set(ctx, "k", []byte("v2"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, err := b.local.Get(ctx, "k")
+		return err != nil
+	})
+	if data, err := b.Get(ctx, "k"); err != nil || string(data) != "v2" {
+		t.Fatalf("b after set: %q, %v", data, err)
+	}
+	// 写入方自己的 L1 不会被自己的消息清除
+	if data, err := a.local.Get(ctx, "k"); err != nil || string(data) != "v2" {
+		t.Fatalf("a local: %q, %v", data, err)
+	}
+}
+
+func TestTieredStoreDeleteInvalidatesOtherInstances(t *testing.T) {
+	stores := newTestInstances(t, 2)
+	a, b := stores[0], stores[1]
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "k", []byte("v1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Get(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, err := b.Get(ctx, "k")
+		return err != nil
+	})
+}
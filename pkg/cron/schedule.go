@@ -0,0 +1,225 @@
+// pkg/cron/schedule.go
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 计算下一次触发时间
+type Schedule interface {
+	// Next 返回严格晚于 t 的下一次触发时间，没有时返回零值
+	Next(t time.Time) time.Time
+}
+
+// Parse 解析标准的 5 段 cron 表达式（分 时 日 月 周），
+// 支持 * , - / 及月份、星期的英文缩写，以及 @hourly、@daily、@weekly、@monthly、@yearly、@every <duration>。
+// loc 为计算触发时间使用的时区
+func Parse(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("invalid cron expression %q: interval must be at least 1s", expr)
+		}
+		return everySchedule{interval: d}, nil
+	}
+	if descriptor, ok := descriptors[expr]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields", expr)
+	}
+	s := &specSchedule{loc: loc}
+	var err error
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: minute: %w", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: hour: %w", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], days); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: day of month: %w", expr, err)
+	}
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: month: %w", expr, err)
+	}
+	if s.dow, err = parseField(fields[4], weekdays); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: day of week: %w", expr, err)
+	}
+	// 周日可写作 0 或 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	// 与标准 cron 一致，以 * 开头（如 */2）的日或星期字段视为不限定
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{min: 0, max: 59}
+	hours   = bounds{min: 0, max: 23}
+	days    = bounds{min: 1, max: 31}
+	months  = bounds{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	weekdays = bounds{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// parseField 将单个字段解析为位图，第 n 位表示值 n
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = b.min, b.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], b); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], b); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseValue(rangePart, b)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// 5/15 表示从 5 开始每 15
+			if step > 1 {
+				hi = b.max
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+type specSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// 日和星期都有限定时满足其一即可，与标准 cron 一致
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+func (s *specSchedule) Next(t time.Time) time.Time {
+	// 按绝对时间截断到分钟，夏令时结束时重复的一小时内也能正确前进
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	// 表达式可能永远不会触发（如 2 月 30 日），最多向后查找 5 年
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc), 24*time.Hour)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc), time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// forward 夏令时切换时 time.Date 构造的不存在的时刻可能被规范化到更早的时间，
+// 此时改为按绝对时长前进，保证查找不会停在原地
+func forward(t, next time.Time, step time.Duration) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(step)
+}
+
+func (s *specSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// everySchedule 固定间隔，按 Unix 纪元对齐，各实例算出的触发时间一致
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
@@ -0,0 +1,213 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestSpecScheduleNext(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	edt := time.FixedZone("EDT", -4*3600)
+	est := time.FixedZone("EST", -5*3600)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time // 从 from 起连续的触发时间
+	}{
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			from: time.Date(2026, 10, 19, 10, 7, 30, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 10, 19, 10, 15, 0, 0, ny),
+				time.Date(2026, 10, 19, 10, 30, 0, 0, ny),
+			},
+		},
+		{
+			name: "step from start value",
+			expr: "5/15 * * * *",
+			from: time.Date(2026, 10, 19, 10, 40, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 10, 19, 10, 50, 0, 0, ny),
+				time.Date(2026, 10, 19, 11, 5, 0, 0, ny),
+				time.Date(2026, 10, 19, 11, 20, 0, 0, ny),
+			},
+		},
+		{
+			name: "next is strictly after from",
+			expr: "0 12 * * *",
+			from: time.Date(2026, 10, 19, 12, 0, 0, 0, ny),
+			want: []time.Time{time.Date(2026, 10, 20, 12, 0, 0, 0, ny)},
+		},
+		{
+			name: "ranges lists and names",
+			expr: "0 9-17/4 * jan,OCT mon-fri",
+			from: time.Date(2026, 10, 30, 14, 0, 0, 0, ny), // 周五
+			want: []time.Time{
+				time.Date(2026, 10, 30, 17, 0, 0, 0, ny),
+				time.Date(2027, 1, 1, 9, 0, 0, 0, ny), // 周五
+				time.Date(2027, 1, 1, 13, 0, 0, 0, ny),
+			},
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * 5",
+			from: time.Date(2026, 12, 5, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 12, 11, 0, 0, 0, 0, ny), // 周五
+				time.Date(2026, 12, 13, 0, 0, 0, 0, ny), // 13 日，周日
+				time.Date(2026, 12, 18, 0, 0, 0, 0, ny),
+			},
+		},
+		{
+			name: "day of month with star step and day of week",
+			expr: "0 0 */2 * 1",
+			from: time.Date(2026, 10, 18, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 10, 19, 0, 0, 0, 0, ny), // 单日的周一
+				time.Date(2026, 11, 9, 0, 0, 0, 0, ny),
+				time.Date(2026, 11, 23, 0, 0, 0, 0, ny),
+			},
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 0 * * 7",
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, ny),
+			want: []time.Time{time.Date(2026, 10, 25, 0, 0, 0, 0, ny)},
+		},
+		{
+			name: "leap day",
+			expr: "0 0 29 2 *",
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, ny),
+			want: []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, ny)},
+		},
+		{
+			name: "never fires within five years",
+			expr: "0 0 30 2 *",
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, ny),
+			want: []time.Time{{}},
+		},
+		{
+			name: "descriptor",
+			expr: "@weekly",
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, ny),
+			want: []time.Time{time.Date(2026, 10, 25, 0, 0, 0, 0, ny)},
+		},
+		{
+			// 2026-03-08 02:00 EST 跳到 03:00 EDT
+			name: "hourly across spring forward",
+			expr: "0 * * * *",
+			from: time.Date(2026, 3, 8, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 1, 0, 0, 0, est),
+				time.Date(2026, 3, 8, 3, 0, 0, 0, edt),
+				time.Date(2026, 3, 8, 4, 0, 0, 0, edt),
+			},
+		},
+		{
+			// 不存在的时刻当天不触发
+			name: "skipped time on spring forward",
+			expr: "30 2 * * *",
+			from: time.Date(2026, 3, 7, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 9, 2, 30, 0, 0, edt),
+				time.Date(2026, 3, 10, 2, 30, 0, 0, edt),
+			},
+		},
+		{
+			// 2026-11-01 02:00 EDT 回拨到 01:00 EST，每小时都触发
+			name: "hourly across fall back",
+			expr: "0 * * * *",
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, edt),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 1, 0, 0, 0, edt),
+				time.Date(2026, 11, 1, 1, 0, 0, 0, est),
+				time.Date(2026, 11, 1, 2, 0, 0, 0, est),
+			},
+		},
+		{
+			// 重复的一小时内的时刻按两个绝对时间各触发一次
+			name: "repeated time on fall back",
+			expr: "30 1 * * *",
+			from: time.Date(2026, 11, 1, 0, 0, 0, 0, edt),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 1, 30, 0, 0, edt),
+				time.Date(2026, 11, 1, 1, 30, 0, 0, est),
+				time.Date(2026, 11, 2, 1, 30, 0, 0, est),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := tt.from
+			for i, want := range tt.want {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next #%d after %v = %v, want %v", i+1, from, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestEveryScheduleIsAlignedToEpoch(t *testing.T) {
+	s, err := Parse("@every 90m", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 10, 19, 10, 7, 0, 0, time.UTC)
+	got := s.Next(from)
+	if got.Unix()%int64((90*time.Minute).Seconds()) != 0 {
+		t.Fatalf("Next = %v, not aligned to 90m since epoch", got)
+	}
+	if !got.After(from) || got.Sub(from) > 90*time.Minute {
+		t.Fatalf("Next = %v, want within 90m after %v", got, from)
+	}
+	// 时区不影响触发时刻，各实例一致
+	ny := mustLoadLocation(t, "America/New_York")
+	if other := s.Next(from.In(ny)); !other.Equal(got) {
+		t.Fatalf("Next in New York = %v, want %v", other, got)
+	}
+	if next := s.Next(got); next.Sub(got) != 90*time.Minute {
+		t.Fatalf("interval = %v, want 90m", next.Sub(got))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 500ms",
+		"@every soon",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr, time.UTC); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
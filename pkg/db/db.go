@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/tenant"
+	"gin-wire-demo/pkg/fieldcrypt"
+	"gin-wire-demo/pkg/logger"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// NewDB 按 database.driver 打开数据库连接，SQL 日志写入 logger，
+// 敏感字段使用 keyring 加密
+func NewDB(cfg *config.Config, logger logger.Logger, keyring *fieldcrypt.Keyring) (*gorm.DB, func(), error) {
+	dialector, err := Dialector(cfg.Database, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 添加连接超时context
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		// 可以添加更多GORM配置
+		PrepareStmt: true, // 开启预编译语句
+		Logger:      newGormLogger(cfg, logger),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// 注册字段加密序列化器
+	fieldcrypt.Register(keyring)
+
+	// 注册租户隔离插件
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		return nil, nil, fmt.Errorf("failed to register tenant plugin: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	// 配置了从库时启用读写分离
+	var replicas *ReplicaRouter
+	if len(cfg.Database.Replicas) > 0 {
+		if replicas, err = newReplicaRouter(cfg, logger); err != nil {
+			_ = sqlDB.Close()
+			return nil, nil, err
+		}
+		if err := db.Use(replicas); err != nil {
+			replicas.Close()
+			_ = sqlDB.Close()
+			return nil, nil, fmt.Errorf("failed to register replica router: %w", err)
+		}
+	}
+
+	// 添加连接测试
+	if err := sqlDB.PingContext(ctx); err != nil {
+		if replicas != nil {
+			replicas.Close()
+		}
+		_ = sqlDB.Close()
+		return nil, nil, fmt.Errorf("database ping failed: %w", err)
+	}
+
+	configurePool(cfg, sqlDB)
+
+	cleanup := func() {
+		if replicas != nil {
+			replicas.Close()
+		}
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("failed to close database connection: %v", err)
+		}
+	}
+
+	return db, cleanup, nil
+}
+
+// NewScriptDB 打开用于执行 SQL 脚本（如迁移）的连接，单条 Exec 可包含多条语句
+func NewScriptDB(cfg *config.Config) (*sql.DB, error) {
+	dialector, err := Dialector(cfg.Database, true)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+	configurePool(cfg, sqlDB)
+	return sqlDB, nil
+}
+
+// Dialector 返回驱动对应的 GORM 方言，multiStatements 为真时允许单次执行多条语句
+func Dialector(dbc config.DatabaseConfig, multiStatements bool) (gorm.Dialector, error) {
+	switch dbc.Driver {
+	case config.DriverMySQL:
+		dsn := MySQLDSN(dbc)
+		if multiStatements {
+			dsn += "&multiStatements=true"
+		}
+		return newMySQLDialector(dsn), nil
+	case config.DriverPostgres:
+		// pgx 对无参数的 Exec 使用简单协议，本身支持多条语句
+		return newPostgresDialector(PostgresDSN(dbc)), nil
+	case config.DriverSQLite:
+		return newSQLiteDialector(SQLiteDSN(dbc)), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", dbc.Driver)
+	}
+}
+
+// configurePool 使用配置中的连接池参数，sqlite 只允许单个连接
+func configurePool(cfg *config.Config, sqlDB *sql.DB) {
+	if cfg.Database.Driver == config.DriverSQLite {
+		// 写操作本就串行，单连接还能保证 :memory: 库在各连接间共享
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		return
+	}
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
+}
+
+func newGormLogger(cfg *config.Config, log logger.Logger) gormlogger.Interface {
+	return logger.NewGormLogger(log, cfg)
+}
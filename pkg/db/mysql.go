@@ -1,58 +1,30 @@
 package db
 
 import (
-	"context"
 	"fmt"
 	"gin-wire-demo/internal/config"
-	"log"
-	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-func NewMySQL(cfg *config.Config) (*gorm.DB, func(), error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.Username,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DBname,
-	)
-
-	// 添加连接超时context
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		// 可以添加更多GORM配置
-		PrepareStmt: true, // 开启预编译语句
-	})
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get sql.DB: %w", err)
-	}
+const defaultMySQLPort = 3306
 
-	// 添加连接测试
-	if err := sqlDB.PingContext(ctx); err != nil {
-		_ = sqlDB.Close()
-		return nil, nil, fmt.Errorf("database ping failed: %w", err)
-	}
-
-	// 使用配置中的连接池参数
-	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
-
-	cleanup := func() {
-		if err := sqlDB.Close(); err != nil {
-			log.Printf("failed to close database connection: %v", err)
-		}
+// MySQLDSN 根据配置生成连接串
+func MySQLDSN(dbc config.DatabaseConfig) string {
+	port := dbc.Port
+	if port == 0 {
+		port = defaultMySQLPort
 	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		dbc.Username,
+		dbc.Password,
+		dbc.Host,
+		port,
+		dbc.DBname,
+	)
+}
 
-	return db, cleanup, nil
+func newMySQLDialector(dsn string) gorm.Dialector {
+	return mysql.Open(dsn)
 }
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"fmt"
+	"gin-wire-demo/internal/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const defaultPostgresPort = 5432
+
+// PostgresDSN 根据配置生成连接串
+func PostgresDSN(dbc config.DatabaseConfig) string {
+	port := dbc.Port
+	if port == 0 {
+		port = defaultPostgresPort
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		dbc.Host,
+		port,
+		dbc.Username,
+		dbc.Password,
+		dbc.DBname,
+		dbc.SSLMode,
+	)
+}
+
+func newPostgresDialector(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type routingKey struct{}
+
+// routing 单个请求的读路由状态
+type routing struct {
+	primary atomic.Bool
+	wrote   atomic.Bool
+}
+
+// WithReadYourWrites 开启读写一致：同一上下文发生写操作后，后续读请求都走主库
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(routingKey{}).(*routing); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, routingKey{}, &routing{})
+}
+
+// UsePrimary 强制该上下文中的读操作走主库
+func UsePrimary(ctx context.Context) context.Context {
+	r := &routing{}
+	r.primary.Store(true)
+	return context.WithValue(ctx, routingKey{}, r)
+}
+
+// StickToPrimary 让已开启读写一致的上下文此后的读操作都走主库，
+// 用于此前的请求刚写过数据、从库可能尚未同步的情况
+func StickToPrimary(ctx context.Context) {
+	if r, ok := ctx.Value(routingKey{}).(*routing); ok {
+		r.primary.Store(true)
+	}
+}
+
+// HasWritten 开启读写一致的上下文中是否发生过写操作
+func HasWritten(ctx context.Context) bool {
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	return ok && r.wrote.Load()
+}
+
+func readFromPrimary(ctx context.Context) bool {
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	return ok && r.primary.Load()
+}
+
+// replica 只读从库及其健康状态
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaRouter GORM 插件：事务外的查询轮询分发到健康的从库，
+// 写操作、事务内的查询和加锁查询都走主库。没有可用从库时回退主库
+type ReplicaRouter struct {
+	driver   string
+	replicas []*replica
+	next     atomic.Uint64
+	maxLag   time.Duration
+	interval time.Duration
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+	logger   logger.Logger
+}
+
+// newReplicaRouter 打开配置的从库并开始健康检查
+func newReplicaRouter(cfg *config.Config, logger logger.Logger) (*ReplicaRouter, error) {
+	router := &ReplicaRouter{
+		logger:   logger.With(zap.String("module", "replica_router")),
+		driver:   cfg.Database.Driver,
+		maxLag:   cfg.Database.ReplicaMaxLag,
+		interval: cfg.Database.ReplicaCheckInterval,
+	}
+	for _, rc := range cfg.Database.Replicas {
+		dbc := cfg.Database.ForReplica(rc)
+		sqlDB, err := openReplica(dbc)
+		if err != nil {
+			router.Close()
+			return nil, fmt.Errorf("failed to open replica %s: %w", rc.Host, err)
+		}
+		sqlDB.SetMaxIdleConns(dbc.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(dbc.MaxOpenConns)
+		sqlDB.SetConnMaxLifetime(time.Duration(dbc.ConnMaxLifetime) * time.Second)
+		router.replicas = append(router.replicas, &replica{
+			name: rc.Host,
+			db:   sqlDB,
+		})
+	}
+
+	// 启动前先检查一次，避免在确认健康前就分发流量
+	ctx, cancel := context.WithCancel(context.Background())
+	router.stop = cancel
+	router.checkAll(ctx)
+	router.wg.Add(1)
+	go router.healthLoop(ctx)
+	return router, nil
+}
+
+func openReplica(dbc config.DatabaseConfig) (*sql.DB, error) {
+	dialector, err := Dialector(dbc, false)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return db.DB()
+}
+
+func (r *ReplicaRouter) Name() string {
+	return "replica_router"
+}
+
+func (r *ReplicaRouter) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("replica:route", r.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("replica:route", r.route); err != nil {
+		return err
+	}
+	// 写操作后将同一上下文标记为读主库
+	if err := db.Callback().Create().After("gorm:create").Register("replica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("replica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("replica:mark_write", markWrite); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("replica:mark_write", markWrite)
+}
+
+func (r *ReplicaRouter) route(db *gorm.DB) {
+	stmt := db.Statement
+	if _, inTx := stmt.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	if _, locking := stmt.Clauses[clause.Locking{}.Name()]; locking {
+		return
+	}
+	if stmt.Context != nil && readFromPrimary(stmt.Context) {
+		return
+	}
+	if pool := r.pick(); pool != nil {
+		stmt.ConnPool = pool
+	}
+}
+
+func markWrite(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	if r, ok := db.Statement.Context.Value(routingKey{}).(*routing); ok {
+		r.primary.Store(true)
+		r.wrote.Store(true)
+	}
+}
+
+// pick 在健康的从库间轮询，全部不可用时返回 nil
+func (r *ReplicaRouter) pick() *sql.DB {
+	n := len(r.replicas)
+	start := r.next.Add(1)
+	for i := 0; i < n; i++ {
+		rep := r.replicas[(start+uint64(i))%uint64(n)]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return nil
+}
+
+func (r *ReplicaRouter) healthLoop(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkAll(ctx)
+		}
+	}
+}
+
+func (r *ReplicaRouter) checkAll(ctx context.Context) {
+	for _, rep := range r.replicas {
+		r.check(ctx, rep)
+	}
+}
+
+// check 探测连通性和复制延迟，延迟超过阈值的从库暂时移出轮询
+func (r *ReplicaRouter) check(ctx context.Context, rep *replica) {
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+
+	lag, err := replicationLag(ctx, r.driver, rep.db)
+	healthy := err == nil && lag <= r.maxLag
+	if was := rep.healthy.Swap(healthy); was != healthy {
+		log := r.logger.With(zap.String("replica", rep.name), zap.Duration("lag", lag))
+		switch {
+		case healthy:
+			log.Info("replica back in rotation")
+		case err != nil:
+			log.Warn("replica removed from rotation", zap.Error(err))
+		default:
+			log.Warn("replica removed from rotation: lag exceeds threshold", zap.Duration("max_lag", r.maxLag))
+		}
+	}
+}
+
+// Close 停止健康检查并关闭从库连接
+func (r *ReplicaRouter) Close() {
+	if r.stop != nil {
+		r.stop()
+	}
+	r.wg.Wait()
+	for _, rep := range r.replicas {
+		if err := rep.db.Close(); err != nil {
+			r.logger.Warn("close replica failed", zap.String("replica", rep.name), zap.Error(err))
+		}
+	}
+}
+
+// replicationLag 查询从库的复制延迟
+func replicationLag(ctx context.Context, driver string, db *sql.DB) (time.Duration, error) {
+	switch driver {
+	case config.DriverMySQL:
+		return mysqlReplicationLag(ctx, db)
+	case config.DriverPostgres:
+		// 已回放到最新位置时延迟为 0，否则以最后回放事务的时间计算
+		var seconds sql.NullFloat64
+		err := db.QueryRowContext(ctx, `SELECT CASE
+    WHEN NOT pg_is_in_recovery() THEN 0
+    WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END`).Scan(&seconds)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds.Float64 * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("replicas are not supported for driver %q", driver)
+	}
+}
+
+// mysqlReplicationLag 读取 SHOW REPLICA STATUS 的 Seconds_Behind_Source，
+// 复制线程停止时该值为 NULL，视为不可用
+func mysqlReplicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("server is not a replica")
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" {
+			continue
+		}
+		if values[i] == nil {
+			return 0, fmt.Errorf("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("replica lag not reported")
+}
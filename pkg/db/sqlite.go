@@ -0,0 +1,19 @@
+package db
+
+import (
+	"gin-wire-demo/internal/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLiteDSN 根据配置生成连接串。使用纯 Go 实现的驱动，无需 cgo，
+// 测试可直接在进程内运行
+func SQLiteDSN(dbc config.DatabaseConfig) string {
+	// 开启外键约束，并在文件被锁时等待而不是立即失败
+	return dbc.DBname + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+func newSQLiteDialector(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
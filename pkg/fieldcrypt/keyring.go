@@ -0,0 +1,143 @@
+// pkg/fieldcrypt/keyring.go
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gin-wire-demo/internal/config"
+)
+
+const keySize = 32 // AES-256
+
+var (
+	ErrUnknownKeyVersion = errors.New("unknown encryption key version")
+	ErrMalformed         = errors.New("malformed ciphertext")
+)
+
+// Keyring 保存按版本区分的数据密钥。新数据总是用当前版本加密，
+// 旧版本只用于解密，直到轮换命令把数据重新加密
+type Keyring struct {
+	active   int
+	aeads    map[int]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring 从配置加载密钥，密钥为 base64 编码的 32 字节随机数
+func NewKeyring(cfg *config.Config) (*Keyring, error) {
+	kr := &Keyring{
+		active: cfg.PII.ActiveKey,
+		aeads:  make(map[int]cipher.AEAD, len(cfg.PII.Keys)),
+	}
+	for _, k := range cfg.PII.Keys {
+		raw, err := decodeKey(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("pii key version %d: %w", k.Version, err)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.aeads[k.Version] = aead
+	}
+	if _, ok := kr.aeads[kr.active]; !ok {
+		return nil, fmt.Errorf("pii active key version %d is not configured", kr.active)
+	}
+
+	indexKey, err := decodeKey(cfg.PII.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("pii index key: %w", err)
+	}
+	kr.indexKey = indexKey
+	return kr, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(raw))
+	}
+	return raw, nil
+}
+
+// ActiveVersion 当前用于加密的密钥版本
+func (k *Keyring) ActiveVersion() int {
+	return k.active
+}
+
+// Encrypt 使用当前密钥加密，输出格式为 v<版本>:<base64(nonce|密文)>
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.aeads[k.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "v" + strconv.Itoa(k.active) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 按密文中的版本号选择密钥解密
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	version, payload, ok := parseEnvelope(ciphertext)
+	if !ok {
+		return "", ErrMalformed
+	}
+	aead, ok := k.aeads[version]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// KeyVersion 返回密文使用的密钥版本，未加密的旧数据返回 false
+func KeyVersion(value string) (int, bool) {
+	version, _, ok := parseEnvelope(value)
+	return version, ok
+}
+
+func parseEnvelope(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, "v") {
+		return 0, "", false
+	}
+	prefix, payload, found := strings.Cut(value[1:], ":")
+	if !found {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, "", false
+	}
+	return version, payload, true
+}
+
+// BlindIndex 计算确定性的盲索引，用于等值查询和唯一约束。
+// 先做规范化（去空白、转小写），因此查询不区分大小写
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
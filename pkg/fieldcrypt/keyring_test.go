@@ -0,0 +1,185 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"gin-wire-demo/internal/config"
+)
+
+func randomKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// testKeys 按版本保存的数据密钥，同一组密钥可以构造出轮换前后的 Keyring
+type testKeys struct {
+	data  map[int]string
+	index string
+}
+
+func newTestKeys(t *testing.T, versions ...int) testKeys {
+	keys := testKeys{data: make(map[int]string), index: randomKey(t)}
+	for _, v := range versions {
+		keys.data[v] = randomKey(t)
+	}
+	return keys
+}
+
+// keyring 使用 versions 中的密钥，active 为当前版本
+func (k testKeys) keyring(t *testing.T, active int, versions ...int) *Keyring {
+	t.Helper()
+	cfg := &config.Config{PII: config.PIIConfig{ActiveKey: active, IndexKey: k.index}}
+	for _, v := range versions {
+		cfg.PII.Keys = append(cfg.PII.Keys, config.PIIKey{Version: v, Key: k.data[v]})
+	}
+	kr, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	kr := newTestKeys(t, 1).keyring(t, 1, 1)
+	for _, plaintext := range []string{"", "alice@example.com", "张三 <zhangsan@例子.公司>", strings.Repeat("x", 4096)} {
+		ciphertext, err := kr.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(ciphertext, "v1:") {
+			t.Fatalf("ciphertext %q has no version prefix", ciphertext)
+		}
+		if plaintext != "" && strings.Contains(ciphertext, plaintext) {
+			t.Fatal("ciphertext contains plaintext")
+		}
+		if version, ok := KeyVersion(ciphertext); !ok || version != 1 {
+			t.Fatalf("KeyVersion = %d, %v", version, ok)
+		}
+		got, err := kr.Decrypt(ciphertext)
+		if err != nil || got != plaintext {
+			t.Fatalf("Decrypt = %q, %v, want %q", got, err, plaintext)
+		}
+	}
+
+	// 随机 nonce，相同明文每次加密结果不同
+	a, _ := kr.Encrypt("alice@example.com")
+	b, _ := kr.Encrypt("alice@example.com")
+	if a == b {
+		t.Fatal("encryption is deterministic")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	keys := newTestKeys(t, 1, 2)
+	before := keys.keyring(t, 1, 1)
+	oldCiphertext, err := before.Encrypt("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换期间：新数据用版本 2 加密，旧密文仍可用版本 1 解密
+	during := keys.keyring(t, 2, 1, 2)
+	if during.ActiveVersion() != 2 {
+		t.Fatalf("ActiveVersion = %d, want 2", during.ActiveVersion())
+	}
+	if got, err := during.Decrypt(oldCiphertext); err != nil || got != "alice@example.com" {
+		t.Fatalf("decrypt old ciphertext = %q, %v", got, err)
+	}
+	newCiphertext, err := during.Encrypt("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := KeyVersion(newCiphertext); version != 2 {
+		t.Fatalf("new ciphertext uses version %d, want 2", version)
+	}
+
+	// 版本 1 下线后，未重新加密的旧密文无法解密
+	after := keys.keyring(t, 2, 2)
+	if _, err := after.Decrypt(oldCiphertext); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("decrypt with retired key: got %v, want ErrUnknownKeyVersion", err)
+	}
+	if got, err := after.Decrypt(newCiphertext); err != nil || got != "alice@example.com" {
+		t.Fatalf("decrypt new ciphertext = %q, %v", got, err)
+	}
+
+	// 盲索引使用独立的密钥，不受数据密钥轮换影响
+	if before.BlindIndex("alice@example.com") != after.BlindIndex("alice@example.com") {
+		t.Fatal("blind index changed with data key rotation")
+	}
+}
+
+func TestDecryptRejectsInvalidCiphertext(t *testing.T) {
+	keys := newTestKeys(t, 1)
+	kr := keys.keyring(t, 1, 1)
+	ciphertext, err := kr.Encrypt("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(ciphertext, "v1:"))
+	sealed[len(sealed)-1] ^= 1
+	tampered := "v1:" + base64.RawStdEncoding.EncodeToString(sealed)
+	if _, err := kr.Decrypt(tampered); err == nil {
+		t.Fatal("tampered ciphertext decrypted")
+	}
+
+	// 版本号相同但密钥不同
+	other := newTestKeys(t, 1).keyring(t, 1, 1)
+	if _, err := other.Decrypt(ciphertext); err == nil {
+		t.Fatal("decrypted with a different key")
+	}
+
+	for _, value := range []string{"alice@example.com", "v1", "vx:abc", "v1:not base64!", "v1:AAAA"} {
+		if _, err := kr.Decrypt(value); !errors.Is(err, ErrMalformed) {
+			t.Errorf("Decrypt(%q) = %v, want ErrMalformed", value, err)
+		}
+	}
+	if _, ok := KeyVersion("alice@example.com"); ok {
+		t.Fatal("plaintext reported as encrypted")
+	}
+}
+
+func TestNewKeyringRejectsInvalidConfig(t *testing.T) {
+	key := randomKey(t)
+	short := base64.StdEncoding.EncodeToString(make([]byte, 16))
+	tests := map[string]config.PIIConfig{
+		"active key missing": {ActiveKey: 2, Keys: []config.PIIKey{{Version: 1, Key: key}}, IndexKey: key},
+		"invalid base64":     {ActiveKey: 1, Keys: []config.PIIKey{{Version: 1, Key: "not base64"}}, IndexKey: key},
+		"short key":          {ActiveKey: 1, Keys: []config.PIIKey{{Version: 1, Key: short}}, IndexKey: key},
+		"missing index key":  {ActiveKey: 1, Keys: []config.PIIKey{{Version: 1, Key: key}}},
+	}
+	for name, pii := range tests {
+		if _, err := NewKeyring(&config.Config{PII: pii}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keys := newTestKeys(t, 1)
+	kr := keys.keyring(t, 1, 1)
+	index := kr.BlindIndex("alice@example.com")
+	if len(index) != 64 {
+		t.Fatalf("index %q is not hex sha256", index)
+	}
+	// 规范化后相同的值索引相同
+	for _, value := range []string{"Alice@Example.com", "  alice@example.com\t"} {
+		if got := kr.BlindIndex(value); got != index {
+			t.Errorf("BlindIndex(%q) differs from the normalized value", value)
+		}
+	}
+	if kr.BlindIndex("bob@example.com") == index {
+		t.Fatal("different values share an index")
+	}
+	// 不同的索引密钥得到不同的索引，泄露的索引无法跨环境比对
+	if newTestKeys(t, 1).keyring(t, 1, 1).BlindIndex("alice@example.com") == index {
+		t.Fatal("index does not depend on the index key")
+	}
+}
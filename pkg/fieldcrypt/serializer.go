@@ -0,0 +1,58 @@
+// pkg/fieldcrypt/serializer.go
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName 模型字段使用 `gorm:"serializer:encrypted"` 启用加密
+const SerializerName = "encrypted"
+
+// Serializer GORM 序列化器，写入时加密、读取时解密字符串字段
+type Serializer struct {
+	keyring *Keyring
+}
+
+// Register 注册加密序列化器。GORM 的序列化器是全局的，应在首次查询前调用
+func Register(keyring *Keyring) {
+	schema.RegisterSerializer(SerializerName, &Serializer{keyring: keyring})
+}
+
+func (s *Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("unsupported encrypted column value %T", dbValue)
+	}
+
+	plaintext := stored
+	// 未带版本前缀的是加密上线前写入的明文，原样返回，等待轮换命令加密
+	if _, ok := KeyVersion(stored); ok {
+		var err error
+		if plaintext, err = s.keyring.Decrypt(stored); err != nil {
+			return fmt.Errorf("decrypt %s: %w", field.Name, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (s *Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be a string, got %T", field.Name, fieldValue)
+	}
+	if plaintext == "" {
+		return "", nil
+	}
+	return s.keyring.Encrypt(plaintext)
+}
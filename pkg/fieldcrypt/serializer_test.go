@@ -0,0 +1,127 @@
+package fieldcrypt
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type secretRecord struct {
+	ID     uint
+	Secret string `gorm:"serializer:encrypted"`
+}
+
+// openWithKeyring 注册 keyring 后打开 path 处的数据库。GORM 解析模型时会复制序列化器，
+// 因此更换密钥需要重新打开数据库，模拟修改配置后重启
+func openWithKeyring(t *testing.T, path string, keyring *Keyring) *gorm.DB {
+	t.Helper()
+	Register(keyring)
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&secretRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// storedValue 绕过序列化器读取列中保存的原始值
+func storedValue(t *testing.T, db *gorm.DB, id uint) string {
+	t.Helper()
+	var stored string
+	if err := db.Raw("SELECT secret FROM secret_records WHERE id = ?", id).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	return stored
+}
+
+func loadRecord(t *testing.T, db *gorm.DB, id uint) (secretRecord, error) {
+	t.Helper()
+	var record secretRecord
+	err := db.First(&record, id).Error
+	return record, err
+}
+
+func TestSerializerEncryptsColumn(t *testing.T) {
+	keys := newTestKeys(t, 1)
+	db := openWithKeyring(t, filepath.Join(t.TempDir(), "test.db"), keys.keyring(t, 1, 1))
+
+	record := secretRecord{Secret: "alice@example.com"}
+	if err := db.Create(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedValue(t, db, record.ID); !strings.HasPrefix(stored, "v1:") || strings.Contains(stored, "alice") {
+		t.Fatalf("stored value %q is not encrypted", stored)
+	}
+	got, err := loadRecord(t, db, record.ID)
+	if err != nil || got.Secret != "alice@example.com" {
+		t.Fatalf("load = %+v, %v", got, err)
+	}
+
+	// 空字符串不加密
+	empty := secretRecord{}
+	if err := db.Create(&empty).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedValue(t, db, empty.ID); stored != "" {
+		t.Fatalf("empty value stored as %q", stored)
+	}
+	if got, err := loadRecord(t, db, empty.ID); err != nil || got.Secret != "" {
+		t.Fatalf("load empty = %+v, %v", got, err)
+	}
+
+	// 加密上线前写入的明文原样读出
+	if err := db.Exec("INSERT INTO secret_records (id, secret) VALUES (100, 'legacy@example.com')").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, err := loadRecord(t, db, 100); err != nil || got.Secret != "legacy@example.com" {
+		t.Fatalf("load legacy = %+v, %v", got, err)
+	}
+}
+
+func TestSerializerAcrossKeyRotation(t *testing.T) {
+	keys := newTestKeys(t, 1, 2)
+	path := filepath.Join(t.TempDir(), "test.db")
+	db := openWithKeyring(t, path, keys.keyring(t, 1, 1))
+	alice := secretRecord{Secret: "alice@example.com"}
+	bob := secretRecord{Secret: "bob@example.com"}
+	if err := db.Create(&alice).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&bob).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 切换到版本 2：旧数据可读，重新保存后使用新版本
+	db = openWithKeyring(t, path, keys.keyring(t, 2, 1, 2))
+	got, err := loadRecord(t, db, alice.ID)
+	if err != nil || got.Secret != "alice@example.com" {
+		t.Fatalf("load after rotation = %+v, %v", got, err)
+	}
+	if err := db.Save(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := KeyVersion(storedValue(t, db, alice.ID)); version != 2 {
+		t.Fatalf("re-saved value uses key version %d, want 2", version)
+	}
+
+	// 版本 1 下线后，未重新加密的数据读取失败
+	db = openWithKeyring(t, path, keys.keyring(t, 2, 2))
+	if got, err := loadRecord(t, db, alice.ID); err != nil || got.Secret != "alice@example.com" {
+		t.Fatalf("load re-encrypted record = %+v, %v", got, err)
+	}
+	if _, err := loadRecord(t, db, bob.ID); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("load with retired key: got %v, want ErrUnknownKeyVersion", err)
+	}
+}
@@ -1,11 +1,12 @@
 package jwtauth
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/cache"
 	"gin-wire-demo/pkg/logger"
+	redisx "gin-wire-demo/pkg/redis"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -17,14 +18,21 @@ var (
 	blacklistKey = "cache:%s:jwt:bl:%s"
 )
 
+// Redis 不可用时进程内降级存储的最大条目数
+const localFallbackEntries = 100000
+
 type JwtBlacklist struct {
-	RedisClient *redis.Client
+	RedisClient redis.UniversalClient
 	Config      *config.Config
 	Logger      logger.Logger
+	Degrader    *redisx.Degrader
+	// local Redis 不可用期间在本实例注销的令牌，降级策略为 local 时使用
+	local *cache.MemoryStore
 }
 
 func NewJwtBlacklist(
-	client *redis.Client,
+	client redis.UniversalClient,
+	health *redisx.HealthWatcher,
 	config *config.Config,
 	logger logger.Logger,
 ) *JwtBlacklist {
@@ -32,6 +40,8 @@ func NewJwtBlacklist(
 		RedisClient: client,
 		Config:      config,
 		Logger:      logger,
+		Degrader:    health.Degrader(redisx.FeatureBlacklist),
+		local:       cache.NewMemoryStore(localFallbackEntries),
 	}
 }
 
@@ -43,12 +53,22 @@ func (jb *JwtBlacklist) IsTokenBlacklisted(c *gin.Context) bool {
 		return false
 	}
 
+	ctx := c.Request.Context()
+	// Redis 不可用期间在本实例注销的令牌，恢复后仍然有效
+	if _, err := jb.local.Get(ctx, jti); err == nil {
+		return true
+	}
+
 	key := fmt.Sprintf(blacklistKey, jb.Config.App.Name, jti)
-	exists, err := jb.RedisClient.Exists(context.Background(), key).Result()
-	if err != nil {
-		return false
+	var exists int64
+	if jb.Degrader.Call(func() (err error) {
+		exists, err = jb.RedisClient.Exists(ctx, key).Result()
+		return err
+	}) {
+		return exists > 0
 	}
-	return exists > 0
+	// Redis 不可用：fail_closed 拒绝全部令牌；fail_open 和 local 放行未在本实例注销的令牌
+	return jb.Degrader.Policy() == config.DegradeFailClosed
 }
 
 // 退出token加入和黑名单
@@ -71,13 +91,26 @@ func (jb *JwtBlacklist) AddTokenBlacklist(c *gin.Context) error {
 			return errors.New("add blacklist fail:invalid token claims")
 		}
 		key := fmt.Sprintf(blacklistKey, jb.Config.App.Name, jti)
-		if err := jb.RedisClient.Set(
-			context.Background(),
-			key,
-			1,         // 值可以是任意内容
-			remaining, // 设置与令牌相同的TTL
-		).Err(); err != nil {
-			jb.Logger.Error(fmt.Sprintf("Failed to add jti to blacklist:%b", err))
+		ctx := c.Request.Context()
+		err := redisx.ErrUnavailable
+		if !jb.Degrader.Call(func() error {
+			err = jb.RedisClient.Set(
+				ctx,
+				key,
+				1,         // 值可以是任意内容
+				remaining, // 设置与令牌相同的TTL
+			).Err()
+			return err
+		}) {
+			switch jb.Degrader.Policy() {
+			case config.DegradeLocal:
+				// 只在本实例生效，其他实例在令牌过期前仍会接受它
+				return jb.local.Set(ctx, jti, []byte{1}, remaining)
+			case config.DegradeFailOpen:
+				jb.Logger.Warn(fmt.Sprintf("Redis unavailable, token not blacklisted: %s", jti))
+				return nil
+			}
+			jb.Logger.Error(fmt.Sprintf("Failed to add jti to blacklist:%v", err))
 			return errors.New("add blacklist fail:internal server error")
 		}
 	}
@@ -2,97 +2,87 @@ package jwtauth
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"gin-wire-demo/internal/config"
 	"gin-wire-demo/internal/model"
 	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/cache"
+	"gin-wire-demo/pkg/db"
+	"strconv"
+	"time"
 
-	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 )
 
-var (
-	Cacheuserinfokey = "cache:%s:jwt:mid:ui:%d"
-)
+// 用户信息缓存的命名空间，键为 jwt:mid:ui:v3:<用户ID>。
+// 值的格式变更时换用新的命名空间，避免滚动升级期间新旧实例读到对方写入的值
+const userinfoNamespace = "jwt:mid:ui:v3"
+
+// cachedUser 缓存的用户信息，只含鉴权所需的字段，邮箱等个人信息不进入缓存
+type cachedUser struct {
+	ID           uint   `json:"id"`
+	Status       string `json:"status"`
+	Role         string `json:"role"`
+	TokenVersion uint   `json:"tv"`
+}
 
 type JwtCacheUserinfo struct {
-	RedisClient *redis.Client
+	Cache       *cache.Cache[cachedUser]
 	Config      *config.Config
 	UserService service.UserService
 }
 
 func NewJwtCacheUserinfo(
-	client *redis.Client,
+	store cache.Store,
 	config *config.Config,
 	userService service.UserService,
 ) *JwtCacheUserinfo {
 	return &JwtCacheUserinfo{
-		RedisClient: client,
+		Cache:       cache.New[cachedUser](store, userinfoNamespace, cache.EarlyRefresh(config.Cache.EarlyRefreshBeta)),
 		Config:      config,
 		UserService: userService,
 	}
 }
 
-// 获取用户信息（带缓存）
-func (jc *JwtCacheUserinfo) GetUserWithCache(userID uint) (*model.User, bool, error) {
-	key := fmt.Sprintf(Cacheuserinfokey, jc.Config.App.Name, userID)
-	// 1. 尝试从缓存获取
-	if user := jc.getCacheUserinfo(key); user != nil {
-		// 空对象表示用户不存在
-		if user.ID == 0 {
-			return nil, true, nil
+// 获取用户信息（带缓存）。同一用户的并发未命中只查询一次数据库。
+// 返回的用户只有 ID、状态、角色和令牌版本，需要其他字段时应从 UserService 读取
+func (jc *JwtCacheUserinfo) GetUserWithCache(ctx context.Context, userID uint) (*model.User, bool, error) {
+	user, fromCache, err := jc.Cache.Fetch(ctx, strconv.FormatUint(uint64(userID), 10), func(ctx context.Context) (cachedUser, time.Duration, error) {
+		// 结果用于校验令牌版本和状态，读主库避免从库延迟导致新令牌被拒或旧令牌仍有效
+		user, err := jc.UserService.GetUserByID(db.UsePrimary(ctx), userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// 缓存空对象防止穿透
+				return cachedUser{}, jc.Config.JWT.NegativeCacheDuration, nil
+			}
+			return cachedUser{}, 0, err
 		}
-		return user, true, nil
-	}
-
-	// 2. 查询数据库
-	user, err := jc.UserService.GetUserByID(userID)
+		return cachedUser{
+			ID:           user.ID,
+			Status:       user.Status,
+			Role:         user.Role,
+			TokenVersion: user.TokenVersion,
+		}, jc.Config.JWT.CacheDuration, nil
+	})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// 缓存空对象防止穿透
-			u := &model.User{}
-			u.ID = 0
-			jc.setCacheUserinfo(key, u)
-		}
 		return nil, false, err
 	}
-	user.Password = ""
-	// 3. 设置缓存
-	jc.setCacheUserinfo(key, user)
-	return user, false, nil
-}
-
-func (jc *JwtCacheUserinfo) getCacheUserinfo(key string) *model.User {
-	cached, err := jc.RedisClient.Get(context.Background(), key).Result()
-	switch {
-	case err == redis.Nil:
-		return nil
-	case err != nil:
-		return nil
-	case cached == "":
-		jc.ClearCacheUserinfo(key)
-		return nil
-	}
-	var user model.User
-	if err := json.Unmarshal([]byte(cached), &user); err != nil {
-		jc.ClearCacheUserinfo(key)
-		return nil
-	}
-
-	return &user
-}
-
-func (jc *JwtCacheUserinfo) setCacheUserinfo(key string, u *model.User) error {
-	marshaled, err := json.Marshal(u)
-	if err != nil {
-		return err
+	// 空对象表示用户不存在
+	if user.ID == 0 {
+		if fromCache {
+			return nil, true, nil
+		}
+		return nil, false, gorm.ErrRecordNotFound
 	}
-	jc.RedisClient.Set(context.Background(), key, string(marshaled), jc.Config.JWT.CacheDuration)
-	return nil
+	return &model.User{
+		Model:        gorm.Model{ID: user.ID},
+		Status:       user.Status,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+	}, fromCache, nil
 }
 
-func (jc *JwtCacheUserinfo) ClearCacheUserinfo(key string) {
-	jc.RedisClient.Del(context.Background(), key)
+// 清除用户信息缓存
+func (jc *JwtCacheUserinfo) ClearCacheUserinfo(ctx context.Context, userID uint) {
+	_ = jc.Cache.Delete(ctx, strconv.FormatUint(uint64(userID), 10))
 }
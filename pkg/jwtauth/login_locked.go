@@ -4,27 +4,61 @@ import (
 	"context"
 	"fmt"
 	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/cache"
+	redisx "gin-wire-demo/pkg/redis"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// 用户名作为 hash tag，同一用户的计数器和锁定键落在同一个槽，集群模式下脚本也能执行
 var (
-	loginFailureKey = "cache:%s:login_failures:%s" // 登录失败计数器键格式
-	accountLockKey  = "cache:%s:account_lock:%s"   // 账户锁定键格式
+	loginFailureKey = "cache:%s:login_failures:{%s}" // 登录失败计数器键格式
+	accountLockKey  = "cache:%s:account_lock:{%s}"   // 账户锁定键格式
 )
 
+// 失败计数加一，达到上限时锁定账户并重置计数，返回 1 表示本次触发了锁定。
+// 整个过程原子执行，并发失败全部计入且只有一次请求触发锁定
+// KEYS: failures, lock
+// ARGV: max_attempts, lock_duration(ms)
+var incrementFailureScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count < tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[2], '1', 'PX', ARGV[2])
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
 type LoginLocked struct {
-	RedisClient *redis.Client
+	RedisClient redis.UniversalClient
 	Config      *config.Config
+	UserService service.UserService // 账户被锁定时记录 user.locked 事件
+	Degrader    *redisx.Degrader
+
+	// Redis 不可用且降级策略为 local 时，在本实例内计数和锁定
+	localMu       sync.Mutex
+	localFailures *cache.MemoryStore
+	localLocks    *cache.MemoryStore
 }
 
 func NewLoginLocked(
-	client *redis.Client,
+	client redis.UniversalClient,
+	health *redisx.HealthWatcher,
 	config *config.Config,
+	userService service.UserService,
 ) *LoginLocked {
 	return &LoginLocked{
-		RedisClient: client,
-		Config:      config,
+		RedisClient:   client,
+		Config:        config,
+		UserService:   userService,
+		Degrader:      health.Degrader(redisx.FeatureLoginLock),
+		localFailures: cache.NewMemoryStore(localFallbackEntries),
+		localLocks:    cache.NewMemoryStore(localFallbackEntries),
 	}
 }
 
@@ -39,58 +73,90 @@ func (ll *LoginLocked) GetAccountLockKey(username string) string {
 
 }
 
-// 检查账户是否被锁定
-func (ll *LoginLocked) IsAccountLocked(username string) (bool, error) {
+// 检查账户是否被锁定。Redis 不可用且降级策略为 fail_closed 时返回 redisx.ErrUnavailable
+func (ll *LoginLocked) IsAccountLocked(ctx context.Context, username string) (bool, error) {
+	// Redis 不可用期间在本实例锁定的账户，恢复后锁定仍然有效
+	if _, err := ll.localLocks.Get(ctx, username); err == nil {
+		return true, nil
+	}
 	key := ll.GetAccountLockKey(username)
-	exists, err := ll.RedisClient.Exists(context.Background(), key).Result()
-	if err != nil {
-		return false, err
+	var exists int64
+	if ll.Degrader.Call(func() (err error) {
+		exists, err = ll.RedisClient.Exists(ctx, key).Result()
+		return err
+	}) {
+		return exists == 1, nil
 	}
-	return exists == 1, nil
+	if ll.Degrader.Policy() == config.DegradeFailClosed {
+		return false, redisx.ErrUnavailable
+	}
+	return false, nil
 }
 
-// 增加登录失败计数
-func (ll *LoginLocked) IncrementLoginFailure(username string) error {
-	key := ll.GetLoginFailureKey(username)
-	ctx := context.Background()
-
-	// 使用事务保证原子操作
-	txf := func(tx *redis.Tx) error {
-		// 获取当前计数
-		count, err := tx.Get(ctx, key).Int()
-		if err != nil && err != redis.Nil {
-			return err
+// 增加登录失败计数，达到上限时锁定账户
+func (ll *LoginLocked) IncrementLoginFailure(ctx context.Context, username string) error {
+	locked := false
+	if !ll.Degrader.Call(func() error {
+		n, err := incrementFailureScript.Run(ctx, ll.RedisClient,
+			[]string{ll.GetLoginFailureKey(username), ll.GetAccountLockKey(username)},
+			ll.Config.JWT.MaxLoginAttempts, ll.Config.JWT.LockDuration.Milliseconds()).Int()
+		locked = n == 1
+		return err
+	}) {
+		switch ll.Degrader.Policy() {
+		case config.DegradeFailOpen:
+			return nil
+		case config.DegradeFailClosed:
+			return redisx.ErrUnavailable
 		}
-
-		// 增加计数
-		newCount := count + 1
-		if newCount >= ll.Config.JWT.MaxLoginAttempts {
-			// 达到阈值，设置锁定
-			lockKey := ll.GetAccountLockKey(username)
-			if err := tx.Set(ctx, lockKey, "1", ll.Config.JWT.LockDuration).Err(); err != nil {
-				return err
-			}
-			// 重置失败计数
-			return tx.Del(ctx, key).Err()
-		} else {
-			// 更新失败计数
-			return tx.Set(ctx, key, newCount, 0).Err()
+		locked = ll.incrementLocal(ctx, username)
+	}
+	if locked {
+		lockedUntil := time.Now().Add(ll.Config.JWT.LockDuration)
+		if err := ll.UserService.RecordAccountLocked(ctx, username, lockedUntil); err != nil {
+			return fmt.Errorf("record account locked: %w", err)
 		}
 	}
+	return nil
+}
 
-	// 使用Watch实现乐观锁
-	return ll.RedisClient.Watch(ctx, txf, key)
+// incrementLocal 在本实例内计数，达到上限时锁定并返回 true。
+// 计数与锁定同样保留 lock_duration，避免长时间降级时条目无限增长
+func (ll *LoginLocked) incrementLocal(ctx context.Context, username string) bool {
+	ll.localMu.Lock()
+	defer ll.localMu.Unlock()
+	count := 0
+	if data, err := ll.localFailures.Get(ctx, username); err == nil {
+		count, _ = strconv.Atoi(string(data))
+	}
+	count++
+	if count < ll.Config.JWT.MaxLoginAttempts {
+		_ = ll.localFailures.Set(ctx, username, []byte(strconv.Itoa(count)), ll.Config.JWT.LockDuration)
+		return false
+	}
+	_ = ll.localLocks.Set(ctx, username, []byte{1}, ll.Config.JWT.LockDuration)
+	_ = ll.localFailures.Delete(ctx, username)
+	return true
 }
 
 // 清除登录失败计数和锁定
-func (ll *LoginLocked) ClearLoginFailures(username string) error {
-	ctx := context.Background()
-	_, err := ll.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		// 清除失败计数
-		pipe.Del(ctx, ll.GetLoginFailureKey(username))
-		// 清除锁定状态
-		pipe.Del(ctx, ll.GetAccountLockKey(username))
+func (ll *LoginLocked) ClearLoginFailures(ctx context.Context, username string) error {
+	_ = ll.localFailures.Delete(ctx, username)
+	_ = ll.localLocks.Delete(ctx, username)
+	if ll.Degrader.Call(func() error {
+		_, err := ll.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			// 清除失败计数
+			pipe.Del(ctx, ll.GetLoginFailureKey(username))
+			// 清除锁定状态
+			pipe.Del(ctx, ll.GetAccountLockKey(username))
+			return nil
+		})
+		return err
+	}) {
 		return nil
-	})
-	return err
+	}
+	if ll.Degrader.Policy() == config.DegradeFailClosed {
+		return redisx.ErrUnavailable
+	}
+	return nil
 }
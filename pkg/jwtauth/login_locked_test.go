@@ -0,0 +1,117 @@
+package jwtauth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/internal/service"
+	"gin-wire-demo/pkg/logger"
+	redisx "gin-wire-demo/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// lockRecorder 只实现 RecordAccountLocked，其余方法不会被调用
+type lockRecorder struct {
+	service.UserService
+	locked atomic.Int64
+}
+
+func (r *lockRecorder) RecordAccountLocked(ctx context.Context, username string, lockedUntil time.Time) error {
+	r.locked.Add(1)
+	return nil
+}
+
+func newTestLoginLocked(t *testing.T, maxAttempts int) (*LoginLocked, *lockRecorder, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "test"},
+		Log: config.LogConfig{Level: "error"},
+		JWT: config.JWTConfig{MaxLoginAttempts: maxAttempts, LockDuration: time.Minute},
+		Redis: config.RedisConfig{
+			HealthCheckInterval: time.Second,
+			Degrade:             config.RedisDegradeConfig{LoginLock: config.DegradeLocal},
+		},
+	}
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &lockRecorder{}
+	ll := NewLoginLocked(client, redisx.NewHealthWatcher(client, cfg, log), cfg, recorder)
+	return ll, recorder, mr
+}
+
+func TestIncrementLoginFailureLocksAtThreshold(t *testing.T) {
+	ll, recorder, _ := newTestLoginLocked(t, 3)
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		locked, err := ll.IsAccountLocked(ctx, "alice")
+		if err != nil || locked {
+			t.Fatalf("before failure %d: locked=%v err=%v", i, locked, err)
+		}
+		if err := ll.IncrementLoginFailure(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if locked, err := ll.IsAccountLocked(ctx, "alice"); err != nil || !locked {
+		t.Fatalf("after 3 failures: locked=%v err=%v", locked, err)
+	}
+	if n := recorder.locked.Load(); n != 1 {
+		t.Fatalf("recorded %d lock events, want 1", n)
+	}
+
+	if err := ll.ClearLoginFailures(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _ := ll.IsAccountLocked(ctx, "alice"); locked {
+		t.Fatal("still locked after clear")
+	}
+}
+
+func TestIncrementLoginFailureCountsConcurrentFailures(t *testing.T) {
+	const maxAttempts, failures = 5, 40
+	ll, recorder, mr := newTestLoginLocked(t, maxAttempts)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < failures; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ll.IncrementLoginFailure(ctx, "alice"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 每次失败都被计入：每满 maxAttempts 次锁定一次并重置计数
+	if n := recorder.locked.Load(); n != failures/maxAttempts {
+		t.Fatalf("recorded %d lock events, want %d", n, failures/maxAttempts)
+	}
+	if mr.Exists(ll.GetLoginFailureKey("alice")) {
+		t.Fatalf("failure counter left at %s", mustGet(t, mr, ll.GetLoginFailureKey("alice")))
+	}
+	if !mr.Exists(ll.GetAccountLockKey("alice")) {
+		t.Fatal("account not locked")
+	}
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	t.Helper()
+	v, err := mr.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
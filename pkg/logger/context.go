@@ -0,0 +1,20 @@
+// pkg/logger/context.go
+package logger
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID 将请求 ID 写入上下文，数据库等下游日志据此关联到同一请求
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 读取上下文中的请求 ID，没有时返回空串
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
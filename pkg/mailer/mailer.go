@@ -0,0 +1,31 @@
+// pkg/mailer/mailer.go
+package mailer
+
+import (
+	"gin-wire-demo/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// LogMailer 将邮件内容写入日志，用于开发环境或尚未接入邮件服务时
+type LogMailer struct {
+	logger logger.Logger
+}
+
+func NewLogMailer(logger logger.Logger) *LogMailer {
+	return &LogMailer{logger: logger.With(zap.String("module", "mailer"))}
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	m.logger.Info("mail sent",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("body", body),
+	)
+	return nil
+}
@@ -0,0 +1,55 @@
+// pkg/mailer/queue.go
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-wire-demo/pkg/queue"
+)
+
+// SendMailJob 发送邮件的任务类型
+const SendMailJob = "mail:send"
+
+const enqueueTimeout = 5 * time.Second
+
+type sendMailPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// QueuedMailer 将邮件放入任务队列异步发送，请求不必等待邮件服务响应，失败时由队列重试
+type QueuedMailer struct {
+	client *queue.Client
+}
+
+func NewQueuedMailer(client *queue.Client) *QueuedMailer {
+	return &QueuedMailer{client: client}
+}
+
+func (m *QueuedMailer) Send(to, subject, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), enqueueTimeout)
+	defer cancel()
+	_, err := m.client.Enqueue(ctx, SendMailJob, sendMailPayload{To: to, Subject: subject, Body: body})
+	return err
+}
+
+// SendHandler 执行 mail:send 任务，实际发送交给 sender
+type SendHandler struct {
+	sender Mailer
+}
+
+func NewSendHandler(sender *LogMailer) *SendHandler {
+	return &SendHandler{sender: sender}
+}
+
+func (h *SendHandler) ProcessJob(ctx context.Context, job *queue.Job) error {
+	var p sendMailPayload
+	if err := job.Decode(&p); err != nil {
+		// 负载无法解析，重试也不会成功
+		return fmt.Errorf("%w: %v", queue.ErrSkipRetry, err)
+	}
+	return h.sender.Send(p.To, p.Subject, p.Body)
+}
@@ -0,0 +1,121 @@
+// pkg/migrate/dialect.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialect 封装各数据库在历史表、占位符和迁移锁上的差异
+type dialect struct {
+	historyDDL string
+	// rebind 将 ? 占位符转换为驱动使用的形式
+	rebind func(query string) string
+	lock   func(ctx context.Context, conn *sql.Conn) error
+	unlock func(ctx context.Context, conn *sql.Conn) error
+}
+
+func dialectFor(driver string) (*dialect, error) {
+	switch driver {
+	case "mysql":
+		return &dialect{
+			historyDDL: `CREATE TABLE IF NOT EXISTS ` + historyTable + ` (
+    version    BIGINT NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    dirty      TINYINT(1) NOT NULL DEFAULT 0,
+    applied_at DATETIME(3) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+			rebind: func(query string) string { return query },
+			lock:   mysqlLock,
+			unlock: func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+				return err
+			},
+		}, nil
+	case "postgres":
+		return &dialect{
+			historyDDL: `CREATE TABLE IF NOT EXISTS ` + historyTable + ` (
+    version    BIGINT NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    dirty      BOOLEAN NOT NULL DEFAULT FALSE,
+    applied_at TIMESTAMPTZ NOT NULL
+)`,
+			rebind: rebindDollar,
+			lock:   postgresLock,
+			unlock: func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey)
+				return err
+			},
+		}, nil
+	case "sqlite":
+		// SQLite 只用于测试和本地开发，由单个进程访问，不需要跨进程的迁移锁
+		noop := func(context.Context, *sql.Conn) error { return nil }
+		return &dialect{
+			historyDDL: `CREATE TABLE IF NOT EXISTS ` + historyTable + ` (
+    version    INTEGER NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    dirty      BOOLEAN NOT NULL DEFAULT 0,
+    applied_at DATETIME NOT NULL
+)`,
+			rebind: func(query string) string { return query },
+			lock:   noop,
+			unlock: noop,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported migration driver %q", driver)
+	}
+}
+
+func mysqlLock(ctx context.Context, conn *sql.Conn) error {
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout/time.Second)).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrLocked
+	}
+	return nil
+}
+
+// postgresLockKey 迁移使用的会话级 advisory lock 键
+const postgresLockKey int64 = 0x6d69677261746500
+
+// postgresLock 轮询 pg_try_advisory_lock，超时返回 ErrLocked
+func postgresLock(ctx context.Context, conn *sql.Conn) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", postgresLockKey).Scan(&acquired); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func rebindDollar(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,358 @@
+// pkg/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	historyTable = "schema_migrations"
+	lockName     = "gin-wire-demo:schema_migrations"
+	lockTimeout  = 30 * time.Second
+)
+
+var (
+	ErrLocked      = errors.New("another migration is running")
+	ErrDirty       = errors.New("database is dirty, fix the failed migration manually and clear its dirty flag")
+	ErrMissingDown = errors.New("migration has no down script")
+
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	Dirty     bool
+}
+
+// Migrator 执行迁移。MySQL 的 DDL 会隐式提交，无法放进事务，
+// 因此执行前先在历史表中标记 dirty，成功后清除；
+// 存在 dirty 记录时拒绝继续执行，需人工确认后处理
+type Migrator struct {
+	db         *sql.DB
+	dialect    *dialect
+	migrations []Migration
+}
+
+// New 从 fsys 加载迁移文件，driver 为 mysql、postgres 或 sqlite。
+// db 需要允许单次 Exec 执行多条语句，以便单个文件包含多条语句
+func New(db *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+// Load 读取并按版本号排序迁移文件
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by both %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up 依次执行未应用的迁移，steps <= 0 表示全部执行
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		history, err := m.loadHistory(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if steps > 0 && len(applied) >= steps {
+				break
+			}
+			if _, ok := history[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本倒序回滚已应用的迁移，steps <= 0 表示全部回滚
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		history, err := m.loadHistory(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if steps > 0 && len(reverted) >= steps {
+				break
+			}
+			migration := m.migrations[i]
+			if _, ok := history[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 返回所有迁移及其执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureHistoryTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	history, err := m.readHistory(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if record, ok := history[migration.Version]; ok {
+			appliedAt := record.appliedAt
+			status.AppliedAt = &appliedAt
+			status.Dirty = record.dirty
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock 在持有命名锁的单个连接上执行 fn，防止多个实例同时迁移
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer m.dialect.unlock(context.Background(), conn)
+
+	if err := m.ensureHistoryTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+type historyRecord struct {
+	appliedAt time.Time
+	dirty     bool
+}
+
+func (m *Migrator) ensureHistoryTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, m.dialect.historyDDL); err != nil {
+		return fmt.Errorf("create migration history table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) readHistory(ctx context.Context, conn *sql.Conn) (map[int64]historyRecord, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, dirty, applied_at FROM "+historyTable)
+	if err != nil {
+		return nil, fmt.Errorf("read migration history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[int64]historyRecord)
+	for rows.Next() {
+		var (
+			version int64
+			record  historyRecord
+		)
+		if err := rows.Scan(&version, &record.dirty, &record.appliedAt); err != nil {
+			return nil, err
+		}
+		history[version] = record
+	}
+	return history, rows.Err()
+}
+
+// loadHistory 读取历史记录，存在 dirty 记录时返回 ErrDirty
+func (m *Migrator) loadHistory(ctx context.Context, conn *sql.Conn) (map[int64]historyRecord, error) {
+	history, err := m.readHistory(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for version, record := range history {
+		if record.dirty {
+			return nil, fmt.Errorf("%w (version %d)", ErrDirty, version)
+		}
+	}
+	return history, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if _, err := conn.ExecContext(ctx,
+		m.dialect.rebind("INSERT INTO "+historyTable+" (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+		migration.Version, migration.Name, true, time.Now()); err != nil {
+		return fmt.Errorf("record migration %d: %w", migration.Version, err)
+	}
+	if _, err := conn.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := conn.ExecContext(ctx,
+		m.dialect.rebind("UPDATE "+historyTable+" SET dirty = ?, applied_at = ? WHERE version = ?"),
+		false, time.Now(), migration.Version); err != nil {
+		return fmt.Errorf("record migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if strings.TrimSpace(migration.Down) == "" {
+		return fmt.Errorf("%w: %d_%s", ErrMissingDown, migration.Version, migration.Name)
+	}
+	if _, err := conn.ExecContext(ctx,
+		m.dialect.rebind("UPDATE "+historyTable+" SET dirty = ? WHERE version = ?"),
+		true, migration.Version); err != nil {
+		return fmt.Errorf("record migration %d: %w", migration.Version, err)
+	}
+	if _, err := conn.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("revert migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := conn.ExecContext(ctx,
+		m.dialect.rebind("DELETE FROM "+historyTable+" WHERE version = ?"),
+		migration.Version); err != nil {
+		return fmt.Errorf("record migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// Create 在每个 dirs 中生成下一个版本号的空白 up/down 文件，返回生成的文件路径。
+// 各目录对应不同的数据库驱动，版本号需保持一致，不一致时拒绝生成；出错时删除已生成的文件
+func Create(dirs []string, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, use lowercase letters, digits and underscores", name)
+	}
+	var last int64 = -1
+	for i, dir := range dirs {
+		migrations, err := Load(os.DirFS(dir))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		var version int64
+		if len(migrations) > 0 {
+			version = migrations[len(migrations)-1].Version
+		}
+		if i > 0 && version != last {
+			return nil, fmt.Errorf("migration directories out of sync: %s is at version %d, %s at %d",
+				dirs[0], last, dir, version)
+		}
+		last = version
+	}
+	next := last + 1
+
+	var paths []string
+	for _, dir := range dirs {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+			if err := createFile(path, fmt.Sprintf("-- %06d_%s (%s)\n", next, name, direction)); err != nil {
+				for _, created := range paths {
+					_ = os.Remove(created)
+				}
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// createFile 创建新文件，O_EXCL 防止覆盖已有文件
+func createFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,353 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/migrations"
+	"gin-wire-demo/pkg/db"
+)
+
+// newTestDB 进程内的 SQLite 内存库，只有一个连接
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	sqlDB, err := db.NewScriptDB(&config.Config{
+		Database: config.DatabaseConfig{Driver: config.DriverSQLite, DBname: ":memory:"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+func tableExists(t *testing.T, sqlDB *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int64 {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int64
+	for _, s := range statuses {
+		if s.Dirty {
+			t.Fatalf("migration %d is dirty", s.Version)
+		}
+		if s.AppliedAt != nil {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestEmbeddedSQLiteMigrationsRoundTrip(t *testing.T) {
+	sqlDB := newTestDB(t)
+	source, err := migrations.For(config.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(sqlDB, config.DriverSQLite, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	total := len(m.migrations)
+
+	applied, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(applied) != total || len(appliedVersions(t, m)) != total {
+		t.Fatalf("applied %d of %d migrations", len(applied), total)
+	}
+	if !tableExists(t, sqlDB, "users") {
+		t.Fatal("users table missing after up")
+	}
+	if applied, err := m.Up(ctx, 0); err != nil || len(applied) != 0 {
+		t.Fatalf("second up applied %d, err %v", len(applied), err)
+	}
+
+	// 回滚最近一个再重新执行
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("down 1: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != m.migrations[total-1].Version {
+		t.Fatalf("down 1 reverted %v", reverted)
+	}
+	if got := appliedVersions(t, m); len(got) != total-1 {
+		t.Fatalf("after down 1, applied %v", got)
+	}
+	if applied, err := m.Up(ctx, 0); err != nil || len(applied) != 1 {
+		t.Fatalf("re-up applied %d, err %v", len(applied), err)
+	}
+
+	// 全部回滚后只剩历史表
+	if reverted, err := m.Down(ctx, 0); err != nil || len(reverted) != total {
+		t.Fatalf("down all reverted %d, err %v", len(reverted), err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Fatalf("after down all, applied %v", got)
+	}
+	rows, err := sqlDB.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		if name != historyTable {
+			t.Errorf("table %s left after down all", name)
+		}
+	}
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY);")},
+		"000001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"000002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER PRIMARY KEY); CREATE INDEX idx_b ON b (id);")},
+		"000002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"000003_c.up.sql":   {Data: []byte("CREATE TABLE c (id INTEGER PRIMARY KEY);")},
+	}
+}
+
+func TestUpAndDownSteps(t *testing.T) {
+	sqlDB := newTestDB(t)
+	m, err := New(sqlDB, config.DriverSQLite, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if applied, err := m.Up(ctx, 2); err != nil || len(applied) != 2 {
+		t.Fatalf("up 2 applied %d, err %v", len(applied), err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[1] != 2 {
+		t.Fatalf("applied %v, want [1 2]", got)
+	}
+	if tableExists(t, sqlDB, "c") {
+		t.Fatal("up 2 applied the third migration")
+	}
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 down 脚本的迁移无法回滚，历史记录保持不变
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrMissingDown) {
+		t.Fatalf("down without script: got %v, want ErrMissingDown", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 3 {
+		t.Fatalf("applied %v after failed down", got)
+	}
+}
+
+func TestFailedMigrationLeavesDirtyFlag(t *testing.T) {
+	sqlDB := newTestDB(t)
+	fsys := testMigrations()
+	fsys["000002_b.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE b (;")}
+	m, err := New(sqlDB, config.DriverSQLite, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx, 0)
+	if err == nil || len(applied) != 1 {
+		t.Fatalf("up applied %d, err %v, want failure at version 2", len(applied), err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[1].Dirty || statuses[0].Dirty || statuses[2].AppliedAt != nil {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+
+	// 存在 dirty 记录时 up 和 down 都拒绝执行
+	if _, err := m.Up(ctx, 0); !errors.Is(err, ErrDirty) {
+		t.Fatalf("up while dirty: got %v, want ErrDirty", err)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrDirty) {
+		t.Fatalf("down while dirty: got %v, want ErrDirty", err)
+	}
+
+	// 人工处理后清除 dirty 记录即可继续
+	if _, err := sqlDB.Exec("DELETE FROM " + historyTable + " WHERE version = 2"); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := New(sqlDB, config.DriverSQLite, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := fixed.Up(ctx, 0); err != nil || len(applied) != 2 {
+		t.Fatalf("up after fix applied %d, err %v", len(applied), err)
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name": {"1_Add-Users.up.sql": {Data: []byte("SELECT 1;")}},
+		"no up":    {"000001_a.down.sql": {Data: []byte("SELECT 1;")}},
+		"version clash": {
+			"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"000001_b.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRebindDollar(t *testing.T) {
+	got := rebindDollar("UPDATE t SET a = ?, b = ? WHERE c = ?")
+	if want := "UPDATE t SET a = $1, b = $2 WHERE c = $3"; got != want {
+		t.Fatalf("rebindDollar = %q, want %q", got, want)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+func TestCreateWritesEveryDriverDirectory(t *testing.T) {
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "mysql"), filepath.Join(root, "postgres"), filepath.Join(root, "sqlite")}
+	for _, dir := range dirs {
+		writeFiles(t, dir, "000001_init.up.sql", "000001_init.down.sql")
+	}
+
+	paths, err := Create(dirs, "Add_Phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 6 {
+		t.Fatalf("created %v", paths)
+	}
+	for _, dir := range dirs {
+		got := strings.Join(listFiles(t, dir), " ")
+		want := "000001_init.down.sql 000001_init.up.sql 000002_add_phone.down.sql 000002_add_phone.up.sql"
+		if got != want {
+			t.Fatalf("%s contains %s", dir, got)
+		}
+	}
+
+	if _, err := Create(dirs, "add-phone"); err == nil {
+		t.Fatal("invalid name accepted")
+	}
+}
+
+func TestCreateRefusesOutOfSyncDirectories(t *testing.T) {
+	root := t.TempDir()
+	mysql, sqlite := filepath.Join(root, "mysql"), filepath.Join(root, "sqlite")
+	writeFiles(t, mysql, "000001_init.up.sql", "000002_extra.up.sql")
+	writeFiles(t, sqlite, "000001_init.up.sql")
+
+	if _, err := Create([]string{mysql, sqlite}, "next"); err == nil {
+		t.Fatal("expected out of sync error")
+	}
+	if got := len(listFiles(t, mysql)) + len(listFiles(t, sqlite)); got != 3 {
+		t.Fatalf("files created despite error, now %d files", got)
+	}
+}
+
+func TestCreateRemovesPartialFilesOnError(t *testing.T) {
+	root := t.TempDir()
+	a, b := filepath.Join(root, "a"), filepath.Join(root, "b")
+	writeFiles(t, a, "000001_init.up.sql")
+	writeFiles(t, b, "000001_init.up.sql")
+	// b 中占用了待生成的文件名，生成到 b 时失败
+	if err := os.Mkdir(filepath.Join(b, "000002_next.up.sql"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Create([]string{a, b}, "next"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := listFiles(t, a); len(got) != 1 {
+		t.Fatalf("partial files left in a: %v", got)
+	}
+}
+
+// SQLite 本身不加锁，替换锁函数验证加锁、释放和加锁失败时不执行迁移
+func TestMigrationsRunUnderLock(t *testing.T) {
+	sqlDB := newTestDB(t)
+	m, err := New(sqlDB, config.DriverSQLite, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var locked, unlocked int
+	m.dialect.lock = func(context.Context, *sql.Conn) error {
+		if locked != unlocked {
+			t.Fatal("lock acquired twice")
+		}
+		locked++
+		return nil
+	}
+	m.dialect.unlock = func(context.Context, *sql.Conn) error {
+		unlocked++
+		return nil
+	}
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrMissingDown) {
+		t.Fatalf("down: got %v, want ErrMissingDown", err)
+	}
+	if _, err := m.Status(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 迁移失败时也要释放锁
+	if locked != 2 || unlocked != 2 {
+		t.Fatalf("locked %d times, unlocked %d times, want 2 each", locked, unlocked)
+	}
+
+	errBusy := errors.New("locked by another instance")
+	m.dialect.lock = func(context.Context, *sql.Conn) error { return errBusy }
+	if _, err := m.Down(ctx, 0); !errors.Is(err, errBusy) {
+		t.Fatalf("down without lock: got %v, want lock error", err)
+	}
+	if !tableExists(t, sqlDB, "a") || unlocked != 2 {
+		t.Fatal("migration ran or unlocked without holding the lock")
+	}
+}
@@ -0,0 +1,122 @@
+// pkg/queue/inspector.go
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Stats 队列中各状态的任务数
+type Stats struct {
+	Queue     string `json:"queue"`
+	Weight    int    `json:"weight"`
+	Pending   int64  `json:"pending"`
+	Scheduled int64  `json:"scheduled"`
+	Active    int64  `json:"active"`
+	Dead      int64  `json:"dead"`
+}
+
+// Stats 返回全部队列的任务数，按队列名排序
+func (c *Client) Stats(ctx context.Context) ([]Stats, error) {
+	names := make([]string, 0, len(c.queues))
+	for name := range c.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type counts struct{ pending, scheduled, active, dead *redis.IntCmd }
+	cmds := make([]counts, len(names))
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, name := range names {
+			k := c.keys(name)
+			cmds[i] = counts{
+				pending:   pipe.LLen(ctx, k.pending),
+				scheduled: pipe.ZCard(ctx, k.scheduled),
+				active:    pipe.ZCard(ctx, k.active),
+				dead:      pipe.ZCard(ctx, k.dead),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]Stats, len(names))
+	for i, name := range names {
+		stats[i] = Stats{
+			Queue:     name,
+			Weight:    c.queues[name],
+			Pending:   cmds[i].pending.Val(),
+			Scheduled: cmds[i].scheduled.Val(),
+			Active:    cmds[i].active.Val(),
+			Dead:      cmds[i].dead.Val(),
+		}
+	}
+	return stats, nil
+}
+
+// DeadJobs 按进入死信的时间倒序列出死信任务
+func (c *Client) DeadJobs(ctx context.Context, queue string, offset, limit int) ([]*Job, error) {
+	if _, ok := c.queues[queue]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+	k := c.keys(queue)
+	ids, err := c.rdb.ZRevRange(ctx, k.dead, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([]*redis.StringStringMapCmd, len(ids))
+	_, err = c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, k.job(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(ids))
+	for i, id := range ids {
+		job, err := parseJob(queue, id, cmds[i].Val())
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// RetryDead 将死信任务重新放入待执行队列
+func (c *Client) RetryDead(ctx context.Context, queue, id string) error {
+	if _, ok := c.queues[queue]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+	k := c.keys(queue)
+	moved, err := retryDeadScript.Run(ctx, c.rdb, []string{k.dead, k.pending, k.job(id)}, id).Int()
+	if err != nil {
+		return err
+	}
+	if moved == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// DeleteDead 删除死信任务
+func (c *Client) DeleteDead(ctx context.Context, queue, id string) error {
+	if _, ok := c.queues[queue]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+	k := c.keys(queue)
+	removed, err := c.rdb.ZRem(ctx, k.dead, id).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrJobNotFound
+	}
+	return c.rdb.Del(ctx, k.job(id)).Err()
+}
@@ -0,0 +1,205 @@
+// pkg/queue/queue.go
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gin-wire-demo/internal/config"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownQueue = errors.New("unknown queue")
+	ErrJobNotFound  = errors.New("job not found")
+	// ErrSkipRetry 处理函数返回包装了该错误的 error 时，任务不再重试直接进入死信
+	ErrSkipRetry = errors.New("skip retry")
+)
+
+// DefaultQueue 未指定队列时使用
+const DefaultQueue = "default"
+
+// Job 队列中的任务
+type Job struct {
+	ID          string
+	Type        string
+	Queue       string
+	Payload     []byte
+	Attempts    int // 已开始执行的次数，含当前这次
+	MaxAttempts int
+	Timeout     time.Duration
+	LastError   string
+	CreatedAt   time.Time
+	FailedAt    *time.Time // 进入死信的时间
+
+	lease string // 本次领取的序号，状态迁移时校验
+}
+
+// Decode 将 JSON 负载解码到 v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Client 投递任务并查看队列状态，可在任意实例上使用
+type Client struct {
+	rdb    redis.UniversalClient
+	app    string
+	queues map[string]int
+	cfg    config.QueueConfig
+}
+
+func NewClient(rdb redis.UniversalClient, cfg *config.Config) *Client {
+	return &Client{
+		rdb:    rdb,
+		app:    cfg.App.Name,
+		queues: cfg.Queue.Queues,
+		cfg:    cfg.Queue,
+	}
+}
+
+type enqueueOptions struct {
+	queue       string
+	processAt   time.Time
+	maxAttempts int
+	timeout     time.Duration
+}
+
+// Option 投递选项
+type Option func(*enqueueOptions)
+
+// Queue 指定队列，队列及其权重在配置中定义
+func Queue(name string) Option {
+	return func(o *enqueueOptions) { o.queue = name }
+}
+
+// Delay 延迟 d 后执行
+func Delay(d time.Duration) Option {
+	return func(o *enqueueOptions) { o.processAt = time.Now().Add(d) }
+}
+
+// ProcessAt 在指定时间执行
+func ProcessAt(t time.Time) Option {
+	return func(o *enqueueOptions) { o.processAt = t }
+}
+
+// MaxAttempts 最多执行次数，含首次
+func MaxAttempts(n int) Option {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// Timeout 单次执行时限。超过时限再加 visibilityGrace 仍未确认的任务会被重新投递
+func Timeout(d time.Duration) Option {
+	return func(o *enqueueOptions) { o.timeout = d }
+}
+
+// Enqueue 投递任务，payload 以 JSON 编码
+func (c *Client) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) (*Job, error) {
+	o := enqueueOptions{
+		queue:       DefaultQueue,
+		maxAttempts: c.cfg.MaxAttempts,
+		timeout:     c.cfg.Timeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, ok := c.queues[o.queue]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQueue, o.queue)
+	}
+	if o.maxAttempts <= 0 || o.timeout <= 0 {
+		return nil, fmt.Errorf("max attempts and timeout must be positive")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Queue:       o.queue,
+		Payload:     data,
+		MaxAttempts: o.maxAttempts,
+		Timeout:     o.timeout,
+		CreatedAt:   time.Now(),
+	}
+	var processAt int64
+	if o.processAt.After(job.CreatedAt) {
+		processAt = o.processAt.UnixMilli()
+	}
+	k := c.keys(o.queue)
+	err = enqueueScript.Run(ctx, c.rdb,
+		[]string{k.job(job.ID), k.pending, k.scheduled},
+		job.ID, job.Type, data, job.MaxAttempts, job.Timeout.Milliseconds(), job.CreatedAt.UnixMilli(), processAt,
+	).Err()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// queueKeys 单个队列的键，用 {队列名} 作为 hash tag 保证同一队列的键落在同一个槽
+type queueKeys struct {
+	pending   string // LIST，待执行
+	scheduled string // ZSET，延迟或等待重试，score 为执行时间
+	active    string // ZSET，执行中，score 为可见性超时的截止时间
+	dead      string // ZSET，死信，score 为进入时间
+	jobPrefix string // HASH，任务数据
+}
+
+func (c *Client) keys(queue string) queueKeys {
+	base := fmt.Sprintf("queue:%s:{%s}:", c.app, queue)
+	return queueKeys{
+		pending:   base + "pending",
+		scheduled: base + "scheduled",
+		active:    base + "active",
+		dead:      base + "dead",
+		jobPrefix: base + "job:",
+	}
+}
+
+func (k queueKeys) job(id string) string {
+	return k.jobPrefix + id
+}
+
+// parseJob 从 HGETALL 的结果还原任务
+func parseJob(queue, id string, fields map[string]string) (*Job, error) {
+	if len(fields) == 0 {
+		return nil, ErrJobNotFound
+	}
+	job := &Job{
+		ID:        id,
+		Type:      fields["type"],
+		Queue:     queue,
+		Payload:   []byte(fields["payload"]),
+		LastError: fields["last_error"],
+		lease:     fields["lease"],
+	}
+	job.Attempts, _ = strconv.Atoi(fields["attempts"])
+	job.MaxAttempts, _ = strconv.Atoi(fields["max_attempts"])
+	timeout, _ := strconv.ParseInt(fields["timeout"], 10, 64)
+	job.Timeout = time.Duration(timeout) * time.Millisecond
+	created, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	job.CreatedAt = time.UnixMilli(created)
+	if failed, err := strconv.ParseInt(fields["failed_at"], 10, 64); err == nil {
+		t := time.UnixMilli(failed)
+		job.FailedAt = &t
+	}
+	return job, nil
+}
+
+// pairsToMap 将 [k1 v1 k2 v2 ...] 转为 map
+func pairsToMap(pairs []interface{}) map[string]string {
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		k, _ := pairs[i].(string)
+		v, _ := pairs[i+1].(string)
+		m[k] = v
+	}
+	return m
+}
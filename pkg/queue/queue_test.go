@@ -0,0 +1,391 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestWorker(t *testing.T) (*Worker, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "test"},
+		Log: config.LogConfig{Level: "error"},
+		Queue: config.QueueConfig{
+			Queues:          map[string]int{DefaultQueue: 3, "low": 1},
+			Concurrency:     1,
+			PollInterval:    10 * time.Millisecond,
+			Timeout:         time.Minute,
+			MaxAttempts:     3,
+			RetryBackoff:    time.Second,
+			MaxBackoff:      10 * time.Second,
+			ShutdownTimeout: 50 * time.Millisecond,
+			DeadMaxSize:     2,
+		},
+	}
+	log, err := logger.NewZapLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewWorker(NewClient(rdb, cfg), cfg, log), mr
+}
+
+// forward 以 now 为当前时间执行一次到期任务的转移
+func forward(t *testing.T, w *Worker, queue string, now time.Time) {
+	t.Helper()
+	k := w.client.keys(queue)
+	err := forwardScript.Run(context.Background(), w.client.rdb,
+		[]string{k.scheduled, k.pending, k.active, k.dead},
+		now.UnixMilli(), k.jobPrefix, w.cfg.DeadMaxSize, forwardBatch).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustFetch(t *testing.T, w *Worker) *Job {
+	t.Helper()
+	job, err := w.fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job == nil {
+		t.Fatal("no job fetched")
+	}
+	return job
+}
+
+func assertStats(t *testing.T, w *Worker, queue string, want Stats) {
+	t.Helper()
+	stats, err := w.client.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stats {
+		if s.Queue != queue {
+			continue
+		}
+		if s.Pending != want.Pending || s.Scheduled != want.Scheduled || s.Active != want.Active || s.Dead != want.Dead {
+			t.Fatalf("stats = pending %d scheduled %d active %d dead %d, want %d %d %d %d",
+				s.Pending, s.Scheduled, s.Active, s.Dead, want.Pending, want.Scheduled, want.Active, want.Dead)
+		}
+		return
+	}
+	t.Fatalf("queue %q not in stats", queue)
+}
+
+func TestEnqueueAndAck(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+
+	if _, err := w.client.Enqueue(ctx, "mail", map[string]string{"to": "a"}, Queue("missing")); !errors.Is(err, ErrUnknownQueue) {
+		t.Fatalf("unknown queue: got %v", err)
+	}
+	enqueued, err := w.client.Enqueue(ctx, "mail", map[string]string{"to": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1})
+
+	var got map[string]string
+	w.Handle("mail", HandlerFunc(func(ctx context.Context, job *Job) error {
+		return job.Decode(&got)
+	}))
+	job := mustFetch(t, w)
+	if job.ID != enqueued.ID || job.Attempts != 1 || job.MaxAttempts != 3 || job.Timeout != time.Minute {
+		t.Fatalf("fetched job = %+v", job)
+	}
+	assertStats(t, w, DefaultQueue, Stats{Active: 1})
+
+	w.process(ctx, job)
+	if got["to"] != "a" {
+		t.Fatalf("payload = %v", got)
+	}
+	assertStats(t, w, DefaultQueue, Stats{})
+	if mr.Exists(w.client.keys(DefaultQueue).job(job.ID)) {
+		t.Fatal("job data not deleted after ack")
+	}
+}
+
+func TestFetchOrder(t *testing.T) {
+	w, _ := newTestWorker(t)
+	ctx := context.Background()
+
+	// 同一队列先进先出
+	first, _ := w.client.Enqueue(ctx, "t", 1)
+	second, _ := w.client.Enqueue(ctx, "t", 2)
+	if job := mustFetch(t, w); job.ID != first.ID {
+		t.Fatalf("fetched %s, want first job", job.ID)
+	}
+	if job := mustFetch(t, w); job.ID != second.ID {
+		t.Fatalf("fetched %s, want second job", job.ID)
+	}
+
+	// 高权重队列为空时取低权重队列
+	low, _ := w.client.Enqueue(ctx, "t", 3, Queue("low"))
+	if job := mustFetch(t, w); job.ID != low.ID || job.Queue != "low" {
+		t.Fatalf("fetched %+v, want job from low queue", job)
+	}
+	if job, err := w.fetch(ctx); err != nil || job != nil {
+		t.Fatalf("empty queues: job=%v err=%v", job, err)
+	}
+}
+
+func TestQueueOrderFollowsWeights(t *testing.T) {
+	w, _ := newTestWorker(t)
+	first := 0
+	const rounds = 4000
+	for i := 0; i < rounds; i++ {
+		if w.queueOrder()[0] == DefaultQueue {
+			first++
+		}
+	}
+	// 权重 3:1，default 排在首位的概率为 3/4
+	if ratio := float64(first) / rounds; ratio < 0.7 || ratio > 0.8 {
+		t.Fatalf("default first in %.2f of rounds, want about 0.75", ratio)
+	}
+}
+
+func TestDelayedJobIsForwardedWhenDue(t *testing.T) {
+	w, _ := newTestWorker(t)
+	ctx := context.Background()
+
+	if _, err := w.client.Enqueue(ctx, "t", nil, Delay(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertStats(t, w, DefaultQueue, Stats{Scheduled: 1})
+	forward(t, w, DefaultQueue, time.Now())
+	assertStats(t, w, DefaultQueue, Stats{Scheduled: 1})
+	forward(t, w, DefaultQueue, time.Now().Add(time.Hour+time.Second))
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1})
+}
+
+func TestFailedJobIsRetriedWithBackoffThenDead(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error {
+		return errors.New("boom")
+	}))
+	enqueued, _ := w.client.Enqueue(ctx, "t", nil)
+	k := w.client.keys(DefaultQueue)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		job := mustFetch(t, w)
+		if job.Attempts != attempt {
+			t.Fatalf("attempts = %d, want %d", job.Attempts, attempt)
+		}
+		before := time.Now()
+		w.process(ctx, job)
+		assertStats(t, w, DefaultQueue, Stats{Scheduled: 1})
+
+		score, err := mr.ZScore(k.scheduled, enqueued.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := before.Add(retryDelay(time.Second, 10*time.Second, attempt))
+		if diff := time.UnixMilli(int64(score)).Sub(want); diff < -time.Millisecond || diff > time.Second {
+			t.Fatalf("attempt %d retry at %v, want about %v", attempt, time.UnixMilli(int64(score)), want)
+		}
+		if got := mr.HGet(k.job(enqueued.ID), "last_error"); got != "boom" {
+			t.Fatalf("last_error = %q", got)
+		}
+		forward(t, w, DefaultQueue, time.Now().Add(time.Minute))
+	}
+
+	// 第三次即最后一次失败后进入死信
+	w.process(ctx, mustFetch(t, w))
+	assertStats(t, w, DefaultQueue, Stats{Dead: 1})
+	dead, err := w.client.DeadJobs(ctx, DefaultQueue, 0, 10)
+	if err != nil || len(dead) != 1 || dead[0].FailedAt == nil || dead[0].LastError != "boom" {
+		t.Fatalf("dead jobs = %+v, err %v", dead, err)
+	}
+}
+
+func TestSkipRetryAndUnknownTypeGoStraightToDead(t *testing.T) {
+	w, _ := newTestWorker(t)
+	ctx := context.Background()
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error {
+		return errors.Join(ErrSkipRetry, errors.New("bad payload"))
+	}))
+	w.client.Enqueue(ctx, "t", nil)
+	w.client.Enqueue(ctx, "unregistered", nil)
+
+	w.process(ctx, mustFetch(t, w))
+	w.process(ctx, mustFetch(t, w))
+	assertStats(t, w, DefaultQueue, Stats{Dead: 2})
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(time.Second, 10*time.Second, tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestVisibilityTimeoutRedelivers(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+	enqueued, _ := w.client.Enqueue(ctx, "t", nil, MaxAttempts(2))
+	k := w.client.keys(DefaultQueue)
+
+	mustFetch(t, w)
+	// 时限内和宽限期内都不重新投递
+	forward(t, w, DefaultQueue, time.Now().Add(time.Minute+visibilityGrace/2))
+	assertStats(t, w, DefaultQueue, Stats{Active: 1})
+
+	forward(t, w, DefaultQueue, time.Now().Add(time.Minute+visibilityGrace+time.Second))
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1})
+	if got := mr.HGet(k.job(enqueued.ID), "last_error"); got != "visibility timeout exceeded" {
+		t.Fatalf("last_error = %q", got)
+	}
+
+	// 次数用尽后超时进入死信
+	if job := mustFetch(t, w); job.Attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", job.Attempts)
+	}
+	forward(t, w, DefaultQueue, time.Now().Add(time.Hour))
+	assertStats(t, w, DefaultQueue, Stats{Dead: 1})
+}
+
+func TestStaleAckDoesNotTouchRedeliveredJob(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error { return nil }))
+	w.client.Enqueue(ctx, "t", nil)
+	k := w.client.keys(DefaultQueue)
+
+	stale := mustFetch(t, w)
+	forward(t, w, DefaultQueue, time.Now().Add(time.Hour))
+
+	// 重新投递后尚未被领取时，迟到的确认不生效
+	w.process(ctx, stale)
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1})
+
+	// 已被其他执行者领取时，迟到的确认也不影响新的执行
+	current := mustFetch(t, w)
+	w.process(ctx, stale)
+	assertStats(t, w, DefaultQueue, Stats{Active: 1})
+	if !mr.Exists(k.job(current.ID)) {
+		t.Fatal("stale ack deleted the running job")
+	}
+
+	// 迟到的失败同样不生效
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error { return errors.New("late") }))
+	w.process(ctx, stale)
+	assertStats(t, w, DefaultQueue, Stats{Active: 1})
+
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error { return nil }))
+	w.process(ctx, current)
+	assertStats(t, w, DefaultQueue, Stats{})
+}
+
+func TestDeadLetterCapDropsOldest(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job, _ := w.client.Enqueue(ctx, "unregistered", i)
+		ids = append(ids, job.ID)
+		w.process(ctx, mustFetch(t, w))
+		// 死信按进入时间排序
+		time.Sleep(2 * time.Millisecond)
+	}
+	assertStats(t, w, DefaultQueue, Stats{Dead: 2})
+	if mr.Exists(w.client.keys(DefaultQueue).job(ids[0])) {
+		t.Fatal("oldest dead job data not deleted")
+	}
+	dead, err := w.client.DeadJobs(ctx, DefaultQueue, 0, 10)
+	if err != nil || len(dead) != 2 || dead[0].ID != ids[2] || dead[1].ID != ids[1] {
+		t.Fatalf("dead jobs = %v, err %v", dead, err)
+	}
+}
+
+func TestRetryAndDeleteDead(t *testing.T) {
+	w, mr := newTestWorker(t)
+	ctx := context.Background()
+	a, _ := w.client.Enqueue(ctx, "unregistered", nil)
+	w.process(ctx, mustFetch(t, w))
+	b, _ := w.client.Enqueue(ctx, "unregistered", nil)
+	w.process(ctx, mustFetch(t, w))
+
+	if err := w.client.RetryDead(ctx, DefaultQueue, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.client.RetryDead(ctx, DefaultQueue, a.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("second retry: got %v, want ErrJobNotFound", err)
+	}
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1, Dead: 1})
+	if job := mustFetch(t, w); job.ID != a.ID || job.Attempts != 1 || job.FailedAt != nil {
+		t.Fatalf("retried job = %+v", job)
+	}
+
+	if err := w.client.DeleteDead(ctx, DefaultQueue, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.client.DeleteDead(ctx, DefaultQueue, b.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("second delete: got %v, want ErrJobNotFound", err)
+	}
+	if mr.Exists(w.client.keys(DefaultQueue).job(b.ID)) {
+		t.Fatal("deleted dead job data still present")
+	}
+	if err := w.client.RetryDead(ctx, "missing", a.ID); !errors.Is(err, ErrUnknownQueue) {
+		t.Fatalf("unknown queue: got %v", err)
+	}
+}
+
+func TestShutdownRequeuesRunningJobs(t *testing.T) {
+	w, _ := newTestWorker(t)
+	started := make(chan struct{})
+	w.Handle("t", HandlerFunc(func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	enqueued, _ := w.client.Enqueue(context.Background(), "t", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job not started")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker did not stop after shutdown timeout")
+	}
+
+	assertStats(t, w, DefaultQueue, Stats{Pending: 1})
+	// 中断的执行不计入尝试次数
+	if job := mustFetch(t, w); job.ID != enqueued.ID || job.Attempts != 1 {
+		t.Fatalf("requeued job = %+v", job)
+	}
+}
@@ -0,0 +1,145 @@
+// pkg/queue/scripts.go
+package queue
+
+import "github.com/go-redis/redis/v8"
+
+// 任务状态的迁移都在 Lua 脚本中完成，保证原子性。时间均为毫秒时间戳。
+// 每次领取时 lease 加一，执行结果只对当次领取生效：任务因可见性超时被重新投递后，
+// 原执行者迟到的确认、重试等操作不会影响新的执行者
+
+// KEYS: job, pending, scheduled
+// ARGV: id, type, payload, max_attempts, timeout, created_at, process_at（0 表示立即）
+var enqueueScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], 'type', ARGV[2], 'payload', ARGV[3], 'attempts', 0,
+	'max_attempts', ARGV[4], 'timeout', ARGV[5], 'created_at', ARGV[6])
+if tonumber(ARGV[7]) > 0 then
+	redis.call('ZADD', KEYS[3], ARGV[7], ARGV[1])
+else
+	redis.call('LPUSH', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// 取出最早的待执行任务放入执行中集合，返回 {id, HGETALL}。任务数据已被删除的 id 直接丢弃。
+// 可见性截止时间为执行时限再加 grace，留出处理函数超时返回后确认的时间
+// KEYS: pending, active
+// ARGV: job 键前缀, now, grace(ms)
+var dequeueScript = redis.NewScript(`
+while true do
+	local id = redis.call('RPOP', KEYS[1])
+	if not id then
+		return false
+	end
+	local key = ARGV[1] .. id
+	local timeout = redis.call('HGET', key, 'timeout')
+	if timeout then
+		redis.call('ZADD', KEYS[2], tonumber(ARGV[2]) + tonumber(timeout) + tonumber(ARGV[3]), id)
+		redis.call('HINCRBY', key, 'attempts', 1)
+		redis.call('HINCRBY', key, 'lease', 1)
+		return {id, redis.call('HGETALL', key)}
+	end
+end
+`)
+
+// 执行成功，删除任务数据
+// KEYS: active, job
+// ARGV: id, lease
+var ackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], 'lease') ~= ARGV[2] or redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('DEL', KEYS[2])
+return 1
+`)
+
+// 执行失败，等待重试。任务已因可见性超时被重新投递时不做处理
+// KEYS: active, scheduled, job
+// ARGV: id, lease, retry_at, error
+var retryScript = redis.NewScript(`
+if redis.call('HGET', KEYS[3], 'lease') ~= ARGV[2] or redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[3], 'last_error', ARGV[4])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+return 1
+`)
+
+// 保留最近 max_dead 个死信，淘汰的任务数据一并删除
+const trimDead = `
+local function trim_dead(dead, prefix, max)
+	local over = redis.call('ZCARD', dead) - max
+	if over > 0 then
+		for _, old in ipairs(redis.call('ZRANGE', dead, 0, over - 1)) do
+			redis.call('DEL', prefix .. old)
+		end
+		redis.call('ZREMRANGEBYRANK', dead, 0, over - 1)
+	end
+end
+`
+
+// 移入死信
+// KEYS: active, dead, job
+// ARGV: id, lease, now, error, job 键前缀, max_dead
+var killScript = redis.NewScript(trimDead + `
+if redis.call('HGET', KEYS[3], 'lease') ~= ARGV[2] or redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[3], 'last_error', ARGV[4], 'failed_at', ARGV[3])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+trim_dead(KEYS[2], ARGV[5], tonumber(ARGV[6]))
+return 1
+`)
+
+// 停机时中断的任务放回队首，不计入尝试次数
+// KEYS: active, pending, job
+// ARGV: id, lease
+var requeueScript = redis.NewScript(`
+if redis.call('HGET', KEYS[3], 'lease') ~= ARGV[2] or redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HINCRBY', KEYS[3], 'attempts', -1)
+redis.call('RPUSH', KEYS[2], ARGV[1])
+return 1
+`)
+
+// 到期的延迟任务移入待执行；可见性超时的任务重新投递，次数用尽则移入死信
+// KEYS: scheduled, pending, active, dead
+// ARGV: now, job 键前缀, max_dead, batch
+var forwardScript = redis.NewScript(trimDead + `
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[4])
+for _, id in ipairs(due) do
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('LPUSH', KEYS[2], id)
+end
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, ARGV[4])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], id)
+	local key = ARGV[2] .. id
+	local attempts = tonumber(redis.call('HGET', key, 'attempts') or '0')
+	local max = tonumber(redis.call('HGET', key, 'max_attempts') or '1')
+	if attempts >= max then
+		redis.call('HSET', key, 'last_error', 'visibility timeout exceeded', 'failed_at', ARGV[1])
+		redis.call('ZADD', KEYS[4], ARGV[1], id)
+	else
+		redis.call('HSET', key, 'last_error', 'visibility timeout exceeded')
+		redis.call('LPUSH', KEYS[2], id)
+	end
+end
+if #expired > 0 then
+	trim_dead(KEYS[4], ARGV[2], tonumber(ARGV[3]))
+end
+return #due + #expired
+`)
+
+// 死信重新投递，尝试次数清零
+// KEYS: dead, pending, job
+// ARGV: id
+var retryDeadScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[3], 'attempts', 0)
+redis.call('HDEL', KEYS[3], 'failed_at')
+redis.call('LPUSH', KEYS[2], ARGV[1])
+return 1
+`)
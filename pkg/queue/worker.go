@@ -0,0 +1,267 @@
+// pkg/queue/worker.go
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	forwardBatch = 100
+	opTimeout    = 5 * time.Second
+	// visibilityGrace 可见性超时比执行时限多出的时间，处理函数在时限附近返回时仍来得及确认
+	visibilityGrace = 30 * time.Second
+)
+
+// Handler 任务处理函数。返回 nil 表示成功；返回错误时按退避重试，
+// 次数用尽或错误包装了 ErrSkipRetry 时进入死信
+type Handler interface {
+	ProcessJob(ctx context.Context, job *Job) error
+}
+
+// HandlerFunc 函数形式的 Handler
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+func (f HandlerFunc) ProcessJob(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// Worker 从队列领取任务并发执行
+type Worker struct {
+	client   *Client
+	cfg      config.QueueConfig
+	handlers map[string]Handler
+	logger   logger.Logger
+}
+
+func NewWorker(client *Client, cfg *config.Config, logger logger.Logger) *Worker {
+	return &Worker{
+		client:   client,
+		cfg:      cfg.Queue,
+		handlers: make(map[string]Handler),
+		logger:   logger.With(zap.String("module", "queue_worker")),
+	}
+}
+
+// Handle 注册任务类型的处理函数，需在 Run 之前调用
+func (w *Worker) Handle(jobType string, h Handler) {
+	w.handlers[jobType] = h
+}
+
+// Run 执行任务直到 ctx 取消。取消后不再领取新任务，等待执行中的任务完成，
+// 超过 shutdown_timeout 仍未完成的任务被取消并放回队列
+func (w *Worker) Run(ctx context.Context) {
+	// 任务使用独立的上下文，停机时先给执行中的任务留出完成时间
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.forwardLoop(ctx)
+	}()
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.processLoop(ctx, jobCtx)
+		}()
+	}
+	<-ctx.Done()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(w.cfg.ShutdownTimeout):
+		w.logger.Warn("shutdown timeout exceeded, requeueing running jobs")
+		cancelJobs()
+		<-done
+	}
+}
+
+func (w *Worker) processLoop(ctx, jobCtx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.fetch(jobCtx)
+		if err != nil {
+			w.logger.Error("fetch job failed", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+		w.process(jobCtx, job)
+	}
+}
+
+// fetch 按权重随机排列队列后依次尝试领取，全部为空时返回 nil
+func (w *Worker) fetch(ctx context.Context) (*Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	for _, queue := range w.queueOrder() {
+		k := w.client.keys(queue)
+		res, err := dequeueScript.Run(ctx, w.client.rdb,
+			[]string{k.pending, k.active}, k.jobPrefix, time.Now().UnixMilli(), visibilityGrace.Milliseconds()).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values, ok := res.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, fmt.Errorf("unexpected dequeue result %v", res)
+		}
+		id, _ := values[0].(string)
+		fields, _ := values[1].([]interface{})
+		return parseJob(queue, id, pairsToMap(fields))
+	}
+	return nil, nil
+}
+
+// queueOrder 按权重随机排序：权重越高越可能排在前面，低权重队列也有机会先被处理
+func (w *Worker) queueOrder() []string {
+	type weighted struct {
+		name string
+		key  float64
+	}
+	items := make([]weighted, 0, len(w.cfg.Queues))
+	for name, weight := range w.cfg.Queues {
+		// Efraimidis-Spirakis 加权随机抽样
+		items = append(items, weighted{name: name, key: -rand.ExpFloat64() / float64(weight)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].key > items[j].key })
+	order := make([]string, len(items))
+	for i, item := range items {
+		order[i] = item.name
+	}
+	return order
+}
+
+func (w *Worker) process(jobCtx context.Context, job *Job) {
+	log := w.logger.With(
+		zap.String("job_id", job.ID),
+		zap.String("job_type", job.Type),
+		zap.String("queue", job.Queue),
+		zap.Int("attempt", job.Attempts))
+
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		w.kill(job, "no handler registered for job type", log)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(jobCtx, job.Timeout)
+	start := time.Now()
+	err := w.call(ctx, handler, job)
+	cancel()
+
+	switch {
+	case err == nil:
+		k := w.client.keys(job.Queue)
+		w.runOp(log, "ack", func(ctx context.Context) error {
+			return ackScript.Run(ctx, w.client.rdb, []string{k.active, k.job(job.ID)}, job.ID, job.lease).Err()
+		})
+		log.Debug("job done", zap.Duration("duration", time.Since(start)))
+	case jobCtx.Err() != nil:
+		// 停机中断，放回队列由其他实例继续
+		k := w.client.keys(job.Queue)
+		w.runOp(log, "requeue", func(ctx context.Context) error {
+			return requeueScript.Run(ctx, w.client.rdb, []string{k.active, k.pending, k.job(job.ID)}, job.ID, job.lease).Err()
+		})
+		log.Info("job interrupted by shutdown, requeued")
+	case errors.Is(err, ErrSkipRetry) || job.Attempts >= job.MaxAttempts:
+		w.kill(job, err.Error(), log)
+	default:
+		delay := retryDelay(w.cfg.RetryBackoff, w.cfg.MaxBackoff, job.Attempts)
+		k := w.client.keys(job.Queue)
+		w.runOp(log, "retry", func(ctx context.Context) error {
+			return retryScript.Run(ctx, w.client.rdb, []string{k.active, k.scheduled, k.job(job.ID)},
+				job.ID, job.lease, time.Now().Add(delay).UnixMilli(), err.Error()).Err()
+		})
+		log.Warn("job failed, will retry", zap.Duration("delay", delay), zap.Error(err))
+	}
+}
+
+// call 执行处理函数，panic 视为执行失败
+func (w *Worker) call(ctx context.Context, h Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Error("job panicked", zap.String("job_id", job.ID), zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.ProcessJob(ctx, job)
+}
+
+func (w *Worker) kill(job *Job, reason string, log logger.Logger) {
+	k := w.client.keys(job.Queue)
+	w.runOp(log, "kill", func(ctx context.Context) error {
+		return killScript.Run(ctx, w.client.rdb, []string{k.active, k.dead, k.job(job.ID)},
+			job.ID, job.lease, time.Now().UnixMilli(), reason, k.jobPrefix, w.cfg.DeadMaxSize).Err()
+	})
+	log.Error("job moved to dead letter", zap.String("error", reason))
+}
+
+// runOp 执行状态迁移。使用独立的超时上下文，停机过程中也能完成
+func (w *Worker) runOp(log logger.Logger, name string, op func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+	if err := op(ctx); err != nil && err != redis.Nil {
+		log.Error("queue operation failed", zap.String("op", name), zap.Error(err))
+	}
+}
+
+// forwardLoop 定期将到期的延迟任务和可见性超时的任务移回待执行队列
+func (w *Worker) forwardLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for queue := range w.cfg.Queues {
+			k := w.client.keys(queue)
+			err := forwardScript.Run(ctx, w.client.rdb,
+				[]string{k.scheduled, k.pending, k.active, k.dead},
+				time.Now().UnixMilli(), k.jobPrefix, w.cfg.DeadMaxSize, forwardBatch).Err()
+			if err != nil && ctx.Err() == nil {
+				w.logger.Error("forward scheduled jobs failed", zap.String("queue", queue), zap.Error(err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryDelay 第 attempts 次失败后的等待时间：base * 2^(attempts-1)，不超过 max
+func retryDelay(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,216 @@
+// pkg/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrUnknownAlgorithm 不支持的限流算法
+var ErrUnknownAlgorithm = errors.New("ratelimit: unknown algorithm")
+
+// 支持的限流算法
+const (
+	SlidingWindow = "sliding_window" // 滑动窗口日志，精确但每个请求占用一个 ZSET 成员
+	TokenBucket   = "token_bucket"   // 令牌桶，每个 key 只存令牌数和时间
+	GCRA          = "gcra"           // 通用信元速率算法，每个 key 只存一个时间戳
+)
+
+// Rule 限流规则：每个 Window 内最多 Limit 次。
+// TokenBucket 和 GCRA 按 Limit/Window 的平均速率放行，Burst 为允许连续突发的请求数，不大于 0 时取 Limit
+type Rule struct {
+	Algorithm string
+	Limit     int64
+	Window    time.Duration
+	Burst     int64
+}
+
+// Capacity 允许连续放行的请求数：TokenBucket 和 GCRA 为 Burst，未设置时为 Limit
+func (r Rule) Capacity() int64 {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration // 被拒绝时距离下次可能放行的时间
+}
+
+// Supported 算法是否受支持
+func Supported(algorithm string) bool {
+	switch algorithm {
+	case SlidingWindow, TokenBucket, GCRA:
+		return true
+	}
+	return false
+}
+
+// Limiter 基于 Redis 的限流器，各实例共享计数。脚本经 EVALSHA 执行，只传输摘要；
+// 服务端未缓存时自动回退为 EVAL，之后即被缓存
+type Limiter struct {
+	client redis.UniversalClient
+}
+
+func NewLimiter(client redis.UniversalClient) *Limiter {
+	return &Limiter{client: client}
+}
+
+// Allow 按规则判断 key 的本次请求是否放行，放行时计入用量
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	switch rule.Algorithm {
+	case SlidingWindow:
+		return l.slidingWindow(ctx, key, rule)
+	case TokenBucket:
+		return l.tokenBucket(ctx, key, rule)
+	case GCRA:
+		return l.gcra(ctx, key, rule)
+	}
+	return Result{}, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, rule.Algorithm)
+}
+
+// KEYS: key
+// ARGV: now(ms), window(ms), limit, member
+// 返回 {是否放行, 剩余次数, 重试等待(ms)}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+-- 移除窗口外的记录
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local retry = window
+	if oldest[2] then
+		retry = tonumber(oldest[2]) + window - now
+	end
+	return {0, 0, retry}
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, limit - count - 1, 0}
+`)
+
+func (l *Limiter) slidingWindow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := time.Now().UnixMilli()
+	// 成员需唯一，同一毫秒内的请求才不会相互覆盖
+	member := fmt.Sprintf("%d:%d", now, rand.Int63())
+	res, err := slidingWindowScript.Run(ctx, l.client, []string{key},
+		now, rule.Window.Milliseconds(), rule.Limit, member).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// 令牌桶。时间取 Redis 服务器时间，不受各实例时钟偏差影响
+// KEYS: key
+// ARGV: capacity, rate（每毫秒补充的令牌数）
+// 返回 {是否放行, 剩余令牌, 重试等待(ms)}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+end
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+-- 桶补满之后状态等同于不存在
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate))
+return {allowed, math.floor(tokens), retry}
+`)
+
+func (l *Limiter) tokenBucket(ctx context.Context, key string, rule Rule) (Result, error) {
+	rate := float64(rule.Limit) / float64(max(rule.Window.Milliseconds(), 1))
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{key}, rule.Capacity(), rate).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      rule.Capacity(),
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// GCRA：记录理论到达时间 tat，请求使 tat 后移一个间隔，tat 领先当前时间不超过 burst 个间隔即放行。
+// 时间单位为微秒，取 Redis 服务器时间
+// KEYS: key
+// ARGV: interval（两次请求的平均间隔，us）, burst
+// 返回 {是否放行, 剩余可突发次数, 重试等待(ms)}
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local tolerance = interval * tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then
+	tat = now
+end
+local new_tat = tat + interval
+local ahead = new_tat - now
+if ahead > tolerance then
+	return {0, 0, math.ceil((ahead - tolerance) / 1000)}
+end
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil(ahead / 1000))
+return {1, math.floor((tolerance - ahead) / interval), 0}
+`)
+
+func (l *Limiter) gcra(ctx context.Context, key string, rule Rule) (Result, error) {
+	interval := rule.Window.Microseconds() / rule.Limit
+	if interval <= 0 {
+		interval = 1
+	}
+	res, err := gcraScript.Run(ctx, l.client, []string{key}, interval, rule.Capacity()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      rule.Capacity(),
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// Load 预先把全部脚本加载到 Redis，之后的请求直接 EVALSHA 命中。加载失败不影响使用
+func (l *Limiter) Load(ctx context.Context) error {
+	for _, script := range []*redis.Script{slidingWindowScript, tokenBucketScript, gcraScript} {
+		if err := script.Load(ctx, l.client).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
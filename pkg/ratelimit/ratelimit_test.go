@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// newTestLimiter 令牌桶和 GCRA 使用 Redis 服务器时间，测试中冻结为 start，由 advance 推进
+func newTestLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis, func(d time.Duration)) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		mr.SetTime(now)
+	}
+	return NewLimiter(client), mr, advance
+}
+
+func mustAllow(t *testing.T, l *Limiter, key string, rule Rule) Result {
+	t.Helper()
+	res, err := l.Allow(context.Background(), key, rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestBurstThenSteadyRate(t *testing.T) {
+	for _, algorithm := range []string{TokenBucket, GCRA} {
+		t.Run(algorithm, func(t *testing.T) {
+			l, _, advance := newTestLimiter(t)
+			// 平均每 100ms 一次，允许连续 5 次
+			rule := Rule{Algorithm: algorithm, Limit: 10, Window: time.Second, Burst: 5}
+
+			for i := int64(1); i <= 5; i++ {
+				res := mustAllow(t, l, "k", rule)
+				if !res.Allowed || res.Limit != 5 || res.Remaining != 5-i {
+					t.Fatalf("burst request %d: %+v", i, res)
+				}
+			}
+			res := mustAllow(t, l, "k", rule)
+			if res.Allowed || res.Remaining != 0 || res.RetryAfter != 100*time.Millisecond {
+				t.Fatalf("over burst: %+v, want rejected with retry after 100ms", res)
+			}
+
+			// 稳定状态下每个间隔放行一次
+			for i := 0; i < 3; i++ {
+				advance(99 * time.Millisecond)
+				if res := mustAllow(t, l, "k", rule); res.Allowed {
+					t.Fatalf("round %d: allowed before the interval elapsed", i)
+				}
+				advance(time.Millisecond)
+				if res := mustAllow(t, l, "k", rule); !res.Allowed {
+					t.Fatalf("round %d: rejected after the interval: %+v", i, res)
+				}
+				if res := mustAllow(t, l, "k", rule); res.Allowed {
+					t.Fatalf("round %d: second request in the same interval allowed", i)
+				}
+			}
+
+			// 长时间空闲后最多恢复到 burst
+			advance(time.Hour)
+			for i := 0; i < 5; i++ {
+				if res := mustAllow(t, l, "k", rule); !res.Allowed {
+					t.Fatalf("after idle, request %d rejected", i+1)
+				}
+			}
+			if res := mustAllow(t, l, "k", rule); res.Allowed {
+				t.Fatal("after idle, burst exceeded")
+			}
+
+			// 各 key 独立计数
+			if res := mustAllow(t, l, "other", rule); !res.Allowed || res.Remaining != 4 {
+				t.Fatalf("other key: %+v", res)
+			}
+		})
+	}
+}
+
+func TestBurstDefaultsToLimit(t *testing.T) {
+	for _, algorithm := range []string{TokenBucket, GCRA} {
+		t.Run(algorithm, func(t *testing.T) {
+			l, _, _ := newTestLimiter(t)
+			rule := Rule{Algorithm: algorithm, Limit: 3, Window: time.Minute}
+			for i := 0; i < 3; i++ {
+				if res := mustAllow(t, l, "k", rule); !res.Allowed || res.Limit != 3 {
+					t.Fatalf("request %d: %+v", i+1, res)
+				}
+			}
+			if res := mustAllow(t, l, "k", rule); res.Allowed {
+				t.Fatal("limit exceeded")
+			}
+		})
+	}
+}
+
+// 间隔不是整毫秒时，等待 RetryAfter 后必须放行，不能因取整提前或推迟
+func TestRetryAfterIsEnoughWithFractionalInterval(t *testing.T) {
+	for _, algorithm := range []string{TokenBucket, GCRA} {
+		t.Run(algorithm, func(t *testing.T) {
+			l, _, advance := newTestLimiter(t)
+			// 间隔 333.33ms
+			rule := Rule{Algorithm: algorithm, Limit: 3, Window: time.Second, Burst: 1}
+			if res := mustAllow(t, l, "k", rule); !res.Allowed {
+				t.Fatal("first request rejected")
+			}
+			for i := 0; i < 10; i++ {
+				res := mustAllow(t, l, "k", rule)
+				if res.Allowed {
+					t.Fatalf("round %d: allowed without waiting", i)
+				}
+				if res.RetryAfter <= 0 || res.RetryAfter > 334*time.Millisecond {
+					t.Fatalf("round %d: retry after %v", i, res.RetryAfter)
+				}
+				advance(res.RetryAfter)
+				if res := mustAllow(t, l, "k", rule); !res.Allowed {
+					t.Fatalf("round %d: rejected after waiting retry after: %+v", i, res)
+				}
+			}
+		})
+	}
+}
+
+func TestStateExpiresOnceRefilled(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      time.Duration
+	}{
+		// 令牌桶：补满 5 个令牌需要 500ms
+		{TokenBucket, 500 * time.Millisecond},
+		// GCRA：两次请求后 tat 领先当前时间 200ms
+		{GCRA, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			l, mr, _ := newTestLimiter(t)
+			rule := Rule{Algorithm: tt.algorithm, Limit: 10, Window: time.Second, Burst: 5}
+			mustAllow(t, l, "k", rule)
+			mustAllow(t, l, "k", rule)
+			if ttl := mr.TTL("k"); ttl != tt.want {
+				t.Fatalf("ttl = %v, want %v", ttl, tt.want)
+			}
+			// 状态过期等同于桶已补满
+			mr.FastForward(tt.want)
+			if mr.Exists("k") {
+				t.Fatal("state not expired")
+			}
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	l, _, _ := newTestLimiter(t)
+	rule := Rule{Algorithm: SlidingWindow, Limit: 3, Window: time.Minute}
+	for i := int64(1); i <= 3; i++ {
+		if res := mustAllow(t, l, "k", rule); !res.Allowed || res.Remaining != 3-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res := mustAllow(t, l, "k", rule)
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("over limit: %+v", res)
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	l, _, _ := newTestLimiter(t)
+	if _, err := l.Allow(context.Background(), "k", Rule{Algorithm: "leaky", Limit: 1, Window: time.Second}); err == nil {
+		t.Fatal("expected error")
+	}
+}
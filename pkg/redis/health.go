@@ -0,0 +1,203 @@
+// pkg/redis/health.go
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gin-wire-demo/internal/config"
+	"gin-wire-demo/pkg/logger"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// ErrUnavailable Redis 不可用且降级策略为 fail_closed 时返回
+var ErrUnavailable = errors.New("redis: unavailable")
+
+// 依赖 Redis 的功能，各自配置降级策略
+const (
+	FeatureBlacklist = "blacklist"
+	FeatureLoginLock = "login_lock"
+	FeatureCache     = "cache"
+	FeatureRateLimit = "rate_limit"
+)
+
+// HealthWatcher 定期 PING Redis。不可用期间各功能直接走降级分支，不必每个请求都等到超时
+type HealthWatcher struct {
+	client   redis.UniversalClient
+	interval time.Duration
+	healthy  atomic.Bool
+	logger   logger.Logger
+	features map[string]*Degrader
+	order    []string
+}
+
+func NewHealthWatcher(client redis.UniversalClient, cfg *config.Config, logger logger.Logger) *HealthWatcher {
+	w := &HealthWatcher{
+		client:   client,
+		interval: cfg.Redis.HealthCheckInterval,
+		logger:   logger.With(zap.String("module", "redis_health")),
+		features: make(map[string]*Degrader),
+	}
+	policies := map[string]string{
+		FeatureBlacklist: cfg.Redis.Degrade.Blacklist,
+		FeatureLoginLock: cfg.Redis.Degrade.LoginLock,
+		FeatureCache:     cfg.Redis.Degrade.Cache,
+		FeatureRateLimit: cfg.Redis.Degrade.RateLimit,
+	}
+	for _, feature := range []string{FeatureBlacklist, FeatureLoginLock, FeatureCache, FeatureRateLimit} {
+		w.features[feature] = &Degrader{feature: feature, policy: policies[feature], watcher: w}
+		w.order = append(w.order, feature)
+	}
+	// 启动时 NewRedisClient 已检查过连接，这里先视为可用，由 Run 更正
+	w.healthy.Store(true)
+	return w
+}
+
+// Healthy 最近一次检查时 Redis 是否可用
+func (w *HealthWatcher) Healthy() bool {
+	return w.healthy.Load()
+}
+
+// Degrader 返回功能的降级控制
+func (w *HealthWatcher) Degrader(feature string) *Degrader {
+	return w.features[feature]
+}
+
+// Run 按 health_check_interval 检查 Redis 直到 ctx 取消
+func (w *HealthWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *HealthWatcher) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, w.interval)
+	defer cancel()
+	err := w.client.Ping(pingCtx).Err()
+	if ctx.Err() != nil {
+		return
+	}
+	healthy := err == nil
+	if w.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		w.logger.Info("Redis is available again")
+		for _, feature := range w.order {
+			w.features[feature].recover()
+		}
+	} else {
+		w.logger.Error("Redis is unavailable, degrading dependent features", zap.Error(err))
+	}
+}
+
+// Stats 各功能的降级统计
+func (w *HealthWatcher) Stats() []DegradeStats {
+	stats := make([]DegradeStats, 0, len(w.order))
+	for _, feature := range w.order {
+		stats = append(stats, w.features[feature].Stats())
+	}
+	return stats
+}
+
+// Degrader 单个功能的降级控制和统计
+type Degrader struct {
+	feature string
+	policy  string
+	watcher *HealthWatcher
+
+	degraded      atomic.Bool // 供 recover 快速判断，避免正常情况下每次调用都加锁
+	mu            sync.Mutex
+	degradedSince time.Time // 零值表示未降级
+	degradedTotal time.Duration
+	degradedCalls int64
+}
+
+// DegradeStats 功能的降级统计
+type DegradeStats struct {
+	Feature         string     `json:"feature"`
+	Policy          string     `json:"policy"`
+	Degraded        bool       `json:"degraded"`
+	DegradedSince   *time.Time `json:"degraded_since,omitempty"`
+	DegradedSeconds float64    `json:"degraded_seconds"` // 累计处于降级状态的时间，含当前这次
+	DegradedCalls   int64      `json:"degraded_calls"`   // 按降级策略处理的调用次数
+}
+
+// Policy 降级策略：config.DegradeFailOpen、DegradeFailClosed 或 DegradeLocal
+func (d *Degrader) Policy() string {
+	return d.policy
+}
+
+// Call Redis 可用时执行 fn 并返回 true；Redis 不可用或 fn 返回错误时返回 false，
+// 调用方按 Policy 降级。fn 不应把 redis.Nil 等业务上的结果当作错误返回
+func (d *Degrader) Call(fn func() error) bool {
+	if d.watcher.Healthy() {
+		if err := fn(); err == nil {
+			d.recover()
+			return true
+		}
+	}
+	d.degrade()
+	return false
+}
+
+func (d *Degrader) degrade() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.degradedSince.IsZero() {
+		d.degradedSince = time.Now()
+		d.degraded.Store(true)
+		d.watcher.logger.Warn("Feature degraded",
+			zap.String("feature", d.feature), zap.String("policy", d.policy))
+	}
+	d.degradedCalls++
+}
+
+func (d *Degrader) recover() {
+	if !d.degraded.Load() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.degradedSince.IsZero() {
+		return
+	}
+	elapsed := time.Since(d.degradedSince)
+	d.degradedTotal += elapsed
+	d.degradedSince = time.Time{}
+	d.degraded.Store(false)
+	d.watcher.logger.Info("Feature recovered",
+		zap.String("feature", d.feature), zap.Duration("degraded_for", elapsed))
+}
+
+// Stats 降级统计
+func (d *Degrader) Stats() DegradeStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := DegradeStats{
+		Feature:       d.feature,
+		Policy:        d.policy,
+		DegradedCalls: d.degradedCalls,
+	}
+	total := d.degradedTotal
+	if !d.degradedSince.IsZero() {
+		since := d.degradedSince
+		stats.Degraded = true
+		stats.DegradedSince = &since
+		total += time.Since(since)
+	}
+	stats.DegradedSeconds = total.Seconds()
+	return stats
+}
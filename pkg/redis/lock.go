@@ -0,0 +1,278 @@
+// pkg/redis/lock.go
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gin-wire-demo/internal/config"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotObtained 锁或信号量已被占用，等待超时仍未获得
+	ErrNotObtained = errors.New("redis: lock not obtained")
+	// ErrNotHeld 锁或许可已过期或被他人持有，释放和续期时返回
+	ErrNotHeld = errors.New("redis: lock not held")
+)
+
+const defaultRetryInterval = 50 * time.Millisecond
+
+// 获取锁，成功时返回递增的 fencing token，失败返回 0。
+// 所有锁共用一个计数器，对单个锁而言同样单调递增，且不会为每个锁名留下常驻的键
+// KEYS: lock, fence
+// ARGV: value, ttl(ms)
+var obtainScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// 只释放自己持有的锁
+// KEYS: lock
+// ARGV: value
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// 只续期自己持有的锁
+// KEYS: lock
+// ARGV: value, ttl(ms)
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Locker 创建分布式锁和信号量。键统一带 lock:{<app>}: 前缀，
+// hash tag 使锁与共用的 fencing 计数器落在同一个槽，集群模式下脚本也能执行
+type Locker struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewLocker(client redis.UniversalClient, cfg *config.Config) *Locker {
+	return &Locker{
+		client: client,
+		prefix: fmt.Sprintf("lock:{%s}:", cfg.App.Name),
+	}
+}
+
+type obtainOptions struct {
+	waitTimeout   time.Duration
+	retryInterval time.Duration
+	autoExtend    bool
+}
+
+// Option 获取锁或信号量许可的选项
+type Option func(*obtainOptions)
+
+// WaitTimeout 被占用时最多等待 d，默认不等待
+func WaitTimeout(d time.Duration) Option {
+	return func(o *obtainOptions) { o.waitTimeout = d }
+}
+
+// RetryInterval 等待期间的重试间隔，默认 50ms
+func RetryInterval(d time.Duration) Option {
+	return func(o *obtainOptions) { o.retryInterval = d }
+}
+
+// AutoExtend 持有期间每隔 ttl/3 自动续期，直到 Release。续期失败时 Lost 关闭
+func AutoExtend() Option {
+	return func(o *obtainOptions) { o.autoExtend = true }
+}
+
+func newObtainOptions(opts []Option) obtainOptions {
+	o := obtainOptions{retryInterval: defaultRetryInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// retry 反复调用 try 直到成功、出错、等待超时或 ctx 取消
+func retry(ctx context.Context, o obtainOptions, try func() (bool, error)) error {
+	var deadline time.Time
+	if o.waitTimeout > 0 {
+		deadline = time.Now().Add(o.waitTimeout)
+	}
+	for {
+		ok, err := try()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if deadline.IsZero() || time.Now().Add(o.retryInterval).After(deadline) {
+			return ErrNotObtained
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(o.retryInterval):
+		}
+	}
+}
+
+// Obtain 获取互斥锁，ttl 为锁的有效期。未获得时返回 ErrNotObtained
+func (l *Locker) Obtain(ctx context.Context, key string, ttl time.Duration, opts ...Option) (*Lock, error) {
+	o := newObtainOptions(opts)
+	lock := &Lock{
+		client: l.client,
+		key:    l.prefix + key,
+		value:  uuid.NewString(),
+		ttl:    ttl,
+		lost:   make(chan struct{}),
+	}
+	fenceKey := l.prefix + "fence"
+	err := retry(ctx, o, func() (bool, error) {
+		token, err := obtainScript.Run(ctx, l.client, []string{lock.key, fenceKey}, lock.value, ttl.Milliseconds()).Int64()
+		if err != nil {
+			return false, err
+		}
+		lock.token = token
+		return token > 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.autoExtend {
+		lock.startAutoExtend()
+	}
+	return lock, nil
+}
+
+// TryLock 尝试获取一次，不等待
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return l.Obtain(ctx, key, ttl)
+}
+
+// WithLock 持有锁（自动续期）执行 fn，结束后释放。锁丢失时取消传给 fn 的 ctx
+func (l *Locker) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...Option) error {
+	lock, err := l.Obtain(ctx, key, ttl, append(opts, AutoExtend())...)
+	if err != nil {
+		return err
+	}
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-fnCtx.Done():
+		}
+	}()
+
+	fnErr := fn(fnCtx)
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), ttl)
+	defer releaseCancel()
+	if err := lock.Release(releaseCtx); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
+
+// Lock 已获得的互斥锁
+type Lock struct {
+	client redis.UniversalClient
+	key    string
+	value  string
+	token  int64
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopped  chan struct{}
+	lost     chan struct{}
+	lostOnce sync.Once
+}
+
+// Token 单调递增的 fencing token。下游写入时携带并拒绝比已见过更小的 token，
+// 可防止锁过期后旧持有者的延迟写入覆盖新持有者的结果
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Lost 锁丢失（自动续期失败）时关闭
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Extend 将有效期重置为 ttl
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	ok, err := extendScript.Run(ctx, l.client, []string{l.key}, l.value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Release 释放锁，锁已过期或被他人持有时返回 ErrNotHeld
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopAutoExtend()
+	n, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.value).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (l *Lock) startAutoExtend() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 协程只使用局部变量，stopAutoExtend 会清空 l.stop
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	l.stop, l.stopped = stop, stopped
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			err := l.Extend(ctx, l.ttl)
+			cancel()
+			// 网络错误时在下一个周期重试，直到确认锁已不属于自己
+			if errors.Is(err, ErrNotHeld) {
+				l.markLost()
+				return
+			}
+		}
+	}()
+}
+
+func (l *Lock) stopAutoExtend() {
+	l.mu.Lock()
+	stop, stopped := l.stop, l.stopped
+	l.stop = nil
+	l.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+}
+
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
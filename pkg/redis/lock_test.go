@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gin-wire-demo/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestLocker(t *testing.T) (*Locker, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewLocker(client, &config.Config{App: config.AppConfig{Name: "test"}}), mr
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+
+	a, err := locker.Obtain(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.TryLock(ctx, "job", time.Minute); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("second obtain: got %v, want ErrNotObtained", err)
+	}
+	// 其他锁名不受影响
+	other, err := locker.TryLock(ctx, "other", time.Minute)
+	if err != nil {
+		t.Fatalf("other lock: %v", err)
+	}
+	if err := other.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	b, err := locker.TryLock(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("obtain after release: %v", err)
+	}
+	if err := b.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithLockRunsOneHolderAtATime(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+
+	var holders, maxHolders, runs atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := locker.WithLock(ctx, "job", time.Second, func(ctx context.Context) error {
+				n := holders.Add(1)
+				defer holders.Add(-1)
+				for {
+					m := maxHolders.Load()
+					if n <= m || maxHolders.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				runs.Add(1)
+				return nil
+			}, WaitTimeout(5*time.Second), RetryInterval(time.Millisecond))
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if runs.Load() != 8 || maxHolders.Load() != 1 {
+		t.Fatalf("runs=%d max concurrent holders=%d", runs.Load(), maxHolders.Load())
+	}
+}
+
+func TestFencingTokensIncrease(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+
+	var last int64
+	for i, key := range []string{"a", "a", "b", "a", "b"} {
+		lock, err := locker.Obtain(ctx, key, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lock.Token() <= last {
+			t.Fatalf("obtain %d: token %d not greater than %d", i, lock.Token(), last)
+		}
+		last = lock.Token()
+		if err := lock.Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReleaseAfterExpiryOrByOtherHolder(t *testing.T) {
+	locker, mr := newTestLocker(t)
+	ctx := context.Background()
+
+	a, err := locker.Obtain(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(2 * time.Second)
+	if err := a.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("release after expiry: got %v, want ErrNotHeld", err)
+	}
+
+	a, err = locker.Obtain(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(2 * time.Second)
+	b, err := locker.Obtain(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("obtain after expiry: %v", err)
+	}
+	if b.Token() <= a.Token() {
+		t.Fatalf("new holder token %d not greater than %d", b.Token(), a.Token())
+	}
+	if err := a.Extend(ctx, time.Minute); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("extend by old holder: got %v, want ErrNotHeld", err)
+	}
+	if err := a.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("release by old holder: got %v, want ErrNotHeld", err)
+	}
+	// 旧持有者的释放不影响新持有者
+	if _, err := locker.TryLock(ctx, "job", time.Minute); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("lock released by old holder: got %v", err)
+	}
+	if err := b.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoExtendAndLost(t *testing.T) {
+	locker, mr := newTestLocker(t)
+	ctx := context.Background()
+	const ttl = 150 * time.Millisecond
+
+	lock, err := locker.Obtain(ctx, "job", ttl, AutoExtend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := locker.prefix + "job"
+
+	// 续期会把有效期重置为 ttl
+	mr.SetTTL(key, time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for mr.TTL(key) != ttl {
+		if time.Now().After(deadline) {
+			t.Fatalf("lock not extended, ttl %v", mr.TTL(key))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-lock.Lost():
+		t.Fatal("lost while still held")
+	default:
+	}
+
+	// 被他人占用后续期失败，Lost 关闭
+	if err := mr.Set(key, "someone-else"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost not closed after the lock was taken over")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("release after lost: got %v, want ErrNotHeld", err)
+	}
+}
+
+func TestWithLockCancelsOnLost(t *testing.T) {
+	locker, mr := newTestLocker(t)
+	ctx := context.Background()
+
+	err := locker.WithLock(ctx, "job", 150*time.Millisecond, func(ctx context.Context) error {
+		mr.Del(locker.prefix + "job")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			t.Error("fn context not cancelled after the lock was lost")
+			return nil
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
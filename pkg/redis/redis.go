@@ -3,36 +3,81 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"gin-wire-demo/internal/config"
 	"log"
+	"os"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
-func NewRedisClient(cfg *config.Config) (*redis.Client, func(), error) {
-	options := &redis.Options{
-		Addr:         cfg.Redis.Addr,
-		Password:     cfg.Redis.Password, // 使用配置中的密码而不是硬编码空字符串
-		DB:           cfg.Redis.DB,       // 使用配置中的DB编号
-		PoolSize:     cfg.Redis.PoolSize, // 使用配置中的连接池大小
-		DialTimeout:  time.Duration(cfg.Redis.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
-		PoolTimeout:  time.Duration(cfg.Redis.PoolTimeout) * time.Second,
+// NewRedisClient 按 redis.mode 创建单节点、哨兵或集群客户端。
+// 依赖方只使用 redis.UniversalClient，多键操作需保证键在同一个槽（hash tag）
+func NewRedisClient(cfg *config.Config) (redis.UniversalClient, func(), error) {
+	tlsConfig, err := newTLSConfig(cfg.Redis.TLS)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	client := redis.NewClient(options)
+	var client redis.UniversalClient
+	switch cfg.Redis.Mode {
+	case config.RedisModeSentinel:
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.Redis.MasterName,
+			SentinelAddrs:    cfg.Redis.Addrs,
+			SentinelPassword: cfg.Redis.SentinelPassword,
+			Username:         cfg.Redis.Username,
+			Password:         cfg.Redis.Password,
+			DB:               cfg.Redis.DB,
+			PoolSize:         cfg.Redis.PoolSize,
+			DialTimeout:      time.Duration(cfg.Redis.DialTimeout) * time.Second,
+			ReadTimeout:      time.Duration(cfg.Redis.ReadTimeout) * time.Second,
+			WriteTimeout:     time.Duration(cfg.Redis.WriteTimeout) * time.Second,
+			PoolTimeout:      time.Duration(cfg.Redis.PoolTimeout) * time.Second,
+			TLSConfig:        tlsConfig,
+		})
+	case config.RedisModeCluster:
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Redis.Addrs,
+			Username:     cfg.Redis.Username,
+			Password:     cfg.Redis.Password,
+			PoolSize:     cfg.Redis.PoolSize, // 每个节点的连接池大小
+			DialTimeout:  time.Duration(cfg.Redis.DialTimeout) * time.Second,
+			ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
+			WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
+			PoolTimeout:  time.Duration(cfg.Redis.PoolTimeout) * time.Second,
+			TLSConfig:    tlsConfig,
+		})
+	default:
+		client = redis.NewClient(&redis.Options{
+			Addr:         cfg.Redis.Addr,
+			Username:     cfg.Redis.Username,
+			Password:     cfg.Redis.Password, // 使用配置中的密码而不是硬编码空字符串
+			DB:           cfg.Redis.DB,       // 使用配置中的DB编号
+			PoolSize:     cfg.Redis.PoolSize, // 使用配置中的连接池大小
+			DialTimeout:  time.Duration(cfg.Redis.DialTimeout) * time.Second,
+			ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
+			WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
+			PoolTimeout:  time.Duration(cfg.Redis.PoolTimeout) * time.Second,
+			TLSConfig:    tlsConfig,
+		})
+	}
 
 	// 使用独立的context进行ping测试
 	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer pingCancel()
 
 	if _, err := client.Ping(pingCtx).Result(); err != nil {
-		// 如果ping失败，立即关闭连接
-		_ = client.Close()
-		return nil, nil, fmt.Errorf("redis ping failed: %w", err)
+		if cfg.Redis.RequireOnStartup {
+			// 如果ping失败，立即关闭连接
+			_ = client.Close()
+			return nil, nil, fmt.Errorf("redis ping failed: %w", err)
+		}
+		// 客户端会在 Redis 恢复后自动重连，期间依赖 Redis 的功能按降级策略处理
+		log.Printf("redis ping failed, starting in degraded mode: %v", err)
 	}
 
 	cleanup := func() {
@@ -43,3 +88,34 @@ func NewRedisClient(cfg *config.Config) (*redis.Client, func(), error) {
 
 	return client, cleanup, nil
 }
+
+// newTLSConfig 未启用 TLS 时返回 nil
+func newTLSConfig(cfg config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in redis ca file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,108 @@
+// pkg/redis/semaphore.go
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// 清理过期许可后，未满 limit 时登记新许可
+// KEYS: semaphore
+// ARGV: now(ms), expire_at(ms), id, limit, ttl(ms)
+var acquireScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[4]) then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], ARGV[5])
+	return 1
+end
+return 0
+`)
+
+// 续期未过期的许可
+// KEYS: semaphore
+// ARGV: now(ms), expire_at(ms), id, ttl(ms)
+var extendPermitScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[3])
+if not score or tonumber(score) <= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// Semaphore 计数信号量，最多 limit 个持有者。许可带有效期，
+// 持有者崩溃后许可到期自动回收；过期判断使用各实例的本地时钟
+type Semaphore struct {
+	client redis.UniversalClient
+	key    string
+	limit  int
+	ttl    time.Duration
+}
+
+// NewSemaphore 创建名为 name 的信号量，同名信号量在各实例间共享
+func (l *Locker) NewSemaphore(name string, limit int, ttl time.Duration) *Semaphore {
+	return &Semaphore{
+		client: l.client,
+		key:    l.prefix + "sem:" + name,
+		limit:  limit,
+		ttl:    ttl,
+	}
+}
+
+// Acquire 获取一个许可，已满时按 WaitTimeout 等待，仍未获得返回 ErrNotObtained
+func (s *Semaphore) Acquire(ctx context.Context, opts ...Option) (*Permit, error) {
+	o := newObtainOptions(opts)
+	permit := &Permit{sem: s, id: uuid.NewString()}
+	err := retry(ctx, o, func() (bool, error) {
+		now := time.Now()
+		ok, err := acquireScript.Run(ctx, s.client, []string{s.key},
+			now.UnixMilli(), now.Add(s.ttl).UnixMilli(), permit.id, s.limit, s.ttl.Milliseconds()).Int()
+		return ok == 1, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permit, nil
+}
+
+// TryAcquire 尝试获取一次，不等待
+func (s *Semaphore) TryAcquire(ctx context.Context) (*Permit, error) {
+	return s.Acquire(ctx)
+}
+
+// Permit 信号量许可
+type Permit struct {
+	sem *Semaphore
+	id  string
+}
+
+// Extend 将许可有效期重置为信号量的 ttl，已过期时返回 ErrNotHeld
+func (p *Permit) Extend(ctx context.Context) error {
+	now := time.Now()
+	ok, err := extendPermitScript.Run(ctx, p.sem.client, []string{p.sem.key},
+		now.UnixMilli(), now.Add(p.sem.ttl).UnixMilli(), p.id, p.sem.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Release 归还许可，已过期被回收时返回 ErrNotHeld
+func (p *Permit) Release(ctx context.Context) error {
+	n, err := p.sem.client.ZRem(ctx, p.sem.key, p.id).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
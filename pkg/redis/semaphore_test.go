@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSemaphoreLimit(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+	sem := locker.NewSemaphore("export", 2, time.Minute)
+
+	p1, err := sem.TryAcquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := sem.TryAcquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.TryAcquire(ctx); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("third acquire: got %v, want ErrNotObtained", err)
+	}
+
+	// 等待期间有许可归还
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = p1.Release(ctx)
+	}()
+	p3, err := sem.Acquire(ctx, WaitTimeout(time.Second), RetryInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("acquire while waiting: %v", err)
+	}
+	if err := p1.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("double release: got %v, want ErrNotHeld", err)
+	}
+	for _, p := range []*Permit{p2, p3} {
+		if err := p.Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSemaphoreReclaimsExpiredPermits(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+	const ttl = 100 * time.Millisecond
+	sem := locker.NewSemaphore("export", 1, ttl)
+
+	stale, err := sem.TryAcquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.TryAcquire(ctx); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("acquire while held: got %v, want ErrNotObtained", err)
+	}
+
+	// 续期推迟过期
+	time.Sleep(ttl / 2)
+	if err := stale.Extend(ctx); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	time.Sleep(ttl / 2)
+	if _, err := sem.TryAcquire(ctx); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("acquire after extend: got %v, want ErrNotObtained", err)
+	}
+
+	// 持有者不再续期，过期后许可被回收
+	time.Sleep(ttl + 20*time.Millisecond)
+	fresh, err := sem.TryAcquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire after expiry: %v", err)
+	}
+	if err := stale.Extend(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("extend expired permit: got %v, want ErrNotHeld", err)
+	}
+	if err := stale.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("release expired permit: got %v, want ErrNotHeld", err)
+	}
+	if err := fresh.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
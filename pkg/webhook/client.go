@@ -0,0 +1,118 @@
+// pkg/webhook/client.go
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidURL     = errors.New("invalid webhook url")
+	ErrForbiddenRoute = errors.New("webhook target resolves to a non-public address")
+)
+
+// ValidateURL 校验回调地址。默认只允许 https 且主机不能是内网、回环等地址；
+// 域名在连接时才解析，由 NewClient 的拨号检查兜底
+func ValidateURL(raw string, allowInsecure bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !allowInsecure {
+			return ErrInvalidURL
+		}
+	default:
+		return ErrInvalidURL
+	}
+	if allowInsecure {
+		return nil
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return ErrForbiddenRoute
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return ErrForbiddenRoute
+	}
+	return nil
+}
+
+// NewClient 创建投递用的 HTTP 客户端：不跟随重定向，
+// allowInsecure 为 false 时拒绝连接解析到非公网地址的目标，防止 SSRF
+func NewClient(timeout time.Duration, allowInsecure bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowInsecure {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return ErrForbiddenRoute
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// nonPublicNets 标准库判断之外的非公网地址段（IANA 特殊用途地址）
+var nonPublicNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",       // 本网络
+		"100.64.0.0/10",   // 运营商级 NAT
+		"192.0.0.0/24",    // IETF 协议分配
+		"192.0.2.0/24",    // 文档示例 TEST-NET-1
+		"192.88.99.0/24",  // 6to4 中继任播（已废弃）
+		"198.18.0.0/15",   // 网络设备基准测试
+		"198.51.100.0/24", // 文档示例 TEST-NET-2
+		"203.0.113.0/24",  // 文档示例 TEST-NET-3
+		"240.0.0.0/4",     // 保留，含 255.255.255.255 广播
+		"64:ff9b::/96",    // NAT64，可映射到任意 IPv4 地址
+		"64:ff9b:1::/48",  // 本地 NAT64
+		"100::/64",        // 丢弃
+		"2001::/23",       // IETF 协议分配，含 Teredo
+		"2001:db8::/32",   // 文档示例
+		"2002::/16",       // 6to4，可映射到任意 IPv4 地址
+		"fec0::/10",       // 站点本地（已废弃）
+	} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// IsPublicIP 是否为可路由的公网地址
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
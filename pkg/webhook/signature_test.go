@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, secretPrefix) {
+		t.Fatalf("secret %q has no %s prefix", secret, secretPrefix)
+	}
+	body := []byte(`{"type":"user.created","data":{"id":1}}`)
+	signedAt := time.Unix(1_790_000_000, 0)
+	header := Sign(secret, signedAt, body)
+	if !strings.HasPrefix(header, "t=1790000000,v1=") {
+		t.Fatalf("header = %q", header)
+	}
+
+	tolerance := 5 * time.Minute
+	if err := Verify(secret, header, body, tolerance, signedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	other, _ := NewSecret()
+	rejected := map[string]func() error{
+		"wrong secret": func() error { return Verify(other, header, body, tolerance, signedAt) },
+		"tampered body": func() error {
+			return Verify(secret, header, []byte(`{"type":"user.created","data":{"id":2}}`), tolerance, signedAt)
+		},
+		"tampered timestamp": func() error {
+			forged := strings.Replace(header, "t=1790000000", "t=1790000060", 1)
+			return Verify(secret, forged, body, tolerance, signedAt)
+		},
+		"too old":          func() error { return Verify(secret, header, body, tolerance, signedAt.Add(6*time.Minute)) },
+		"from the future":  func() error { return Verify(secret, header, body, tolerance, signedAt.Add(-6*time.Minute)) },
+		"missing v1":       func() error { return Verify(secret, "t=1790000000", body, tolerance, signedAt) },
+		"missing t":        func() error { return Verify(secret, header[strings.Index(header, "v1="):], body, tolerance, signedAt) },
+		"malformed header": func() error { return Verify(secret, "garbage", body, tolerance, signedAt) },
+	}
+	for name, verify := range rejected {
+		if err := verify(); err == nil {
+			t.Errorf("%s: verify succeeded", name)
+		}
+	}
+}